@@ -2,44 +2,246 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
 	"tgbot/internal/adapters"
 	"tgbot/internal/config"
+	"tgbot/internal/content"
+	"tgbot/internal/logging"
+	"tgbot/internal/metrics"
+	"tgbot/internal/queue"
 	"tgbot/internal/repository"
+	"tgbot/internal/server"
 	"tgbot/internal/service"
+	"tgbot/internal/tracking"
 	"tgbot/internal/usecases"
+	"time"
 
 	tgbotapi "github.com/skinass/telegram-bot-api/v5"
 )
 
 func main() {
-	cfg, err := config.LoadConfig()
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "путь к YAML-файлу конфигурации")
+	flag.Parse()
+
+	if args := flag.Args(); len(args) > 0 {
+		if len(args) == 2 && args[0] == "config" && args[1] == "check" {
+			os.Exit(checkConfig(*configPath))
+		}
+		fmt.Fprintf(os.Stderr, "Неизвестная команда: %s\nИспользование: tgbot [-config файл] [config check]\n", strings.Join(args, " "))
+		os.Exit(2)
+	}
+
+	run(*configPath)
+}
+
+// checkConfig validates the configuration without starting the bot.
+func checkConfig(path string) int {
+	if _, err := config.Load(path); err != nil {
+		fmt.Fprintf(os.Stderr, "Конфигурация содержит ошибки:\n%v\n", err)
+		return 1
+	}
+	fmt.Println("Конфигурация корректна.")
+	return 0
+}
+
+func run(configPath string) {
+	startedAt := time.Now()
+
+	cfg, err := config.Load(configPath)
 	if err != nil {
-		log.Fatal("Не удалось загрузить конфигурацию:", err)
+		fatal(slog.Default(), "Не удалось загрузить конфигурацию", err)
 	}
 
+	logger := logging.New(os.Stdout, cfg.Log)
+	slog.SetDefault(logger)
+
 	ctx := context.Background()
-	postgresRepo, err := repository.NewPostgresRepository(ctx, 3, cfg.Storage)
+	postgresRepo, err := repository.NewPostgresRepository(ctx, cfg.Storage, logger)
 	if err != nil {
-		log.Fatal("Не удалось подключиться к PostgreSQL:", err)
+		fatal(logger, "Не удалось подключиться к PostgreSQL", err)
 	}
+	if err := postgresRepo.Migrate(ctx); err != nil {
+		fatal(logger, "Не удалось применить схему базы данных", err)
+	}
+
+	metricsRegistry := metrics.NewRegistry()
+	metricsRegistry.AddCollector(postgresRepo.PoolStats)
 
 	userRepo := repository.NewUserRepository(postgresRepo.Conn())
 	subRepo := repository.NewSubscriptionRepository(postgresRepo.Conn())
 	sentArticlesRepo := repository.NewSentArticlesRepository(postgresRepo.Conn())
+	articleRepo := repository.NewArticleRepository(postgresRepo.Conn())
+	apiUsageRepo := repository.NewAPIUsageRepository(postgresRepo.Conn())
+	sourceRepo := repository.NewSourceRepository(postgresRepo.Conn())
+	filterRepo := repository.NewFilterRepository(postgresRepo.Conn())
+	deliveryRepo := repository.NewDeliveryRepository(postgresRepo.Conn())
+	statsRepo := repository.NewStatsRepository(postgresRepo.Conn())
+	categoryRepo := repository.NewCategoryRepository(postgresRepo.Conn())
+	scheduleRepo := repository.NewScheduleRepository(postgresRepo.Conn())
+	jobRepo := repository.NewJobRepository(postgresRepo.Conn())
+	bookmarkRepo := repository.NewBookmarkRepository(postgresRepo.Conn())
+	feedbackRepo := repository.NewFeedbackRepository(postgresRepo.Conn())
+	clickRepo := repository.NewClickRepository(postgresRepo.Conn())
+	breakingRepo := repository.NewBreakingRepository(postgresRepo.Conn())
+	summaryRepo := repository.NewSummaryRepository(postgresRepo.Conn())
+	readerRepo := repository.NewReaderRepository(postgresRepo.Conn())
+	languageRepo := repository.NewLanguageRepository(postgresRepo.Conn())
+	mediaRepo := repository.NewMediaRepository(postgresRepo.Conn())
 
-	newsService := service.NewNewsAPIService(cfg.Bot.AuthKey)
-	subscriptionUsecase := usecases.NewSubscriptionUsecase(userRepo, subRepo)
-	newsUsecase := usecases.NewNewsUsecase(newsService, sentArticlesRepo)
+	newsService := service.NewNewsAPIService(cfg.Bot.AuthKeys,
+		service.WithKeyStrategy(service.KeyStrategy(cfg.NewsAPI.KeyStrategy)),
+		service.WithBaseURL(cfg.NewsAPI.BaseURL),
+		service.WithHTTPClient(&http.Client{Timeout: cfg.NewsAPI.Timeout}),
+		service.WithRetries(cfg.NewsAPI.MaxRetries, cfg.NewsAPI.Backoff),
+		service.WithBudget(service.NewBudget(apiUsageRepo, cfg.NewsAPI.DailyLimit)),
+		service.WithCircuitBreaker(service.NewCircuitBreaker(cfg.NewsAPI.BreakerThreshold, cfg.NewsAPI.BreakerCooldown)),
+		service.WithRecorder(metricsRegistry),
+	)
+
+	var newsCache service.Cache = service.NewMemoryCache()
+	if cfg.Cache.Backend == "postgres" {
+		newsCache = repository.NewNewsCacheRepository(postgresRepo.Conn())
+	}
+	cachedNewsService := service.NewCachedNewsService(newsService, "newsapi", newsCache, cfg.Cache.TTL)
+
+	rssService := service.NewRSSService(&http.Client{Timeout: cfg.NewsAPI.Timeout})
+	pageFetcher := content.NewFetcher(content.NewClient(cfg.Content.FetchTimeout), int64(cfg.Content.MaxPageSize))
 
-	categories := []string{"technology", "business", "science", "health", "entertainment"}
+	categoryUsecase := usecases.NewCategoryUsecase(categoryRepo)
+	if err := categoryUsecase.Seed(ctx, cfg.Bot.Categories); err != nil {
+		fatal(logger, "Не удалось заполнить каталог категорий", err)
+	}
+	subscriptionUsecase := usecases.NewSubscriptionUsecase(userRepo, subRepo)
+	newsUsecase := usecases.NewNewsUsecase(cachedNewsService, sentArticlesRepo,
+		usecases.WithArticleStore(articleRepo),
+		usecases.WithNewsMetrics(metricsRegistry),
+		usecases.WithNewsLogger(logger),
+		usecases.WithCategoryCatalogue(categoryUsecase, rssService),
+	)
+	sourceUsecase := usecases.NewSourceUsecase(userRepo, sourceRepo, newsService)
+	filterUsecase := usecases.NewFilterUsecase(userRepo, filterRepo)
+	bookmarkUsecase := usecases.NewBookmarkUsecase(bookmarkRepo)
+	feedbackUsecase := usecases.NewFeedbackUsecase(feedbackRepo)
+	breakingUsecase := usecases.NewBreakingUsecase(breakingRepo)
+	summaryUsecase := usecases.NewSummaryUsecase(summaryRepo, pageFetcher)
+	languageUsecase := usecases.NewLanguageUsecase(languageRepo)
+	mediaUsecase := usecases.NewMediaUsecase(mediaRepo)
+	// Reader pages are linked only when users can reach this server.
+	readerPagePrefix := ""
+	if cfg.HTTP.PublicURL != "" {
+		readerPagePrefix = strings.TrimRight(cfg.HTTP.PublicURL, "/") + server.ReaderPath
+	}
+	signer := tracking.NewSigner(cfg.HTTP.TrackingSecret)
+	readerUsecase := usecases.NewReaderUsecase(readerRepo, pageFetcher, signer, readerPagePrefix)
+	clickUsecase := usecases.NewClickUsecase(clickRepo, signer,
+		strings.TrimRight(cfg.HTTP.PublicURL, "/")+server.RedirectPath)
+	adminUsecase := usecases.NewAdminUsecase(userRepo, statsRepo, deliveryRepo)
+	if err := adminUsecase.SyncAdmins(ctx, cfg.Bot.AdminIDs); err != nil {
+		fatal(logger, "Не удалось сохранить администраторов", err)
+	}
 
 	bot, err := tgbotapi.NewBotAPI(cfg.Bot.Token)
 	if err != nil {
-		log.Fatal("Ошибка при создании Telegram-бота:", err)
+		fatal(logger, "Ошибка при создании Telegram-бота", err)
+	}
+
+	wrappedBot := adapters.NewRateLimitedBot(&adapters.BotWrapper{Bot: bot})
+	botOptions := []usecases.BotOption{
+		usecases.WithAdmins(cfg.Bot.AdminIDs),
+		usecases.WithNewsInterval(cfg.Bot.NewsInterval),
+		usecases.WithArticlesPerCategory(cfg.Bot.ArticlesPerCategory),
+		usecases.WithScheduleStore(scheduleRepo),
+		usecases.WithScheduleJitter(cfg.Bot.ScheduleJitter),
+		usecases.WithJobQueue(jobRepo),
+		usecases.WithCategoryUsecase(categoryUsecase),
+		usecases.WithKeyStatusProvider(newsService),
+		usecases.WithSourceUsecase(sourceUsecase),
+		usecases.WithFilterUsecase(filterUsecase),
+		usecases.WithAdminUsecase(adminUsecase),
+		usecases.WithDeliveryRecorder(deliveryRepo),
+		usecases.WithBookmarkUsecase(bookmarkUsecase),
+		usecases.WithFeedbackUsecase(feedbackUsecase),
+		usecases.WithBreakingUsecase(breakingUsecase),
+		usecases.WithSummaryUsecase(summaryUsecase),
+		usecases.WithReaderUsecase(readerUsecase),
+		usecases.WithLanguageUsecase(languageUsecase),
+		usecases.WithMediaUsecase(mediaUsecase),
+		usecases.WithBotMetrics(metricsRegistry),
+		usecases.WithLogger(logger),
+		usecases.WithMessageBodyLogging(cfg.Log.MessageBodies),
+	}
+	if cfg.HTTP.ClickTracking {
+		botOptions = append(botOptions, usecases.WithClickTracking(clickUsecase))
+	}
+	botUsecase := usecases.NewBotUsecase(wrappedBot, subscriptionUsecase, newsUsecase, cfg.Bot.Categories, botOptions...)
+
+	worker := queue.NewWorker(jobRepo,
+		queue.WithConcurrency(cfg.Queue.Workers),
+		queue.WithLease(cfg.Queue.Lease),
+		queue.WithMaxAttempts(cfg.Queue.MaxAttempts),
+		queue.WithLogger(logger),
+	)
+	botUsecase.RegisterJobs(worker)
+	go worker.Run(ctx)
+
+	httpServer := server.New(cfg.HTTP.Addr)
+	httpServer.Handle("GET /metrics", metricsRegistry)
+	health := server.NewHealthHandler(
+		server.HealthCheck{Name: "database", Critical: true, Check: postgresRepo.Ping},
+		// Long polls return at least every minute even without updates.
+		server.HealthCheck{Name: "telegram", Critical: true, Check: server.RecencyCheck(wrappedBot.LastPoll, startedAt, 3*time.Minute)},
+		server.HealthCheck{Name: "news_cycle", Check: server.RecencyCheck(botUsecase.LastNewsCycle, startedAt, 3*cfg.Bot.NewsInterval)},
+		server.HealthCheck{Name: "newsapi", Check: func(ctx context.Context) error {
+			if state := newsService.CircuitState(); state != service.CircuitClosed {
+				return errors.New("circuit breaker " + state.String())
+			}
+			return nil
+		}},
+	)
+	httpServer.Handle("GET /healthz", http.HandlerFunc(health.Healthz))
+	httpServer.Handle("GET /readyz", http.HandlerFunc(health.Readyz))
+	if cfg.HTTP.ClickTracking {
+		httpServer.Handle("GET "+server.RedirectPath+"{token}", server.NewRedirectHandler(clickUsecase))
+	}
+	if readerPagePrefix != "" {
+		httpServer.Handle("GET "+server.ReaderPath+"{token}", server.NewReaderHandler(readerUsecase))
+	}
+	if cfg.HTTP.AdminAPIEnabled {
+		httpServer.Handle("/admin/", server.NewAdminHandler(ctx, cfg.HTTP.AdminToken, adminUsecase, subscriptionUsecase, botUsecase, botUsecase))
+	}
+	go func() {
+		if err := httpServer.Run(ctx); err != nil {
+			logger.Error("HTTP-сервер остановлен", "error", err)
+		}
+	}()
+
+	if configPath != "" {
+		go config.Watch(ctx, configPath, cfg, func(next *config.Config) {
+			botUsecase.UpdateSettings(usecases.BotSettings{
+				Categories:          next.Bot.Categories,
+				AdminIDs:            next.Bot.AdminIDs,
+				NewsInterval:        next.Bot.NewsInterval,
+				ArticlesPerCategory: next.Bot.ArticlesPerCategory,
+			})
+			if err := categoryUsecase.Seed(ctx, next.Bot.Categories); err != nil {
+				logger.Error("Не удалось заполнить каталог категорий", "error", err)
+			}
+			if err := adminUsecase.SyncAdmins(ctx, next.Bot.AdminIDs); err != nil {
+				logger.Error("Не удалось сохранить администраторов", "error", err)
+			}
+		})
 	}
 
-	wrappedBot := &adapters.BotWrapper{Bot: bot}
-	botUsecase := usecases.NewBotUsecase(wrappedBot, subscriptionUsecase, newsUsecase, categories)
 	botUsecase.StartBot(ctx)
 }
+
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
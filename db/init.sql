@@ -1,17 +1,265 @@
+-- init.sql runs only on an empty volume; the bot applies this file on every
+-- start, so columns added after a table was created are added here too.
+
 CREATE TABLE IF NOT EXISTS users (
-    id BIGINT PRIMARY KEY
+    id BIGINT PRIMARY KEY,
+    role VARCHAR(10) NOT NULL DEFAULT 'user',
+    banned BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Users who opted out with /privacy get plain article links.
+    tracking_opt_out BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Users who turned on "🔴 Breaking" alerts with /breaking on.
+    breaking_alerts BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Users who get summaries instead of provider descriptions (/summary on).
+    summaries BOOLEAN NOT NULL DEFAULT FALSE,
+    -- Languages the user reads (/languages); empty means all of them.
+    languages TEXT[] NOT NULL DEFAULT '{}',
+    -- How pushed articles are shown (/media): preview, photo or text.
+    media VARCHAR(10) NOT NULL DEFAULT 'preview',
+    -- Groups and channels subscribe too; their IDs are negative.
+    chat_type VARCHAR(20) NOT NULL DEFAULT 'private',
+    -- Chats that blocked or removed the bot (Telegram answered 403) get
+    -- nothing until a command is sent from them again.
+    unreachable BOOLEAN NOT NULL DEFAULT FALSE,
+    last_active_at TIMESTAMP WITH TIME ZONE
 );
 
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(10) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS banned BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_active_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS tracking_opt_out BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS breaking_alerts BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS summaries BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS languages TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS media VARCHAR(10) NOT NULL DEFAULT 'preview';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS chat_type VARCHAR(20) NOT NULL DEFAULT 'private';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS unreachable BOOLEAN NOT NULL DEFAULT FALSE;
+
 CREATE TABLE IF NOT EXISTS subscriptions (
     id SERIAL PRIMARY KEY,
     user_id BIGINT REFERENCES users(id),
     category VARCHAR(50) NOT NULL,
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    language VARCHAR(2) NOT NULL DEFAULT '',
+    -- Forum topic of a supergroup the chat subscribed from; 0 is General.
+    thread_id BIGINT NOT NULL DEFAULT 0,
     UNIQUE(user_id, category)
 );
 
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS country VARCHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS language VARCHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE subscriptions ADD COLUMN IF NOT EXISTS thread_id BIGINT NOT NULL DEFAULT 0;
+
 CREATE TABLE IF NOT EXISTS sent_articles (
     id SERIAL PRIMARY KEY,
-    url VARCHAR(255) NOT NULL UNIQUE,
+    url TEXT NOT NULL UNIQUE,
     category VARCHAR(50) NOT NULL,
     sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
-);
\ No newline at end of file
+);
+
+-- Articles are claimed in bulk, so one URL too long for the old
+-- VARCHAR(255) failed the whole batch.
+ALTER TABLE sent_articles ALTER COLUMN url TYPE TEXT;
+
+CREATE TABLE IF NOT EXISTS articles (
+    id BIGSERIAL PRIMARY KEY,
+    url TEXT NOT NULL UNIQUE,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    published_at VARCHAR(40) NOT NULL DEFAULT '',
+    category VARCHAR(50) NOT NULL,
+    source_id VARCHAR(100) NOT NULL DEFAULT '',
+    source_name VARCHAR(255) NOT NULL DEFAULT '',
+    author TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    -- Detected language, empty if unknown.
+    language VARCHAR(2) NOT NULL DEFAULT '',
+    fetched_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    -- Unlike fetched_at, not updated when the article is fetched again.
+    first_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    -- Extractive summary of the page; NULL until made, empty if the page
+    -- had no usable text.
+    summary TEXT,
+    -- Main text of the page as paragraphs separated by blank lines; NULL
+    -- until extracted.
+    content TEXT
+);
+
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS source_id VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS source_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS first_seen_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS summary TEXT;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS content TEXT;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS language VARCHAR(2) NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS author TEXT NOT NULL DEFAULT '';
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS image_url TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS articles_category_published_idx ON articles (category, published_at DESC);
+CREATE INDEX IF NOT EXISTS articles_category_first_seen_idx ON articles (category, first_seen_at);
+
+CREATE TABLE IF NOT EXISTS api_usage (
+    key_id VARCHAR(32) NOT NULL,
+    day DATE NOT NULL,
+    requests INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (key_id, day)
+);
+
+CREATE TABLE IF NOT EXISTS news_cache (
+    key TEXT PRIMARY KEY,
+    payload JSONB NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sources (
+    id VARCHAR(100) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    url TEXT NOT NULL DEFAULT '',
+    category VARCHAR(50) NOT NULL DEFAULT '',
+    language VARCHAR(2) NOT NULL DEFAULT '',
+    country VARCHAR(2) NOT NULL DEFAULT '',
+    fetched_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS source_follows (
+    user_id BIGINT REFERENCES users(id),
+    source_id VARCHAR(100) NOT NULL,
+    -- Forum topic the source was followed from, like subscriptions.thread_id.
+    thread_id BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, source_id)
+);
+
+ALTER TABLE source_follows ADD COLUMN IF NOT EXISTS thread_id BIGINT NOT NULL DEFAULT 0;
+
+-- source holds either a source ID or a lower-cased source name, since many
+-- articles come from outlets that are not in the catalogue.
+CREATE TABLE IF NOT EXISTS source_mutes (
+    user_id BIGINT REFERENCES users(id),
+    source VARCHAR(255) NOT NULL,
+    PRIMARY KEY (user_id, source)
+);
+
+CREATE TABLE IF NOT EXISTS filter_rules (
+    id SERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id),
+    category VARCHAR(50) NOT NULL DEFAULT '',
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('include', 'exclude')),
+    expression TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS filter_rules_user_idx ON filter_rules (user_id);
+
+CREATE TABLE IF NOT EXISTS deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    article_url TEXT NOT NULL,
+    category VARCHAR(50) NOT NULL,
+    sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS deliveries_sent_at_idx ON deliveries (sent_at);
+
+-- Depending on provider, a category is served by a NewsAPI category, a
+-- NewsAPI keyword query or a set of RSS/Atom feeds.
+CREATE TABLE IF NOT EXISTS categories (
+    slug VARCHAR(50) PRIMARY KEY,
+    names JSONB NOT NULL DEFAULT '{}',
+    provider VARCHAR(20) NOT NULL DEFAULT 'newsapi' CHECK (provider IN ('newsapi', 'keyword', 'rss')),
+    newsapi_category VARCHAR(50) NOT NULL DEFAULT '',
+    keywords TEXT NOT NULL DEFAULT '',
+    feeds TEXT[] NOT NULL DEFAULT '{}',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INT NOT NULL DEFAULT 0,
+    -- Empty means the default news interval.
+    schedule VARCHAR(100) NOT NULL DEFAULT ''
+);
+
+ALTER TABLE categories ADD COLUMN IF NOT EXISTS schedule VARCHAR(100) NOT NULL DEFAULT '';
+
+INSERT INTO categories (slug, names, provider, newsapi_category, sort_order) VALUES
+    ('technology', '{"ru": "Технологии", "en": "Technology"}', 'newsapi', 'technology', 1),
+    ('business', '{"ru": "Бизнес", "en": "Business"}', 'newsapi', 'business', 2),
+    ('science', '{"ru": "Наука", "en": "Science"}', 'newsapi', 'science', 3),
+    ('health', '{"ru": "Здоровье", "en": "Health"}', 'newsapi', 'health', 4),
+    ('entertainment', '{"ru": "Развлечения", "en": "Entertainment"}', 'newsapi', 'entertainment', 5)
+ON CONFLICT (slug) DO NOTHING;
+
+-- Start times of scheduled jobs, so a restart doesn't rerun them all at once.
+CREATE TABLE IF NOT EXISTS schedule_runs (
+    job VARCHAR(100) PRIMARY KEY,
+    last_run TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+-- Durable work queue. Workers claim jobs with FOR UPDATE SKIP LOCKED and hold
+-- them until locked_until; a job whose worker died is claimed again after
+-- that. Jobs that keep failing are kept with dead_at set.
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    dedupe_key VARCHAR(200),
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    locked_by VARCHAR(100),
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    dead_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS jobs_run_at_idx ON jobs (run_at) WHERE dead_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS jobs_dedupe_key_idx ON jobs (dedupe_key) WHERE dedupe_key IS NOT NULL AND dead_at IS NULL;
+
+-- Articles users saved with the Save button. Stored articles are never
+-- pruned, so bookmarks outlive the provider's results.
+CREATE TABLE IF NOT EXISTS bookmarks (
+    user_id BIGINT NOT NULL,
+    article_id BIGINT NOT NULL REFERENCES articles(id),
+    saved_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, article_id)
+);
+
+CREATE INDEX IF NOT EXISTS bookmarks_user_saved_idx ON bookmarks (user_id, saved_at DESC);
+
+-- 👍/👎 votes on delivered articles; they train the per-user ranking.
+CREATE TABLE IF NOT EXISTS article_feedback (
+    user_id BIGINT NOT NULL,
+    article_id BIGINT NOT NULL REFERENCES articles(id),
+    vote SMALLINT NOT NULL CHECK (vote IN (-1, 1)),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, article_id)
+);
+
+-- Clicks on tracked article links, for the click-through reports in /stats.
+CREATE TABLE IF NOT EXISTS clicks (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    article_id BIGINT NOT NULL REFERENCES articles(id),
+    clicked_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS clicks_user_article_idx ON clicks (user_id, article_id);
+CREATE INDEX IF NOT EXISTS deliveries_user_article_idx ON deliveries (user_id, article_url);
+
+-- Peak score of every story covered by more than one source, by its lead
+-- article, to tune the breaking-news thresholds.
+CREATE TABLE IF NOT EXISTS trend_scores (
+    category VARCHAR(50) NOT NULL,
+    article_id BIGINT NOT NULL REFERENCES articles(id),
+    score INT NOT NULL,
+    recorded_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (category, article_id)
+);
+
+-- Sources a story needs to be breaking news, for categories that don't use
+-- the default.
+CREATE TABLE IF NOT EXISTS breaking_thresholds (
+    category VARCHAR(50) PRIMARY KEY,
+    threshold INT NOT NULL CHECK (threshold >= 2)
+);
+
+-- Articles of stories that were sent as breaking news; a story is alerted
+-- once even as it grows.
+CREATE TABLE IF NOT EXISTS breaking_articles (
+    article_id BIGINT PRIMARY KEY REFERENCES articles(id),
+    alerted_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
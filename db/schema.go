@@ -0,0 +1,11 @@
+// Package db holds the database schema.
+package db
+
+import _ "embed"
+
+// Schema creates the tables the bot uses and adds columns that were
+// introduced after a table was first created. Every statement is
+// idempotent, so it is applied on every start.
+//
+//go:embed init.sql
+var Schema string
@@ -1,17 +1,75 @@
 package adapters
 
-import tgbotapi "github.com/skinass/telegram-bot-api/v5"
+import (
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+const pollRetryDelay = 3 * time.Second
 
 type BotWrapper struct {
 	Bot *tgbotapi.BotAPI
+
+	// lastPoll is the Unix time in nanoseconds of the last successful
+	// getUpdates call.
+	lastPoll atomic.Int64
+
+	threadsMu sync.Mutex
+	// threads holds the forum topics of recent commands sent in one.
+	threads map[messageKey]topicCommand
 }
 
 func (b *BotWrapper) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
 	return b.Bot.Send(c)
 }
 
+// Request makes API calls whose result isn't a message, such as answering a
+// callback query.
+func (b *BotWrapper) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	return b.Bot.Request(c)
+}
+
+// GetUpdatesChan long-polls getUpdates like the library's own loop, but
+// records every successful poll so readiness checks can see whether
+// Telegram is reachable even when no updates arrive. It also records the
+// forum topics of commands; see MessageThreadID.
 func (b *BotWrapper) GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
-	return b.Bot.GetUpdatesChan(config)
+	ch := make(chan tgbotapi.Update, b.Bot.Buffer)
+
+	go func() {
+		for {
+			updates, err := b.getUpdates(config)
+			if err != nil {
+				slog.Error("Failed to get updates, retrying", "error", err, "retry_in", pollRetryDelay)
+				time.Sleep(pollRetryDelay)
+				continue
+			}
+			b.lastPoll.Store(time.Now().UnixNano())
+
+			for _, update := range updates {
+				if update.UpdateID >= config.Offset {
+					config.Offset = update.UpdateID + 1
+					ch <- update
+				}
+			}
+		}
+	}()
+
+	return ch
+}
+
+// LastPoll returns the time of the last successful getUpdates call, or the
+// zero time if there was none yet.
+func (b *BotWrapper) LastPoll() time.Time {
+	nanos := b.lastPoll.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
 }
 
 func (b *BotWrapper) Self() tgbotapi.User {
@@ -0,0 +1,133 @@
+package adapters
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+const (
+	// Telegram allows about 30 messages per second overall, one per second
+	// to a private chat and 20 per minute to a group.
+	globalSendInterval  = time.Second / 30
+	privateSendInterval = time.Second
+	groupSendInterval   = 3 * time.Second
+)
+
+// RateLimitedBot spaces out sends to stay within Telegram's limits and
+// retries once when Telegram asks to slow down.
+type RateLimitedBot struct {
+	*BotWrapper
+
+	mu       sync.Mutex
+	nextSend time.Time
+	nextChat map[int64]time.Time
+}
+
+func NewRateLimitedBot(bot *BotWrapper) *RateLimitedBot {
+	return &RateLimitedBot{
+		BotWrapper: bot,
+		nextChat:   make(map[int64]time.Time),
+	}
+}
+
+func (b *RateLimitedBot) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	b.wait(chatID(c))
+
+	msg, err := b.BotWrapper.Send(c)
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.RetryAfter > 0 {
+		time.Sleep(time.Duration(tgErr.RetryAfter) * time.Second)
+		b.wait(chatID(c))
+		msg, err = b.BotWrapper.Send(c)
+	}
+	return msg, err
+}
+
+// Request spaces out calls that send to a chat, such as albums, like Send.
+// Other calls, such as callback answers, aren't limited.
+func (b *RateLimitedBot) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	chat := chatID(c)
+	if chat == 0 {
+		return b.BotWrapper.Request(c)
+	}
+	return b.limited(chat, func() (*tgbotapi.APIResponse, error) {
+		return b.BotWrapper.Request(c)
+	})
+}
+
+// SendToThread spaces out sends to forum topics like Send.
+func (b *RateLimitedBot) SendToThread(c tgbotapi.Chattable, threadID int) (*tgbotapi.APIResponse, error) {
+	return b.limited(chatID(c), func() (*tgbotapi.APIResponse, error) {
+		return b.BotWrapper.SendToThread(c, threadID)
+	})
+}
+
+// limited makes a call that sends to the chat once its slot is free, and
+// retries it once if Telegram asks to slow down.
+func (b *RateLimitedBot) limited(chat int64, call func() (*tgbotapi.APIResponse, error)) (*tgbotapi.APIResponse, error) {
+	b.wait(chat)
+
+	resp, err := call()
+	var tgErr *tgbotapi.Error
+	if errors.As(err, &tgErr) && tgErr.RetryAfter > 0 {
+		time.Sleep(time.Duration(tgErr.RetryAfter) * time.Second)
+		b.wait(chat)
+		resp, err = call()
+	}
+	return resp, err
+}
+
+// wait blocks until both the global and the per-chat slot are free.
+func (b *RateLimitedBot) wait(chat int64) {
+	b.mu.Lock()
+	now := time.Now()
+	at := maxTime(now, b.nextSend)
+	if chat != 0 {
+		at = maxTime(at, b.nextChat[chat])
+		interval := privateSendInterval
+		if chat < 0 {
+			interval = groupSendInterval
+		}
+		b.nextChat[chat] = at.Add(interval)
+	}
+	b.nextSend = at.Add(globalSendInterval)
+	b.pruneChats(now)
+	b.mu.Unlock()
+
+	time.Sleep(time.Until(at))
+}
+
+func (b *RateLimitedBot) pruneChats(now time.Time) {
+	if len(b.nextChat) < 10000 {
+		return
+	}
+	for chat, next := range b.nextChat {
+		if next.Before(now) {
+			delete(b.nextChat, chat)
+		}
+	}
+}
+
+// chatID returns the chat a config is sent to, or 0 if it isn't known.
+// Group chats have negative IDs.
+func chatID(c tgbotapi.Chattable) int64 {
+	switch cfg := c.(type) {
+	case tgbotapi.MessageConfig:
+		return cfg.ChatID
+	case tgbotapi.PhotoConfig:
+		return cfg.ChatID
+	case tgbotapi.MediaGroupConfig:
+		return cfg.ChatID
+	}
+	return 0
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
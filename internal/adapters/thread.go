@@ -0,0 +1,133 @@
+package adapters
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+// topicCommandTTL bounds how long the forum topic of a command is kept
+// for the bot to look up while it works through the updates.
+const topicCommandTTL = 10 * time.Minute
+
+type messageKey struct {
+	chatID    int64
+	messageID int
+}
+
+type topicCommand struct {
+	threadID   int
+	receivedAt time.Time
+}
+
+// topicUpdate holds the forum topic fields of an update, which the library
+// doesn't decode.
+type topicUpdate struct {
+	Message *struct {
+		ThreadID       int  `json:"message_thread_id"`
+		IsTopicMessage bool `json:"is_topic_message"`
+	} `json:"message"`
+}
+
+// getUpdates calls getUpdates like the library does, and records the forum
+// topic of every command sent in one.
+func (b *BotWrapper) getUpdates(config tgbotapi.UpdateConfig) ([]tgbotapi.Update, error) {
+	resp, err := b.Bot.Request(config)
+	if err != nil {
+		return nil, err
+	}
+	var updates []tgbotapi.Update
+	if err := json.Unmarshal(resp.Result, &updates); err != nil {
+		return nil, err
+	}
+	var topics []topicUpdate
+	if err := json.Unmarshal(resp.Result, &topics); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	b.threadsMu.Lock()
+	defer b.threadsMu.Unlock()
+	for key, command := range b.threads {
+		if now.Sub(command.receivedAt) > topicCommandTTL {
+			delete(b.threads, key)
+		}
+	}
+	for i, update := range updates {
+		message := update.Message
+		topic := topics[i].Message
+		if message == nil || !message.IsCommand() || topic == nil || !topic.IsTopicMessage {
+			continue
+		}
+		if b.threads == nil {
+			b.threads = make(map[messageKey]topicCommand)
+		}
+		b.threads[messageKey{message.Chat.ID, message.MessageID}] = topicCommand{topic.ThreadID, now}
+	}
+	return updates, nil
+}
+
+// MessageThreadID returns the forum topic a command was sent in, or 0 for
+// the General topic and chats without topics. Each command is looked up
+// once.
+func (b *BotWrapper) MessageThreadID(chatID int64, messageID int) int {
+	b.threadsMu.Lock()
+	defer b.threadsMu.Unlock()
+	key := messageKey{chatID, messageID}
+	command := b.threads[key]
+	delete(b.threads, key)
+	return command.threadID
+}
+
+// SendToThread sends a message, photo or album into a forum topic.
+func (b *BotWrapper) SendToThread(c tgbotapi.Chattable, threadID int) (*tgbotapi.APIResponse, error) {
+	method, params, err := threadParams(c, threadID)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bot.MakeRequest(method, params)
+}
+
+// threadParams returns the method and parameters of a config sent into a
+// forum topic. The library keeps a config's parameters to itself and knows
+// no message_thread_id, so the fields the bot uses are set here.
+func threadParams(c tgbotapi.Chattable, threadID int) (string, tgbotapi.Params, error) {
+	params := make(tgbotapi.Params)
+	params.AddNonZero("message_thread_id", threadID)
+	switch cfg := c.(type) {
+	case tgbotapi.MessageConfig:
+		params.AddNonEmpty("text", cfg.Text)
+		params.AddBool("disable_web_page_preview", cfg.DisableWebPagePreview)
+		params.AddNonEmpty("parse_mode", cfg.ParseMode)
+		return "sendMessage", params, addBaseChat(params, cfg.BaseChat)
+	case tgbotapi.PhotoConfig:
+		if cfg.File.NeedsUpload() {
+			return "", nil, fmt.Errorf("uploading photos to forum topics is not supported")
+		}
+		params["photo"] = cfg.File.SendData()
+		params.AddNonEmpty("caption", cfg.Caption)
+		params.AddNonEmpty("parse_mode", cfg.ParseMode)
+		return "sendPhoto", params, addBaseChat(params, cfg.BaseChat)
+	case tgbotapi.MediaGroupConfig:
+		for _, media := range cfg.Media {
+			if photo, ok := media.(tgbotapi.InputMediaPhoto); !ok || photo.Media.NeedsUpload() {
+				return "", nil, fmt.Errorf("only photos by URL can be sent to forum topics as albums")
+			}
+		}
+		params.AddNonZero64("chat_id", cfg.ChatID)
+		params.AddBool("disable_notification", cfg.DisableNotification)
+		params.AddNonZero("reply_to_message_id", cfg.ReplyToMessageID)
+		return "sendMediaGroup", params, params.AddInterface("media", cfg.Media)
+	}
+	return "", nil, fmt.Errorf("%T can't be sent to a forum topic", c)
+}
+
+func addBaseChat(params tgbotapi.Params, chat tgbotapi.BaseChat) error {
+	params.AddNonZero64("chat_id", chat.ChatID)
+	params.AddNonZero("reply_to_message_id", chat.ReplyToMessageID)
+	params.AddBool("disable_notification", chat.DisableNotification)
+	params.AddBool("allow_sending_without_reply", chat.AllowSendingWithoutReply)
+	return params.AddInterface("reply_markup", chat.ReplyMarkup)
+}
@@ -1,53 +1,193 @@
 package config
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
-	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
-	Bot     BotConfig
-	Storage StorageConfig
+	Bot     BotConfig     `yaml:"bot"`
+	Storage StorageConfig `yaml:"storage"`
+	NewsAPI NewsAPIConfig `yaml:"newsapi"`
+	Cache   CacheConfig   `yaml:"cache"`
+	Queue   QueueConfig   `yaml:"queue"`
+	Content ContentConfig `yaml:"content"`
+	HTTP    HTTPConfig    `yaml:"http"`
+	Log     LogConfig     `yaml:"log"`
+}
+
+type LogConfig struct {
+	Level slog.Level `yaml:"level"`
+	// Format is "text" or "json".
+	Format string `yaml:"format"`
+	// MessageBodies logs the text of sent messages. It is off by default
+	// because messages may contain user data.
+	MessageBodies bool `yaml:"message_bodies"`
+}
+
+type HTTPConfig struct {
+	Addr string `yaml:"addr"`
+	// AdminAPIEnabled mounts the admin API, which requires AdminToken as a
+	// bearer token.
+	AdminAPIEnabled bool   `yaml:"admin_api_enabled"`
+	AdminToken      string `yaml:"admin_token"`
+	// PublicURL is the address users reach this server at, used in links
+	// the bot sends. Reader pages are served only when it is set.
+	PublicURL string `yaml:"public_url"`
+	// ClickTracking sends article links through the server's redirect
+	// endpoint, which records clicks. TrackingSecret signs these links and
+	// the links to reader pages.
+	ClickTracking  bool   `yaml:"click_tracking"`
+	TrackingSecret string `yaml:"tracking_secret"`
 }
 
 type BotConfig struct {
-	Token   string
-	AuthKey string
+	Token string `yaml:"token"`
+	// AuthKeys are the NewsAPI keys requests are spread across.
+	AuthKeys []string `yaml:"auth_keys"`
+	AdminIDs []int64  `yaml:"admin_ids"`
+
+	// Categories seed the category catalogue: missing ones are added as
+	// NewsAPI categories, existing ones are managed with /category.
+	Categories []string `yaml:"categories"`
+	// NewsInterval is how often subscribers are sent new articles.
+	NewsInterval time.Duration `yaml:"news_interval"`
+	// ArticlesPerCategory caps the articles sent per category and cycle.
+	ArticlesPerCategory int `yaml:"articles_per_category"`
+	// ScheduleJitter delays each polling run by a random duration up to this
+	// value. Category schedules themselves are set with /category schedule.
+	ScheduleJitter time.Duration `yaml:"schedule_jitter"`
 }
 
-type StorageConfig struct {
-	Username string
-	Password string
-	Host     string
-	Port     int
-	Database string
+type NewsAPIConfig struct {
+	BaseURL    string        `yaml:"base_url"`
+	Timeout    time.Duration `yaml:"timeout"`
+	MaxRetries int           `yaml:"max_retries"`
+	Backoff    time.Duration `yaml:"backoff"`
+	// KeyStrategy is either "round_robin" or "least_used".
+	KeyStrategy string `yaml:"key_strategy"`
+	// DailyLimit is the request budget of one API key per UTC day.
+	DailyLimit       int           `yaml:"daily_limit"`
+	BreakerThreshold int           `yaml:"breaker_threshold"`
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown"`
 }
 
-func LoadConfig() (*Config, error) {
-	port, err := strconv.Atoi(getEnv("STORAGE_PORT", "5432"))
-	if err != nil {
-		return nil, fmt.Errorf("неверный порт базы данных: %w", err)
-	}
+type CacheConfig struct {
+	// Backend is "memory" for a per-process cache or "postgres" to share it
+	// across replicas.
+	Backend string        `yaml:"backend"`
+	TTL     time.Duration `yaml:"ttl"`
+}
+
+type QueueConfig struct {
+	// Workers is the number of jobs this replica runs at once.
+	Workers int `yaml:"workers"`
+	// Lease is how long a claimed job stays locked unless its worker renews
+	// it, which it does while the job runs; a job whose worker died is
+	// picked up again after that.
+	Lease       time.Duration `yaml:"lease"`
+	MaxAttempts int           `yaml:"max_attempts"`
+}
+
+// ContentConfig limits the fetches of article pages, which summaries are
+// made from.
+type ContentConfig struct {
+	FetchTimeout time.Duration `yaml:"fetch_timeout"`
+	// MaxPageSize is the number of bytes read from a page; the rest is
+	// ignored.
+	MaxPageSize int `yaml:"max_page_size"`
+}
+
+type StorageConfig struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"`
+	Database string `yaml:"database"`
+	MaxConns int    `yaml:"max_conns"`
+}
 
+func defaults() *Config {
 	return &Config{
 		Bot: BotConfig{
-			Token:   getEnv("BOT_TOKEN", ""),
-			AuthKey: getEnv("BOT_AUTH_KEY", ""),
+			Categories:          []string{"technology", "business", "science", "health", "entertainment"},
+			NewsInterval:        5 * time.Minute,
+			ArticlesPerCategory: 5,
+			ScheduleJitter:      15 * time.Second,
 		},
 		Storage: StorageConfig{
-			Username: getEnv("STORAGE_USERNAME", "postgres"),
-			Password: getEnv("STORAGE_PASSWORD", "postgres"),
-			Host:     getEnv("STORAGE_HOST", "localhost"),
-			Port:     port,
-			Database: getEnv("STORAGE_DATABASE", "tgbot"),
+			Username: "postgres",
+			Password: "postgres",
+			Host:     "localhost",
+			Port:     5432,
+			Database: "tgbot",
+			MaxConns: 3,
+		},
+		NewsAPI: NewsAPIConfig{
+			BaseURL:          "https://newsapi.org",
+			Timeout:          10 * time.Second,
+			MaxRetries:       3,
+			Backoff:          500 * time.Millisecond,
+			KeyStrategy:      "round_robin",
+			DailyLimit:       100,
+			BreakerThreshold: 5,
+			BreakerCooldown:  15 * time.Minute,
+		},
+		Cache: CacheConfig{
+			Backend: "memory",
+			TTL:     5 * time.Minute,
+		},
+		Queue: QueueConfig{
+			Workers:     2,
+			Lease:       5 * time.Minute,
+			MaxAttempts: 5,
+		},
+		Content: ContentConfig{
+			FetchTimeout: 10 * time.Second,
+			MaxPageSize:  2 << 20,
+		},
+		HTTP: HTTPConfig{
+			Addr: ":8080",
+		},
+		Log: LogConfig{
+			Level:  slog.LevelInfo,
+			Format: "text",
 		},
-	}, nil
+	}
 }
 
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+// Load builds the config from the defaults, the YAML file at path (if path
+// isn't empty) and the environment, in increasing priority, and validates
+// the result. The returned error lists every invalid field.
+func Load(path string) (*Config, error) {
+	cfg := defaults()
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+	if err := errors.Join(cfg.applyEnv(), cfg.Validate()); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать файл конфигурации: %w", err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("ошибка в файле конфигурации %s: %w", path, err)
 	}
-	return defaultValue
+	return nil
 }
@@ -0,0 +1,162 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envLoader overrides config fields with the environment variables that are
+// set, collecting every value that can't be parsed. Empty variables count as
+// unset: docker-compose passes every variable it lists, set or not.
+type envLoader struct {
+	errs []error
+}
+
+func (c *Config) applyEnv() error {
+	var env envLoader
+
+	env.string("BOT_TOKEN", &c.Bot.Token)
+	env.list("BOT_AUTH_KEY", &c.Bot.AuthKeys)
+	if keys := splitList(os.Getenv("BOT_AUTH_KEYS")); len(keys) > 0 {
+		c.Bot.AuthKeys = keys
+	}
+	env.ids("BOT_ADMIN_IDS", &c.Bot.AdminIDs)
+	env.list("BOT_CATEGORIES", &c.Bot.Categories)
+	env.duration("BOT_NEWS_INTERVAL", &c.Bot.NewsInterval)
+	env.duration("BOT_SCHEDULE_JITTER", &c.Bot.ScheduleJitter)
+	env.int("BOT_ARTICLES_PER_CATEGORY", &c.Bot.ArticlesPerCategory)
+
+	env.string("STORAGE_USERNAME", &c.Storage.Username)
+	env.string("STORAGE_PASSWORD", &c.Storage.Password)
+	env.string("STORAGE_HOST", &c.Storage.Host)
+	env.int("STORAGE_PORT", &c.Storage.Port)
+	env.string("STORAGE_DATABASE", &c.Storage.Database)
+	env.int("STORAGE_MAX_CONNS", &c.Storage.MaxConns)
+
+	env.string("NEWSAPI_BASE_URL", &c.NewsAPI.BaseURL)
+	env.duration("NEWSAPI_TIMEOUT", &c.NewsAPI.Timeout)
+	env.int("NEWSAPI_MAX_RETRIES", &c.NewsAPI.MaxRetries)
+	env.duration("NEWSAPI_BACKOFF", &c.NewsAPI.Backoff)
+	env.string("NEWSAPI_KEY_STRATEGY", &c.NewsAPI.KeyStrategy)
+	env.int("NEWSAPI_DAILY_LIMIT", &c.NewsAPI.DailyLimit)
+	env.int("NEWSAPI_BREAKER_THRESHOLD", &c.NewsAPI.BreakerThreshold)
+	env.duration("NEWSAPI_BREAKER_COOLDOWN", &c.NewsAPI.BreakerCooldown)
+
+	env.string("CACHE_BACKEND", &c.Cache.Backend)
+	env.duration("CACHE_TTL", &c.Cache.TTL)
+
+	env.int("QUEUE_WORKERS", &c.Queue.Workers)
+	env.duration("QUEUE_LEASE", &c.Queue.Lease)
+	env.int("QUEUE_MAX_ATTEMPTS", &c.Queue.MaxAttempts)
+
+	env.duration("CONTENT_FETCH_TIMEOUT", &c.Content.FetchTimeout)
+	env.int("CONTENT_MAX_PAGE_SIZE", &c.Content.MaxPageSize)
+
+	env.string("HTTP_ADDR", &c.HTTP.Addr)
+	env.bool("ADMIN_API_ENABLED", &c.HTTP.AdminAPIEnabled)
+	env.string("ADMIN_API_TOKEN", &c.HTTP.AdminToken)
+	env.string("HTTP_PUBLIC_URL", &c.HTTP.PublicURL)
+	env.bool("CLICK_TRACKING", &c.HTTP.ClickTracking)
+	env.string("CLICK_TRACKING_SECRET", &c.HTTP.TrackingSecret)
+
+	if value, ok := lookupEnv("LOG_LEVEL"); ok {
+		if err := c.Log.Level.UnmarshalText([]byte(value)); err != nil {
+			env.fail("LOG_LEVEL", value)
+		}
+	}
+	env.string("LOG_FORMAT", &c.Log.Format)
+	env.bool("LOG_MESSAGE_BODIES", &c.Log.MessageBodies)
+
+	return errors.Join(env.errs...)
+}
+
+func (e *envLoader) fail(key, value string) {
+	e.errs = append(e.errs, fmt.Errorf("%s: неверное значение %q", key, value))
+}
+
+func (e *envLoader) string(key string, dst *string) {
+	if value, ok := lookupEnv(key); ok {
+		*dst = value
+	}
+}
+
+func (e *envLoader) int(key string, dst *int) {
+	if value, ok := lookupEnv(key); ok {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			e.fail(key, value)
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *envLoader) bool(key string, dst *bool) {
+	if value, ok := lookupEnv(key); ok {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			e.fail(key, value)
+			return
+		}
+		*dst = b
+	}
+}
+
+func (e *envLoader) duration(key string, dst *time.Duration) {
+	if value, ok := lookupEnv(key); ok {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			e.fail(key, value)
+			return
+		}
+		*dst = d
+	}
+}
+
+func (e *envLoader) list(key string, dst *[]string) {
+	if value, ok := lookupEnv(key); ok {
+		*dst = splitList(value)
+	}
+}
+
+func (e *envLoader) ids(key string, dst *[]int64) {
+	if value, ok := lookupEnv(key); ok {
+		ids, err := parseIDs(value)
+		if err != nil {
+			e.fail(key, value)
+			return
+		}
+		*dst = ids
+	}
+}
+
+func lookupEnv(key string) (string, bool) {
+	value := os.Getenv(key)
+	return value, strings.TrimSpace(value) != ""
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseIDs(value string) ([]int64, error) {
+	var ids []int64
+	for _, item := range splitList(value) {
+		id, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) check(ok bool, field, format string, args ...any) {
+	if !ok {
+		v.errs = append(v.errs, fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...)))
+	}
+}
+
+// Validate reports every missing or invalid field at once, named by its
+// path in the config file.
+func (c *Config) Validate() error {
+	var v validator
+
+	v.check(c.Bot.Token != "", "bot.token", "обязательное поле (BOT_TOKEN)")
+	v.check(len(c.Bot.AuthKeys) > 0, "bot.auth_keys", "нужен хотя бы один ключ NewsAPI (BOT_AUTH_KEYS)")
+	v.check(len(c.Bot.Categories) > 0, "bot.categories", "список категорий пуст")
+	seen := make(map[string]bool)
+	for _, category := range c.Bot.Categories {
+		v.check(category != "" && !seen[category], "bot.categories", "пустая или повторяющаяся категория %q", category)
+		seen[category] = true
+	}
+	v.check(c.Bot.NewsInterval >= time.Minute, "bot.news_interval", "должен быть не меньше минуты, задано %s", c.Bot.NewsInterval)
+	v.check(c.Bot.ScheduleJitter >= 0 && c.Bot.ScheduleJitter < c.Bot.NewsInterval, "bot.schedule_jitter", "должен быть неотрицательным и меньше news_interval, задано %s", c.Bot.ScheduleJitter)
+	v.check(c.Bot.ArticlesPerCategory >= 1 && c.Bot.ArticlesPerCategory <= 20, "bot.articles_per_category", "должно быть от 1 до 20, задано %d", c.Bot.ArticlesPerCategory)
+
+	v.check(c.Storage.Host != "", "storage.host", "обязательное поле")
+	v.check(c.Storage.Port > 0 && c.Storage.Port < 65536, "storage.port", "неверный порт %d", c.Storage.Port)
+	v.check(c.Storage.Database != "", "storage.database", "обязательное поле")
+	v.check(c.Storage.MaxConns >= 1, "storage.max_conns", "должно быть положительным, задано %d", c.Storage.MaxConns)
+
+	baseURL, err := url.Parse(c.NewsAPI.BaseURL)
+	v.check(err == nil && (baseURL.Scheme == "http" || baseURL.Scheme == "https") && baseURL.Host != "", "newsapi.base_url", "неверный URL %q", c.NewsAPI.BaseURL)
+	v.check(c.NewsAPI.Timeout > 0, "newsapi.timeout", "должен быть положительным")
+	v.check(c.NewsAPI.MaxRetries >= 0, "newsapi.max_retries", "не может быть отрицательным")
+	v.check(c.NewsAPI.Backoff >= 0, "newsapi.backoff", "не может быть отрицательной")
+	v.check(c.NewsAPI.KeyStrategy == "round_robin" || c.NewsAPI.KeyStrategy == "least_used", "newsapi.key_strategy", "ожидается round_robin или least_used, задано %q", c.NewsAPI.KeyStrategy)
+	v.check(c.NewsAPI.DailyLimit > 0, "newsapi.daily_limit", "должен быть положительным")
+	v.check(c.NewsAPI.BreakerThreshold > 0, "newsapi.breaker_threshold", "должен быть положительным")
+	v.check(c.NewsAPI.BreakerCooldown > 0, "newsapi.breaker_cooldown", "должно быть положительным")
+
+	v.check(c.Cache.Backend == "memory" || c.Cache.Backend == "postgres", "cache.backend", "ожидается memory или postgres, задано %q", c.Cache.Backend)
+	v.check(c.Cache.TTL > 0, "cache.ttl", "должно быть положительным")
+
+	v.check(c.Queue.Workers >= 1, "queue.workers", "должно быть положительным, задано %d", c.Queue.Workers)
+	v.check(c.Queue.Lease >= time.Minute, "queue.lease", "должна быть не меньше минуты, задано %s", c.Queue.Lease)
+	v.check(c.Queue.MaxAttempts >= 1, "queue.max_attempts", "должно быть положительным, задано %d", c.Queue.MaxAttempts)
+
+	v.check(c.Content.FetchTimeout > 0, "content.fetch_timeout", "должен быть положительным")
+	v.check(c.Content.MaxPageSize >= 64<<10, "content.max_page_size", "должен быть не меньше 64 КиБ, задано %d", c.Content.MaxPageSize)
+
+	v.check(!c.HTTP.AdminAPIEnabled || c.HTTP.AdminToken != "", "http.admin_token", "обязателен при включённом админ-API (ADMIN_API_TOKEN)")
+	if c.HTTP.PublicURL != "" || c.HTTP.ClickTracking {
+		publicURL, err := url.Parse(c.HTTP.PublicURL)
+		v.check(err == nil && (publicURL.Scheme == "http" || publicURL.Scheme == "https") && publicURL.Host != "", "http.public_url", "неверный URL %q (HTTP_PUBLIC_URL), обязателен при включённом учёте переходов", c.HTTP.PublicURL)
+	}
+	v.check((!c.HTTP.ClickTracking && c.HTTP.PublicURL == "") || len(c.HTTP.TrackingSecret) >= 16, "http.tracking_secret", "при включённом учёте переходов или заданном public_url нужен секрет не короче 16 символов (CLICK_TRACKING_SECRET)")
+
+	v.check(c.Log.Format == "text" || c.Log.Format == "json", "log.format", "ожидается text или json, задано %q", c.Log.Format)
+
+	return errors.Join(v.errs...)
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+	"time"
+)
+
+const watchInterval = 10 * time.Second
+
+// Reloadable returns a copy of c with the fields that are safe to change at
+// runtime taken from next: categories, the news interval, the article limit
+// and the admin list.
+func (c *Config) Reloadable(next *Config) *Config {
+	merged := *c
+	merged.Bot.Categories = next.Bot.Categories
+	merged.Bot.NewsInterval = next.Bot.NewsInterval
+	merged.Bot.ArticlesPerCategory = next.Bot.ArticlesPerCategory
+	merged.Bot.AdminIDs = next.Bot.AdminIDs
+	return &merged
+}
+
+// Watch reloads the config file on SIGHUP and whenever its modification time
+// changes, until ctx is cancelled. Each valid config is narrowed to its
+// reloadable fields and passed to apply; invalid ones are logged and ignored.
+func Watch(ctx context.Context, path string, current *Config, apply func(*Config)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	modTime := fileModTime(path)
+	reload := func(reason string) {
+		next, err := Load(path)
+		if err != nil {
+			slog.Error("Конфигурация не перезагружена", "reason", reason, "error", err)
+			return
+		}
+		merged := current.Reloadable(next)
+		if !reflect.DeepEqual(merged, next) {
+			slog.Warn("Часть изменений конфигурации вступит в силу только после перезапуска", "reason", reason)
+		}
+		current = merged
+		apply(current)
+		slog.Info("Конфигурация перезагружена", "reason", reason)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			modTime = fileModTime(path)
+			reload("sighup")
+		case <-ticker.C:
+			if t := fileModTime(path); !t.Equal(modTime) {
+				modTime = t
+				reload("file_changed")
+			}
+		}
+	}
+}
+
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,123 @@
+// Package content fetches article pages and extracts their text.
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// ErrNotHTML is returned for pages that aren't HTML documents.
+var ErrNotHTML = errors.New("page is not HTML")
+
+// ErrNonPublicAddress is returned for pages on loopback, private,
+// link-local and other addresses outside the public internet.
+var ErrNonPublicAddress = errors.New("address is not public")
+
+// reservedPrefixes are non-public ranges the netip predicates don't cover.
+var reservedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// NewClient returns a client for fetching article pages that only connects
+// to public addresses, so article links can't reach this server's own
+// network. The address is checked after DNS resolution, on every connection
+// including redirects.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublic(addrPort.Addr()) {
+				return fmt.Errorf("%s: %w", address, ErrNonPublicAddress)
+			}
+			return nil
+		},
+	}
+	// No proxy: the check must see the page's own address.
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: timeout,
+		MaxIdleConns:        10,
+		IdleConnTimeout:     90 * time.Second,
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if addr.IsLoopback() || addr.IsPrivate() || addr.IsUnspecified() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reservedPrefixes {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Fetcher downloads article pages. Pages larger than maxSize are cut off,
+// and the client's timeout bounds every fetch.
+type Fetcher struct {
+	client  *http.Client
+	maxSize int64
+}
+
+func NewFetcher(client *http.Client, maxSize int64) *Fetcher {
+	return &Fetcher{client: client, maxSize: maxSize}
+}
+
+// Fetch returns the HTML of the page at url, in UTF-8.
+func (f *Fetcher) Fetch(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; tgbot)")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil &&
+		mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return "", ErrNotHTML
+	}
+
+	// Pages in legacy encodings are converted to UTF-8.
+	body, err := charset.NewReader(io.LimitReader(resp.Body, f.maxSize), resp.Header.Get("Content-Type"))
+	if err != nil {
+		return "", err
+	}
+	page, err := io.ReadAll(body)
+	if err != nil {
+		return "", err
+	}
+	return string(page), nil
+}
@@ -0,0 +1,221 @@
+package content
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Readable is the main text of a page.
+type Readable struct {
+	Title string
+	// Paragraphs holds the text blocks in page order. Headings are kept as
+	// their own blocks and list items are prefixed with a bullet.
+	Paragraphs []string
+}
+
+// Class and ID hints, as in Arc90's readability.
+var (
+	positiveHint = regexp.MustCompile(`(?i)article|body|content|entry|main|page|post|story|text`)
+	negativeHint = regexp.MustCompile(`(?i)ad-|banner|combx|comment|community|disqus|foot|menu|meta|modal|newsletter|popup|promo|related|share|shoutbox|sidebar|social|sponsor|subscribe|tags|widget`)
+)
+
+// minParagraph is the length below which a paragraph adds nothing to its
+// container's score; captions and bylines are usually shorter.
+const minParagraph = 25
+
+// Extract finds the element holding the page's main text and returns its
+// text blocks. Every paragraph scores its parent and, half as much, its
+// grandparent; scores are scaled down by link density, and the best-scored
+// element wins. Pages without a clear winner fall back to all paragraphs.
+func Extract(page string) Readable {
+	doc, err := html.Parse(strings.NewReader(page))
+	if err != nil {
+		return Readable{}
+	}
+	result := Readable{Title: pageTitle(doc)}
+
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+	addScore := func(n *html.Node, score float64) {
+		if n == nil || n.Type != html.ElementNode {
+			return
+		}
+		if _, ok := scores[n]; !ok {
+			scores[n] = hintScore(n)
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if skipped[n.DataAtom] || hidden(n) {
+				return
+			}
+			if n.DataAtom == atom.P || n.DataAtom == atom.Pre || n.DataAtom == atom.Blockquote {
+				text := nodeText(n)
+				if utf8.RuneCountInString(text) >= minParagraph {
+					score := 1 + float64(strings.Count(text, ",")) + min(float64(utf8.RuneCountInString(text))/100, 3)
+					addScore(n.Parent, score)
+					if n.Parent != nil {
+						addScore(n.Parent.Parent, score/2)
+					}
+				}
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	var best *html.Node
+	bestScore := 0.0
+	for _, n := range candidates {
+		score := scores[n] * (1 - linkDensity(n))
+		if score > bestScore {
+			best, bestScore = n, score
+		}
+	}
+	if best == nil {
+		result.Paragraphs = Paragraphs(page)
+		return result
+	}
+	result.Paragraphs = blocks(best)
+	return result
+}
+
+// blocks returns the text blocks inside n, leaving out link lists and
+// elements hinted to be page furniture.
+func blocks(n *html.Node) []string {
+	var result []string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type != html.ElementNode {
+			return
+		}
+		if skipped[n.DataAtom] || hidden(n) || furniture(n) {
+			return
+		}
+		switch n.DataAtom {
+		case atom.P, atom.Pre, atom.Blockquote, atom.H2, atom.H3, atom.H4:
+			if text := nodeText(n); text != "" && linkDensity(n) < 0.5 {
+				result = append(result, text)
+			}
+			return
+		case atom.Li:
+			if text := nodeText(n); text != "" && linkDensity(n) < 0.5 {
+				result = append(result, "• "+text)
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walk(c)
+	}
+	return result
+}
+
+// hintScore scores an element by its class and ID.
+func hintScore(n *html.Node) float64 {
+	hints := attr(n, "class") + " " + attr(n, "id")
+	score := 0.0
+	if negativeHint.MatchString(hints) {
+		score -= 25
+	}
+	if positiveHint.MatchString(hints) {
+		score += 25
+	}
+	switch n.DataAtom {
+	case atom.Article, atom.Main:
+		score += 10
+	case atom.Ul, atom.Ol, atom.Dl, atom.Table:
+		score -= 3
+	}
+	return score
+}
+
+// furniture reports whether an element is hinted to be something other
+// than article text.
+func furniture(n *html.Node) bool {
+	hints := attr(n, "class") + " " + attr(n, "id")
+	return negativeHint.MatchString(hints) && !positiveHint.MatchString(hints)
+}
+
+// linkDensity is the share of n's text that is link text.
+func linkDensity(n *html.Node) float64 {
+	total := utf8.RuneCountInString(nodeText(n))
+	if total == 0 {
+		return 0
+	}
+	links := 0
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A {
+			links += utf8.RuneCountInString(nodeText(n))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return float64(links) / float64(total)
+}
+
+func hidden(n *html.Node) bool {
+	if _, ok := attrValue(n, "hidden"); ok || attr(n, "aria-hidden") == "true" {
+		return true
+	}
+	style := strings.ReplaceAll(attr(n, "style"), " ", "")
+	return strings.Contains(style, "display:none") || strings.Contains(style, "visibility:hidden")
+}
+
+// pageTitle prefers the og:title meta tag, which unlike <title> usually
+// lacks the site name.
+func pageTitle(doc *html.Node) string {
+	var ogTitle, title string
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			switch {
+			case n.DataAtom == atom.Meta && attr(n, "property") == "og:title" && ogTitle == "":
+				ogTitle = strings.Join(strings.Fields(attr(n, "content")), " ")
+			case n.DataAtom == atom.Title && title == "":
+				title = nodeText(n)
+			case n.DataAtom == atom.Body:
+				return
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if ogTitle != "" {
+		return ogTitle
+	}
+	return title
+}
+
+func attr(n *html.Node, key string) string {
+	value, _ := attrValue(n, key)
+	return value
+}
+
+func attrValue(n *html.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
@@ -1,8 +1,17 @@
 package entities
 
 type Article struct {
+	// ID is set once the article is stored.
+	ID          int64  `json:"id,omitempty"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	URL         string `json:"url"`
 	PublishedAt string `json:"published_at"`
+	SourceID    string `json:"source_id"`
+	SourceName  string `json:"source_name"`
+	Author      string `json:"author,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	// Language is the ISO 639-1 code detected from the title and
+	// description, or empty if it couldn't be told.
+	Language string `json:"language,omitempty"`
 }
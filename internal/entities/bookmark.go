@@ -0,0 +1,9 @@
+package entities
+
+import "time"
+
+// Bookmark is an article a user saved to read later.
+type Bookmark struct {
+	Article Article   `json:"article"`
+	SavedAt time.Time `json:"saved_at"`
+}
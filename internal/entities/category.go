@@ -0,0 +1,38 @@
+package entities
+
+// Category providers say where the articles of a category come from.
+const (
+	// ProviderNewsAPI maps the category to a NewsAPI category.
+	ProviderNewsAPI = "newsapi"
+	// ProviderKeyword runs a NewsAPI full-text query.
+	ProviderKeyword = "keyword"
+	// ProviderRSS reads a set of RSS or Atom feeds.
+	ProviderRSS = "rss"
+)
+
+// Category is an entry of the category catalogue users subscribe to.
+type Category struct {
+	Slug string `json:"slug"`
+	// Names are display names by language code.
+	Names    map[string]string `json:"names"`
+	Provider string            `json:"provider"`
+	// NewsAPICategory is used by ProviderNewsAPI, Keywords by
+	// ProviderKeyword and Feeds by ProviderRSS.
+	NewsAPICategory string   `json:"newsapi_category,omitempty"`
+	Keywords        string   `json:"keywords,omitempty"`
+	Feeds           []string `json:"feeds,omitempty"`
+	Enabled         bool     `json:"enabled"`
+	SortOrder       int      `json:"sort_order"`
+	// Schedule is a cron expression or "@every <duration>" saying how often
+	// the category is polled. Empty means the default news interval.
+	Schedule string `json:"schedule,omitempty"`
+}
+
+// DisplayName returns the name of the category in the given language,
+// falling back to the slug.
+func (c Category) DisplayName(lang string) string {
+	if name := c.Names[lang]; name != "" {
+		return name
+	}
+	return c.Slug
+}
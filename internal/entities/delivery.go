@@ -0,0 +1,12 @@
+package entities
+
+import "time"
+
+// Delivery is an article sent to a user.
+type Delivery struct {
+	ID         int64     `json:"id"`
+	UserID     int64     `json:"user_id"`
+	ArticleURL string    `json:"article_url"`
+	Category   string    `json:"category"`
+	SentAt     time.Time `json:"sent_at"`
+}
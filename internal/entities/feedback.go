@@ -0,0 +1,16 @@
+package entities
+
+// Feedback votes.
+const (
+	VoteUp   = 1
+	VoteDown = -1
+)
+
+// Feedback is a user's vote on a delivered article.
+type Feedback struct {
+	UserID int64 `json:"user_id"`
+	Vote   int   `json:"vote"`
+	// Category is the category the article was stored under.
+	Category string  `json:"category"`
+	Article  Article `json:"article"`
+}
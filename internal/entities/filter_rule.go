@@ -0,0 +1,16 @@
+package entities
+
+const (
+	FilterInclude = "include"
+	FilterExclude = "exclude"
+)
+
+// FilterRule is a keyword expression a user filters deliveries with. An
+// empty Category applies the rule to every subscription.
+type FilterRule struct {
+	ID         int
+	UserID     int64
+	Category   string
+	Kind       string
+	Expression string
+}
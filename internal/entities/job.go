@@ -0,0 +1,19 @@
+package entities
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job is a unit of work in the durable job queue.
+type Job struct {
+	ID      int64           `json:"id"`
+	Kind    string          `json:"kind"`
+	Payload json.RawMessage `json:"payload"`
+	// DedupeKey, if set, keeps a second job with the same key from being
+	// queued while one is pending or running.
+	DedupeKey string    `json:"dedupe_key,omitempty"`
+	RunAt     time.Time `json:"run_at"`
+	// Attempts counts claims, including the current one.
+	Attempts int `json:"attempts"`
+}
@@ -0,0 +1,15 @@
+package entities
+
+// Media modes say how pushed articles are shown in chat.
+const (
+	// MediaPreview sends text with a large preview of the article image, or
+	// of the article page if it has no image.
+	MediaPreview = "preview"
+	// MediaPhoto sends the article image with the text as its caption.
+	MediaPhoto = "photo"
+	// MediaText sends text without a preview.
+	MediaText = "text"
+)
+
+// MediaModes lists the media modes, the default first.
+var MediaModes = []string{MediaPreview, MediaPhoto, MediaText}
@@ -0,0 +1,40 @@
+package entities
+
+import "strings"
+
+// NewsQuery selects the headlines fetched from a news provider.
+type NewsQuery struct {
+	Category string
+	Country  string
+	Language string
+	// Sources is a comma-separated list of source IDs. NewsAPI doesn't allow
+	// it together with Category or Country.
+	Sources string
+	// Keywords is a full-text query, e.g. for categories defined by keywords
+	// rather than a provider category.
+	Keywords string
+}
+
+// Country and language codes accepted by NewsAPI.
+var (
+	newsCountries = toSet("ae ar at au be bg br ca ch cn co cu cz de eg fr gb gr hk hu id ie il in it jp kr lt lv ma mx my ng nl no nz ph pl pt ro rs ru sa se sg si sk th tr tw ua us ve za")
+	newsLanguages = toSet("ar de en es fr he it nl no pt ru sv ud zh")
+)
+
+// IsNewsCountry reports whether NewsAPI serves headlines for the country.
+func IsNewsCountry(code string) bool {
+	return newsCountries[code]
+}
+
+// IsNewsLanguage reports whether NewsAPI serves headlines in the language.
+func IsNewsLanguage(code string) bool {
+	return newsLanguages[code]
+}
+
+func toSet(values string) map[string]bool {
+	set := make(map[string]bool)
+	for _, v := range strings.Fields(values) {
+		set[v] = true
+	}
+	return set
+}
@@ -0,0 +1,20 @@
+package entities
+
+// Source is an outlet from the NewsAPI sources catalogue.
+type Source struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	URL         string `json:"url"`
+	Category    string `json:"category"`
+	Language    string `json:"language"`
+	Country     string `json:"country"`
+}
+
+// SourceFollow subscribes a user to every article of a source.
+type SourceFollow struct {
+	UserID   int64
+	SourceID string
+	// ThreadID is the forum topic deliveries go to; 0 is General.
+	ThreadID int
+}
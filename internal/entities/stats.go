@@ -0,0 +1,30 @@
+package entities
+
+// Stats is the operator overview shown by /stats.
+type Stats struct {
+	Users                   int
+	ActiveUsers             int
+	BannedUsers             int
+	SubscriptionsByCategory map[string]int
+	DeliveriesToday         int
+	// ClicksByCategory and ClicksBySource report the click-through of the
+	// last week's tracked deliveries, most delivered first.
+	ClicksByCategory []ClickThrough
+	ClicksBySource   []ClickThrough
+}
+
+// ClickThrough counts the tracked deliveries of a category or source and how
+// many of them were opened.
+type ClickThrough struct {
+	Name       string
+	Deliveries int
+	Clicks     int
+}
+
+// Rate returns the share of deliveries that were opened.
+func (c ClickThrough) Rate() float64 {
+	if c.Deliveries == 0 {
+		return 0
+	}
+	return float64(c.Clicks) / float64(c.Deliveries)
+}
@@ -1,7 +1,34 @@
 package entities
 
+import "strings"
+
 type Subscription struct {
-	ID       int
-	UserID   int64
-	Category string
+	ID       int    `json:"id"`
+	UserID   int64  `json:"user_id"`
+	Category string `json:"category"`
+	Country  string `json:"country"`
+	Language string `json:"language"`
+	// ThreadID is the forum topic deliveries go to; 0 is General.
+	ThreadID int `json:"thread_id,omitempty"`
+}
+
+// Query returns the provider query that serves this subscription.
+func (s Subscription) Query() NewsQuery {
+	return NewsQuery{Category: s.Category, Country: s.Country, Language: s.Language}
+}
+
+// Label describes the subscription for users, e.g.
+// "technology (страна: ru, язык: ru)".
+func (s Subscription) Label() string {
+	var filters []string
+	if s.Country != "" {
+		filters = append(filters, "страна: "+s.Country)
+	}
+	if s.Language != "" {
+		filters = append(filters, "язык: "+s.Language)
+	}
+	if len(filters) == 0 {
+		return s.Category
+	}
+	return s.Category + " (" + strings.Join(filters, ", ") + ")"
 }
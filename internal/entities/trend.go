@@ -0,0 +1,10 @@
+package entities
+
+// TrendScore is the peak score of a story, the number of sources that
+// covered it within the detection window.
+type TrendScore struct {
+	Category string
+	// ArticleID is the story's lead article.
+	ArticleID int64
+	Score     int
+}
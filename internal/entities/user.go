@@ -1,5 +1,25 @@
 package entities
 
+import "time"
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Chat types, as Telegram names them. A user row stands for the chat news is
+// delivered to; in private chats its ID is the user's own.
+const (
+	ChatPrivate    = "private"
+	ChatGroup      = "group"
+	ChatSuperGroup = "supergroup"
+	ChatChannel    = "channel"
+)
+
 type User struct {
-	ID int64
+	ID           int64     `json:"id"`
+	Role         string    `json:"role"`
+	Banned       bool      `json:"banned"`
+	ChatType     string    `json:"chat_type"`
+	LastActiveAt time.Time `json:"last_active_at"`
 }
@@ -0,0 +1,255 @@
+// Package filter implements the keyword expressions users filter articles
+// with, e.g. `AI OR "machine learning"` or `crypto* -bitcoin`.
+//
+// Terms are matched case-insensitively against whole words; a trailing `*`
+// matches any word with that prefix and a quoted phrase matches consecutive
+// words. Terms next to each other are combined with AND; OR, NOT and
+// parentheses are supported, as are the `|`, `-` and `!` shorthands.
+package filter
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Expr is a parsed filter expression.
+type Expr interface {
+	Match(words []string) bool
+}
+
+type termExpr struct {
+	words  []string
+	prefix bool
+}
+
+func (e termExpr) Match(words []string) bool {
+	for i := 0; i+len(e.words) <= len(words); i++ {
+		matched := true
+		for j, w := range e.words {
+			last := j == len(e.words)-1
+			if words[i+j] != w && !(last && e.prefix && strings.HasPrefix(words[i+j], w)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+type andExpr struct{ left, right Expr }
+
+func (e andExpr) Match(words []string) bool { return e.left.Match(words) && e.right.Match(words) }
+
+type orExpr struct{ left, right Expr }
+
+func (e orExpr) Match(words []string) bool { return e.left.Match(words) || e.right.Match(words) }
+
+type notExpr struct{ expr Expr }
+
+func (e notExpr) Match(words []string) bool { return !e.expr.Match(words) }
+
+// Words splits text into lower-cased words the way expressions are matched.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Parse compiles an expression.
+func Parse(input string) (Expr, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("пустое выражение")
+	}
+
+	p := &parser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("неожиданный токен %q", p.tokens[p.pos].text)
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokenTerm tokenKind = iota
+	tokenPhrase
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenOpen
+	tokenClose
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func tokenize(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenOpen, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenClose, text: ")"})
+			i++
+		case r == '-' || r == '!':
+			tokens = append(tokens, token{kind: tokenNot, text: string(r)})
+			i++
+		case r == '|' || r == '&':
+			j := i
+			for j < len(runes) && runes[j] == r {
+				j++
+			}
+			kind := tokenOr
+			if r == '&' {
+				kind = tokenAnd
+			}
+			tokens = append(tokens, token{kind: kind, text: string(runes[i:j])})
+			i = j
+		case r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+			if j == len(runes) {
+				return nil, fmt.Errorf("незакрытая кавычка")
+			}
+			tokens = append(tokens, token{kind: tokenPhrase, text: string(runes[i+1 : j])})
+			i = j + 1
+		default:
+			j := i
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune(`()"|&`, runes[j]) {
+				j++
+			}
+			word := string(runes[i:j])
+			switch strings.ToUpper(word) {
+			case "AND":
+				tokens = append(tokens, token{kind: tokenAnd, text: word})
+			case "OR":
+				tokens = append(tokens, token{kind: tokenOr, text: word})
+			case "NOT":
+				tokens = append(tokens, token{kind: tokenNot, text: word})
+			default:
+				tokens = append(tokens, token{kind: tokenTerm, text: word})
+			}
+			i = j
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) parseOr() (Expr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind != tokenOr {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (Expr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		tok, ok := p.peek()
+		if !ok || tok.kind == tokenOr || tok.kind == tokenClose {
+			return left, nil
+		}
+		if tok.kind == tokenAnd {
+			p.pos++
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (Expr, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("выражение оборвано")
+	}
+	if tok.kind == tokenNot {
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr: expr}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Expr, error) {
+	tok, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("выражение оборвано")
+	}
+	p.pos++
+
+	switch tok.kind {
+	case tokenOpen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next, ok := p.peek(); !ok || next.kind != tokenClose {
+			return nil, fmt.Errorf("не хватает закрывающей скобки")
+		}
+		p.pos++
+		return expr, nil
+	case tokenTerm, tokenPhrase:
+		prefix := strings.HasSuffix(tok.text, "*")
+		words := Words(strings.TrimSuffix(tok.text, "*"))
+		if len(words) == 0 {
+			return nil, fmt.Errorf("пустой термин %q", tok.text)
+		}
+		return termExpr{words: words, prefix: prefix}, nil
+	}
+	return nil, fmt.Errorf("неожиданный токен %q", tok.text)
+}
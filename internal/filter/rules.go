@@ -0,0 +1,54 @@
+package filter
+
+import (
+	"tgbot/internal/entities"
+)
+
+type compiledRule struct {
+	category string
+	kind     string
+	expr     Expr
+}
+
+// RuleSet decides which articles a user wants to receive.
+//
+// An article is dropped if any exclude rule matches it. If there are
+// include rules, the article must match every one of them. Rules with a
+// category only apply to articles delivered for that category.
+type RuleSet struct {
+	rules []compiledRule
+}
+
+// NewRuleSet compiles the rules, skipping any that no longer parse.
+func NewRuleSet(rules []entities.FilterRule) *RuleSet {
+	set := &RuleSet{}
+	for _, rule := range rules {
+		expr, err := Parse(rule.Expression)
+		if err != nil {
+			continue
+		}
+		set.rules = append(set.rules, compiledRule{category: rule.Category, kind: rule.Kind, expr: expr})
+	}
+	return set
+}
+
+func (s *RuleSet) Allow(category string, article *entities.Article) bool {
+	if s == nil || len(s.rules) == 0 {
+		return true
+	}
+
+	words := Words(article.Title + " " + article.Description)
+	for _, rule := range s.rules {
+		if rule.category != "" && rule.category != category {
+			continue
+		}
+		matched := rule.expr.Match(words)
+		if rule.kind == entities.FilterExclude && matched {
+			return false
+		}
+		if rule.kind == entities.FilterInclude && !matched {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,143 @@
+// Package langdetect guesses the language of short texts such as headlines.
+package langdetect
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Languages lists the ISO 639-1 codes Detect can return.
+var Languages = []string{"ar", "de", "el", "en", "es", "fr", "he", "it", "ja", "ko", "nl", "no", "pt", "ru", "sv", "uk", "zh"}
+
+const (
+	// minTrigrams is the least text Detect guesses from.
+	minTrigrams = 6
+	// minMargin is how much more likely, in nats, the text must be in the
+	// best language than in the runner-up: about seven times.
+	minMargin = 2.0
+	// unseen smooths the counts of trigrams missing from a profile.
+	unseen = 0.5
+)
+
+type profile struct {
+	counts map[string]float64
+	total  float64
+}
+
+// profiles holds the trigram profiles of the languages written in the Latin
+// and Cyrillic scripts, by script.
+var profiles = buildProfiles()
+
+func buildProfiles() map[*unicode.RangeTable]map[string]*profile {
+	result := map[*unicode.RangeTable]map[string]*profile{
+		unicode.Latin:    {},
+		unicode.Cyrillic: {},
+	}
+	for lang, text := range samples {
+		p := &profile{counts: make(map[string]float64)}
+		for _, trigram := range trigrams(text) {
+			p.counts[trigram]++
+			p.total++
+		}
+		result[dominantScript(text)][lang] = p
+	}
+	return result
+}
+
+// Detect returns the language of the text, or an empty string if it can't
+// tell. Scripts used by one language decide by themselves; Latin and
+// Cyrillic text is scored against each language's trigram frequencies.
+func Detect(text string) string {
+	script := dominantScript(text)
+	switch script {
+	case nil:
+		return ""
+	case unicode.Arabic:
+		return "ar"
+	case unicode.Hebrew:
+		return "he"
+	case unicode.Greek:
+		return "el"
+	case unicode.Hangul:
+		return "ko"
+	case unicode.Hiragana, unicode.Katakana:
+		return "ja"
+	case unicode.Han:
+		// Japanese mixes kanji with kana.
+		if strings.ContainsFunc(text, func(r rune) bool { return unicode.In(r, unicode.Hiragana, unicode.Katakana) }) {
+			return "ja"
+		}
+		return "zh"
+	}
+
+	grams := trigrams(text)
+	if len(grams) < minTrigrams {
+		return ""
+	}
+	type score struct {
+		lang  string
+		value float64
+	}
+	var scores []score
+	for lang, p := range profiles[script] {
+		value := 0.0
+		for _, gram := range grams {
+			value += math.Log((p.counts[gram] + unseen) / p.total)
+		}
+		scores = append(scores, score{lang, value})
+	}
+	if len(scores) == 0 {
+		return ""
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].value > scores[j].value })
+	if len(scores) > 1 && scores[0].value-scores[1].value < minMargin {
+		return ""
+	}
+	return scores[0].lang
+}
+
+// scripts are the scripts dominantScript tells apart.
+var scripts = []*unicode.RangeTable{
+	unicode.Latin, unicode.Cyrillic, unicode.Arabic, unicode.Hebrew, unicode.Greek,
+	unicode.Hangul, unicode.Hiragana, unicode.Katakana, unicode.Han,
+}
+
+// dominantScript returns the script most letters of the text are written
+// in, or nil if there are no letters.
+func dominantScript(text string) *unicode.RangeTable {
+	counts := make(map[*unicode.RangeTable]int)
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		for _, script := range scripts {
+			if unicode.Is(script, r) {
+				counts[script]++
+				break
+			}
+		}
+	}
+	var best *unicode.RangeTable
+	for _, script := range scripts {
+		if counts[script] > counts[best] {
+			best = script
+		}
+	}
+	return best
+}
+
+// trigrams returns the letter trigrams of the lower-cased words of the text,
+// with words padded by spaces so that their starts and ends count.
+func trigrams(text string) []string {
+	var result []string
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			result = append(result, string(runes[i:i+3]))
+		}
+	}
+	return result
+}
@@ -0,0 +1,113 @@
+package langdetect
+
+// samples is the training text of the languages told apart by trigrams:
+// news-style prose, so that the profiles match headlines and leads.
+var samples = map[string]string{
+	"en": `The government said on Tuesday that it would raise interest rates for the first time in two years,
+		as inflation continues to climb across the country. Officials warned that the economy could slow down
+		and that prices for food and energy are likely to remain high through the winter. The president met
+		with leaders of the largest companies to discuss the new rules, which have been criticised by workers
+		and unions. Police are investigating the attack, which happened late on Sunday night near the station.
+		Scientists have found evidence that the climate is changing faster than expected, according to a report
+		published this week. The team will play their next match at home against the champions, and the coach
+		says he is confident that they can win. Shares of the technology giant fell sharply after the company
+		reported lower profits than analysts had expected. Thousands of people gathered in the capital to protest
+		against the war and called for an end to the fighting. What you need to know about the election.`,
+	"de": `Die Bundesregierung hat am Dienstag angekündigt, dass die Steuern für kleine Unternehmen gesenkt werden
+		sollen. Nach Angaben des Ministeriums wird die Inflation in diesem Jahr weiter steigen, während die Wirtschaft
+		nur langsam wächst. Die Polizei ermittelt nach einem Unfall auf der Autobahn, bei dem mehrere Menschen
+		verletzt wurden. Der Bundeskanzler traf sich mit den Ministerpräsidenten der Länder, um über die neuen
+		Regeln für die Energieversorgung zu sprechen. Wissenschaftler warnen, dass sich das Klima schneller
+		verändert als erwartet. Die Mannschaft hat das Spiel gegen den Tabellenführer überraschend gewonnen und
+		steht damit im Finale. Die Aktien des Konzerns sind nach der Veröffentlichung der Zahlen deutlich gefallen.
+		Tausende Menschen haben in der Hauptstadt gegen den Krieg demonstriert und ein Ende der Kämpfe gefordert.
+		Was Sie über die Wahl wissen müssen und warum die Zeit für eine Entscheidung knapp wird.`,
+	"fr": `Le gouvernement a annoncé mardi une hausse des taux d'intérêt pour la première fois depuis deux ans,
+		alors que l'inflation continue de progresser dans le pays. Selon les responsables, l'économie pourrait
+		ralentir et les prix de l'énergie et de l'alimentation devraient rester élevés pendant l'hiver. Le président
+		de la République a rencontré les dirigeants des grandes entreprises pour discuter des nouvelles règles,
+		critiquées par les syndicats. La police enquête sur l'attaque qui a eu lieu dimanche soir près de la gare.
+		Des scientifiques ont découvert que le climat change plus vite que prévu, selon un rapport publié cette
+		semaine. L'équipe jouera son prochain match à domicile contre les champions et l'entraîneur se dit confiant.
+		Les actions du géant de la technologie ont fortement chuté après des bénéfices inférieurs aux attentes.
+		Des milliers de personnes se sont rassemblées dans la capitale pour protester contre la guerre.
+		Ce qu'il faut savoir sur les élections et pourquoi le vote est important pour l'avenir du pays.`,
+	"es": `El gobierno anunció el martes que subirá los tipos de interés por primera vez en dos años, mientras la
+		inflación sigue aumentando en el país. Según las autoridades, la economía podría desacelerarse y los precios
+		de la energía y de los alimentos seguirán siendo altos durante el invierno. El presidente se reunió con los
+		líderes de las grandes empresas para hablar de las nuevas normas, que han sido criticadas por los sindicatos.
+		La policía investiga el ataque que ocurrió el domingo por la noche cerca de la estación. Los científicos han
+		descubierto que el clima está cambiando más rápido de lo esperado, según un informe publicado esta semana.
+		El equipo jugará su próximo partido en casa contra los campeones y el entrenador dice que está seguro de
+		ganar. Las acciones de la empresa tecnológica cayeron con fuerza tras presentar unos beneficios menores.
+		Miles de personas se concentraron en la capital para protestar contra la guerra y pedir el fin de los
+		combates. Lo que hay que saber sobre las elecciones y por qué son tan importantes para el futuro.`,
+	"it": `Il governo ha annunciato martedì che alzerà i tassi di interesse per la prima volta in due anni, mentre
+		l'inflazione continua a crescere nel paese. Secondo le autorità, l'economia potrebbe rallentare e i prezzi
+		dell'energia e dei prodotti alimentari resteranno alti per tutto l'inverno. Il presidente del Consiglio ha
+		incontrato i dirigenti delle grandi aziende per discutere delle nuove regole, criticate dai sindacati.
+		La polizia indaga sull'attacco avvenuto domenica sera vicino alla stazione. Gli scienziati hanno scoperto
+		che il clima sta cambiando più velocemente del previsto, secondo un rapporto pubblicato questa settimana.
+		La squadra giocherà la prossima partita in casa contro i campioni e l'allenatore si dice fiducioso.
+		Le azioni del colosso tecnologico sono crollate dopo che la società ha comunicato utili più bassi del
+		previsto. Migliaia di persone si sono radunate nella capitale per protestare contro la guerra e chiedere
+		la fine dei combattimenti. Cosa c'è da sapere sulle elezioni e perché sono così importanti per il futuro.`,
+	"pt": `O governo anunciou na terça-feira que vai aumentar as taxas de juro pela primeira vez em dois anos,
+		enquanto a inflação continua a subir no país. Segundo as autoridades, a economia pode abrandar e os preços
+		da energia e dos alimentos devem continuar elevados durante o inverno. O presidente reuniu-se com os
+		líderes das maiores empresas para discutir as novas regras, que foram criticadas pelos sindicatos.
+		A polícia investiga o ataque que aconteceu no domingo à noite perto da estação. Os cientistas descobriram
+		que o clima está a mudar mais depressa do que o esperado, segundo um relatório publicado esta semana.
+		A equipa vai jogar o próximo jogo em casa contra os campeões e o treinador diz que está confiante.
+		As ações da gigante tecnológica caíram fortemente depois de a empresa ter apresentado lucros abaixo do
+		esperado. Milhares de pessoas reuniram-se na capital para protestar contra a guerra e pedir o fim dos
+		combates. O que você precisa saber sobre as eleições e por que elas são tão importantes para o futuro.`,
+	"nl": `De regering heeft dinsdag aangekondigd dat de rente voor het eerst in twee jaar wordt verhoogd, terwijl
+		de inflatie in het land blijft stijgen. Volgens de autoriteiten kan de economie vertragen en blijven de
+		prijzen van energie en voedsel de hele winter hoog. De minister-president sprak met de leiders van de
+		grootste bedrijven over de nieuwe regels, die door de vakbonden worden bekritiseerd. De politie onderzoekt
+		de aanval die zondagavond laat bij het station plaatsvond. Wetenschappers hebben ontdekt dat het klima
+		sneller verandert dan verwacht, volgens een rapport dat deze week is gepubliceerd. Het team speelt de
+		volgende wedstrijd thuis tegen de kampioen en de trainer zegt dat hij vertrouwen heeft in een overwinning.
+		De aandelen van het technologiebedrijf daalden sterk nadat het bedrijf lagere winsten had gemeld.
+		Duizenden mensen kwamen in de hoofdstad bijeen om tegen de oorlog te protesteren en een einde aan de
+		gevechten te eisen. Wat je moet weten over de verkiezingen en waarom ze zo belangrijk zijn.`,
+	"sv": `Regeringen meddelade på tisdagen att räntan ska höjas för första gången på två år, samtidigt som
+		inflationen fortsätter att stiga i landet. Enligt myndigheterna kan ekonomin bromsa in och priserna på
+		energi och mat väntas vara fortsatt höga under hela vintern. Statsministern träffade ledarna för de största
+		företagen för att diskutera de nya reglerna, som har kritiserats av fackförbunden. Polisen utreder attacken
+		som inträffade sent på söndagskvällen nära stationen. Forskare har upptäckt att klimatet förändras snabbare
+		än väntat, enligt en rapport som publicerades i veckan. Laget spelar nästa match på hemmaplan mot mästarna
+		och tränaren säger att han är säker på att de kan vinna. Teknikjättens aktier föll kraftigt efter att
+		företaget rapporterat lägre vinster än väntat. Tusentals människor samlades i huvudstaden för att protestera
+		mot kriget och kräva ett slut på striderna. Det här behöver du veta om valet och varför det är viktigt.`,
+	"no": `Regjeringen kunngjorde tirsdag at renten skal settes opp for første gang på to år, mens prisveksten
+		fortsetter å øke i landet. Ifølge myndighetene kan økonomien bremse opp, og prisene på strøm og mat ventes
+		å holde seg høye gjennom hele vinteren. Statsministeren møtte lederne for de største selskapene for å
+		diskutere de nye reglene, som har blitt kritisert av fagforeningene. Politiet etterforsker angrepet som
+		skjedde sent søndag kveld i nærheten av stasjonen. Forskere har funnet ut at klimaet endrer seg raskere
+		enn ventet, ifølge en rapport som ble publisert denne uken. Laget spiller neste kamp på hjemmebane mot
+		mesterne, og treneren sier at han er sikker på at de kan vinne. Aksjene i teknologiselskapet falt kraftig
+		etter at selskapet meldte om lavere overskudd enn ventet. Tusenvis av mennesker samlet seg i hovedstaden
+		for å protestere mot krigen og kreve en slutt på kampene. Dette må du vite om valget og hvorfor det er viktig.`,
+	"ru": `Правительство во вторник объявило, что впервые за два года повысит процентные ставки, поскольку
+		инфляция в стране продолжает расти. По словам чиновников, экономика может замедлиться, а цены на энергию
+		и продукты питания останутся высокими всю зиму. Президент встретился с руководителями крупнейших компаний,
+		чтобы обсудить новые правила, которые подверглись критике со стороны профсоюзов. Полиция расследует
+		нападение, которое произошло поздно вечером в воскресенье недалеко от вокзала. Учёные обнаружили, что
+		климат меняется быстрее, чем ожидалось, говорится в докладе, опубликованном на этой неделе. Команда
+		сыграет следующий матч дома против чемпионов, и тренер уверен, что они смогут победить. Акции
+		технологического гиганта резко упали после того, как компания сообщила о снижении прибыли. Тысячи
+		человек собрались в столице, чтобы выступить против войны и потребовать прекращения боевых действий.
+		Что нужно знать о выборах и почему они так важны для будущего страны.`,
+	"uk": `Уряд у вівторок оголосив, що вперше за два роки підвищить процентні ставки, оскільки інфляція в
+		країні продовжує зростати. За словами посадовців, економіка може сповільнитися, а ціни на енергію та
+		продукти харчування залишатимуться високими всю зиму. Президент зустрівся з керівниками найбільших
+		компаній, щоб обговорити нові правила, які розкритикували профспілки. Поліція розслідує напад, що стався
+		пізно ввечері в неділю неподалік від вокзалу. Науковці виявили, що клімат змінюється швидше, ніж
+		очікувалося, йдеться у звіті, оприлюдненому цього тижня. Команда зіграє наступний матч удома проти
+		чемпіонів, і тренер упевнений, що вони зможуть перемогти. Акції технологічного гіганта різко впали
+		після того, як компанія повідомила про зниження прибутку. Тисячі людей зібралися в столиці, щоб
+		виступити проти війни та вимагати припинення бойових дій. Що треба знати про вибори і чому вони такі
+		важливі для майбутнього країни.`,
+}
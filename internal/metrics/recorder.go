@@ -0,0 +1,30 @@
+package metrics
+
+import "time"
+
+// Recorder receives the bot's operational events. Registry exports them to
+// Prometheus; tests can plug in their own implementation.
+type Recorder interface {
+	// CommandHandled counts a bot command by name and outcome.
+	CommandHandled(command, outcome string)
+	// NewsAPIRequest observes one HTTP request to NewsAPI. Status is the HTTP
+	// status code or "error" if no response was received.
+	NewsAPIRequest(status string, duration time.Duration)
+	// ArticlesFetched counts the articles fetched for a category and how
+	// many of them hadn't been sent before.
+	ArticlesFetched(category string, fetched, fresh int)
+	// MessageSent counts a Telegram message by result: "ok" or an error class.
+	MessageSent(result string)
+	// NewsCycle observes the duration of one CheckAndSendNews cycle.
+	NewsCycle(duration time.Duration)
+}
+
+// Nop discards everything. It is the default of every component that takes a
+// Recorder.
+type Nop struct{}
+
+func (Nop) CommandHandled(command, outcome string)               {}
+func (Nop) NewsAPIRequest(status string, duration time.Duration) {}
+func (Nop) ArticlesFetched(category string, fetched, fresh int)  {}
+func (Nop) MessageSent(result string)                            {}
+func (Nop) NewsCycle(duration time.Duration)                     {}
@@ -0,0 +1,234 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+	cycleBuckets   = []float64{1, 5, 10, 30, 60, 120, 300, 600}
+)
+
+// Sample is a value read at scrape time, e.g. connection pool statistics.
+type Sample struct {
+	Name string
+	Help string
+	// Type is "gauge" or "counter".
+	Type  string
+	Value float64
+}
+
+// Registry keeps the bot's metrics in memory and serves them in the
+// Prometheus text exposition format.
+type Registry struct {
+	mu          sync.Mutex
+	commands    *counterVec
+	apiRequests *histogramVec
+	fetched     *counterVec
+	fresh       *counterVec
+	messages    *counterVec
+	cycles      *histogramVec
+	collectors  []func() []Sample
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		commands:    newCounterVec("tgbot_commands_total", "Bot commands handled, by command and outcome.", "command", "outcome"),
+		apiRequests: newHistogramVec("tgbot_newsapi_request_duration_seconds", "NewsAPI request latency, by HTTP status.", latencyBuckets, "status"),
+		fetched:     newCounterVec("tgbot_articles_fetched_total", "Articles fetched from the news provider, by category.", "category"),
+		fresh:       newCounterVec("tgbot_articles_new_total", "Fetched articles that had not been sent before, by category.", "category"),
+		messages:    newCounterVec("tgbot_messages_total", "Telegram messages sent, by result.", "result"),
+		cycles:      newHistogramVec("tgbot_news_cycle_duration_seconds", "Duration of CheckAndSendNews cycles.", cycleBuckets),
+	}
+}
+
+// AddCollector registers a function whose samples are read on every scrape.
+func (r *Registry) AddCollector(collect func() []Sample) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, collect)
+}
+
+func (r *Registry) CommandHandled(command, outcome string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.commands.add(1, command, outcome)
+}
+
+func (r *Registry) NewsAPIRequest(status string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.apiRequests.observe(duration.Seconds(), status)
+}
+
+func (r *Registry) ArticlesFetched(category string, fetched, fresh int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.fetched.add(float64(fetched), category)
+	r.fresh.add(float64(fresh), category)
+}
+
+func (r *Registry) MessageSent(result string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages.add(1, result)
+}
+
+func (r *Registry) NewsCycle(duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cycles.observe(duration.Seconds())
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	r.WriteTo(w)
+}
+
+// WriteTo writes all metrics in the Prometheus text format.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	var b strings.Builder
+	r.commands.write(&b)
+	r.apiRequests.write(&b)
+	r.fetched.write(&b)
+	r.fresh.write(&b)
+	r.messages.write(&b)
+	r.cycles.write(&b)
+	collectors := append([]func() []Sample(nil), r.collectors...)
+	r.mu.Unlock()
+
+	for _, collect := range collectors {
+		for _, s := range collect() {
+			fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s %s\n", s.Name, s.Help, s.Name, s.Type, s.Name, formatFloat(s.Value))
+		}
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+type counterVec struct {
+	name, help string
+	labelNames []string
+	values     map[string]float64
+	labels     map[string][]string
+}
+
+func newCounterVec(name, help string, labelNames ...string) *counterVec {
+	return &counterVec{
+		name:       name,
+		help:       help,
+		labelNames: labelNames,
+		values:     make(map[string]float64),
+		labels:     make(map[string][]string),
+	}
+}
+
+func (c *counterVec) add(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	c.values[key] += v
+	c.labels[key] = labelValues
+}
+
+func (c *counterVec) write(b *strings.Builder) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name)
+	for _, key := range sortedKeys(c.values) {
+		fmt.Fprintf(b, "%s%s %s\n", c.name, formatLabels(c.labelNames, c.labels[key]), formatFloat(c.values[key]))
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+type histogramVec struct {
+	name, help string
+	buckets    []float64
+	labelNames []string
+	values     map[string]*histogram
+	labels     map[string][]string
+}
+
+func newHistogramVec(name, help string, buckets []float64, labelNames ...string) *histogramVec {
+	return &histogramVec{
+		name:       name,
+		help:       help,
+		buckets:    buckets,
+		labelNames: labelNames,
+		values:     make(map[string]*histogram),
+		labels:     make(map[string][]string),
+	}
+}
+
+func (h *histogramVec) observe(v float64, labelValues ...string) {
+	key := strings.Join(labelValues, "\xff")
+	hist, ok := h.values[key]
+	if !ok {
+		hist = &histogram{counts: make([]uint64, len(h.buckets))}
+		h.values[key] = hist
+		h.labels[key] = labelValues
+	}
+	for i, upper := range h.buckets {
+		if v <= upper {
+			hist.counts[i]++
+		}
+	}
+	hist.sum += v
+	hist.count++
+}
+
+func (h *histogramVec) write(b *strings.Builder) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name)
+	bucketLabels := append(append([]string(nil), h.labelNames...), "le")
+	for _, key := range sortedKeys(h.values) {
+		hist, labelValues := h.values[key], h.labels[key]
+		for i, upper := range h.buckets {
+			values := append(append([]string(nil), labelValues...), formatFloat(upper))
+			fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabels, values), hist.counts[i])
+		}
+		values := append(append([]string(nil), labelValues...), "+Inf")
+		fmt.Fprintf(b, "%s_bucket%s %d\n", h.name, formatLabels(bucketLabels, values), hist.count)
+		fmt.Fprintf(b, "%s_sum%s %s\n", h.name, formatLabels(h.labelNames, labelValues), formatFloat(hist.sum))
+		fmt.Fprintf(b, "%s_count%s %d\n", h.name, formatLabels(h.labelNames, labelValues), hist.count)
+	}
+}
+
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + `="` + escapeLabel(values[i]) + `"`
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,260 @@
+// Package queue runs jobs from the durable job queue.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"tgbot/internal/entities"
+	"tgbot/internal/logging"
+	"time"
+)
+
+const (
+	defaultConcurrency  = 2
+	defaultLease        = 5 * time.Minute
+	defaultPollInterval = time.Second
+	defaultMaxAttempts  = 5
+	// retryBackoff is multiplied by the square of the attempt number.
+	retryBackoff = 10 * time.Second
+)
+
+// ErrLeaseLost is returned by a Store for a job the worker no longer holds:
+// its lease ran out and another worker claimed it.
+var ErrLeaseLost = errors.New("job lease lost")
+
+// Store is the persistent side of the queue. Extend, Complete, Retry and
+// Bury only act on jobs still locked by workerID.
+type Store interface {
+	Claim(ctx context.Context, workerID string, lease time.Duration) (*entities.Job, error)
+	Extend(ctx context.Context, id int64, workerID string, lease time.Duration) error
+	Complete(ctx context.Context, id int64, workerID string) error
+	Retry(ctx context.Context, id int64, workerID string, runAt time.Time, lastError string) error
+	Bury(ctx context.Context, id int64, workerID string, lastError string) error
+}
+
+// Handler processes the payload of a job. A returned error retries the job
+// with backoff until it runs out of attempts.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+type Option func(*Worker)
+
+// WithConcurrency sets how many jobs the worker runs at once.
+func WithConcurrency(n int) Option {
+	return func(w *Worker) {
+		w.concurrency = n
+	}
+}
+
+// WithLease sets how long a claimed job stays locked. The lease is renewed
+// while the job runs; a job whose worker stopped renewing it may be claimed
+// by another worker once it runs out.
+func WithLease(lease time.Duration) Option {
+	return func(w *Worker) {
+		w.lease = lease
+	}
+}
+
+// WithPollInterval sets how long an idle worker waits before looking for
+// due jobs again.
+func WithPollInterval(interval time.Duration) Option {
+	return func(w *Worker) {
+		w.pollInterval = interval
+	}
+}
+
+// WithMaxAttempts sets how many times a failing job runs before it is
+// buried.
+func WithMaxAttempts(n int) Option {
+	return func(w *Worker) {
+		w.maxAttempts = n
+	}
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(w *Worker) {
+		w.logger = logger
+	}
+}
+
+// Worker claims due jobs and runs the handler registered for their kind.
+type Worker struct {
+	store        Store
+	id           string
+	concurrency  int
+	lease        time.Duration
+	pollInterval time.Duration
+	maxAttempts  int
+	logger       *slog.Logger
+
+	mu       sync.RWMutex
+	handlers map[string]Handler
+}
+
+func NewWorker(store Store, opts ...Option) *Worker {
+	host, _ := os.Hostname()
+	w := &Worker{
+		store:        store,
+		id:           host + "-" + logging.NewID(),
+		concurrency:  defaultConcurrency,
+		lease:        defaultLease,
+		pollInterval: defaultPollInterval,
+		maxAttempts:  defaultMaxAttempts,
+		logger:       slog.Default(),
+		handlers:     make(map[string]Handler),
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Handle registers the handler for jobs of the given kind.
+func (w *Worker) Handle(kind string, handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[kind] = handler
+}
+
+// Run processes jobs until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	w.logger.Info("Job worker started", "worker_id", w.id, "concurrency", w.concurrency)
+	var wg sync.WaitGroup
+	for range w.concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for {
+		// Keep claiming while there is work; sleep only when idle.
+		if w.RunOnce(ctx) {
+			if ctx.Err() != nil {
+				return
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.pollInterval):
+		}
+	}
+}
+
+// RunOnce claims and runs one due job. It reports whether there was one.
+func (w *Worker) RunOnce(ctx context.Context) bool {
+	job, err := w.store.Claim(ctx, w.id, w.lease)
+	if err != nil {
+		if ctx.Err() == nil {
+			w.logger.Error("Error claiming job", "error", err)
+		}
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	logger := w.logger.With("job_id", job.ID, "kind", job.Kind, "attempt", job.Attempts)
+	ctx = logging.WithLogger(ctx, logger)
+	start := time.Now()
+	jobCtx, cancel := context.WithCancel(ctx)
+	stop := w.keepLeased(ctx, logger, job, cancel)
+	err = w.run(jobCtx, job)
+	stop()
+	cancel()
+	if err != nil {
+		w.fail(ctx, logger, job, err)
+		return true
+	}
+	if err := w.store.Complete(ctx, job.ID, w.id); err != nil {
+		logStoreError(logger, "Error completing job", err)
+		return true
+	}
+	logger.Debug("Job done", "duration", time.Since(start))
+	return true
+}
+
+// keepLeased renews the job's lease every third of the lease until stop is
+// called. If the lease was lost to another worker, it cancels the job.
+func (w *Worker) keepLeased(ctx context.Context, logger *slog.Logger, job *entities.Job, cancelJob context.CancelFunc) (stop func()) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(w.lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			err := w.store.Extend(ctx, job.ID, w.id, w.lease)
+			if errors.Is(err, ErrLeaseLost) {
+				logger.Warn("Job lease lost, cancelling job")
+				cancelJob()
+				return
+			}
+			if err != nil && ctx.Err() == nil {
+				logger.Error("Error extending job lease", "error", err)
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		wg.Wait()
+	}
+}
+
+// logStoreError logs a failed update of a finished job. A lost lease is
+// expected after a slow run, as the job now belongs to another worker.
+func logStoreError(logger *slog.Logger, msg string, err error) {
+	if errors.Is(err, ErrLeaseLost) {
+		logger.Warn(msg, "error", err)
+		return
+	}
+	logger.Error(msg, "error", err)
+}
+
+func (w *Worker) run(ctx context.Context, job *entities.Job) (err error) {
+	w.mu.RLock()
+	handler, ok := w.handlers[job.Kind]
+	w.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("no handler for job kind %q", job.Kind)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(ctx, job.Payload)
+}
+
+func (w *Worker) fail(ctx context.Context, logger *slog.Logger, job *entities.Job, jobErr error) {
+	if job.Attempts >= w.maxAttempts {
+		logger.Error("Job failed, giving up", "error", jobErr)
+		if err := w.store.Bury(ctx, job.ID, w.id, jobErr.Error()); err != nil {
+			logStoreError(logger, "Error burying job", err)
+		}
+		return
+	}
+	runAt := time.Now().Add(retryBackoff * time.Duration(job.Attempts*job.Attempts))
+	logger.Warn("Job failed, retrying", "error", jobErr, "run_at", runAt)
+	if err := w.store.Retry(ctx, job.ID, w.id, runAt, jobErr.Error()); err != nil {
+		logStoreError(logger, "Error rescheduling job", err)
+	}
+}
@@ -0,0 +1,111 @@
+// Package ranking orders articles by how much a user is likely to care
+// about them, learned from their 👍/👎 feedback.
+package ranking
+
+import (
+	"sort"
+	"strings"
+	"tgbot/internal/entities"
+	"tgbot/internal/filter"
+	"unicode/utf8"
+)
+
+// smoothing pulls the weight of rarely seen features towards zero, so one
+// vote doesn't outweigh a consistent pattern.
+const smoothing = 2.0
+
+const termPrefix = "t:"
+
+// minTermLength skips short words, which are mostly stop words.
+const minTermLength = 4
+
+// Model scores articles by the source, category and title terms of the
+// articles a user voted on. Each feature weighs the average vote it got,
+// damped by smoothing; an article scores the source and category weights
+// plus the mean weight of its known title terms.
+type Model struct {
+	weights map[string]float64
+}
+
+// NewModel trains a model on one user's feedback.
+func NewModel(feedback []entities.Feedback) *Model {
+	sums := make(map[string]float64)
+	counts := make(map[string]float64)
+	for _, f := range feedback {
+		for _, feature := range features(f.Category, &f.Article) {
+			sums[feature] += float64(f.Vote)
+			counts[feature]++
+		}
+	}
+	m := &Model{weights: make(map[string]float64, len(sums))}
+	for feature, sum := range sums {
+		m.weights[feature] = sum / (counts[feature] + smoothing)
+	}
+	return m
+}
+
+// Score rates the article delivered for the category; higher is better and
+// zero is neutral.
+func (m *Model) Score(category string, article *entities.Article) float64 {
+	if m == nil || len(m.weights) == 0 {
+		return 0
+	}
+	var score, termSum float64
+	var terms int
+	for _, feature := range features(category, article) {
+		weight, ok := m.weights[feature]
+		if !ok {
+			continue
+		}
+		if strings.HasPrefix(feature, termPrefix) {
+			termSum += weight
+			terms++
+			continue
+		}
+		score += weight
+	}
+	if terms > 0 {
+		score += termSum / float64(terms)
+	}
+	return score
+}
+
+// Rank orders the articles by score, keeping the given order among equal
+// scores. The slice is not modified.
+func (m *Model) Rank(category string, articles []entities.Article) []entities.Article {
+	ranked := append([]entities.Article(nil), articles...)
+	if m == nil || len(m.weights) == 0 {
+		return ranked
+	}
+	scores := make(map[string]float64, len(ranked))
+	for i := range ranked {
+		scores[ranked[i].URL] = m.Score(category, &ranked[i])
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].URL] > scores[ranked[j].URL]
+	})
+	return ranked
+}
+
+// features lists the features of an article: "s:" source, "c:" category and
+// "t:" title terms, each term once.
+func features(category string, article *entities.Article) []string {
+	var result []string
+	if article.SourceID != "" {
+		result = append(result, "s:"+article.SourceID)
+	} else if article.SourceName != "" {
+		result = append(result, "s:"+article.SourceName)
+	}
+	if category != "" {
+		result = append(result, "c:"+category)
+	}
+	seen := make(map[string]bool)
+	for _, word := range filter.Words(article.Title) {
+		if utf8.RuneCountInString(word) < minTermLength || seen[word] {
+			continue
+		}
+		seen[word] = true
+		result = append(result, termPrefix+word)
+	}
+	return result
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type APIUsageRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPIUsageRepository(pool *pgxpool.Pool) *APIUsageRepository {
+	return &APIUsageRepository{pool: pool}
+}
+
+func (r *APIUsageRepository) IncrementUsage(ctx context.Context, keyID string, day time.Time) (int, error) {
+	var requests int
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO api_usage (key_id, day, requests) VALUES ($1, $2, 1)
+		 ON CONFLICT (key_id, day) DO UPDATE SET requests = api_usage.requests + 1
+		 RETURNING requests`,
+		keyID, day).Scan(&requests)
+	return requests, err
+}
+
+func (r *APIUsageRepository) GetUsage(ctx context.Context, keyID string, day time.Time) (int, error) {
+	var requests int
+	err := r.pool.QueryRow(ctx,
+		"SELECT requests FROM api_usage WHERE key_id = $1 AND day = $2",
+		keyID, day).Scan(&requests)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return requests, err
+}
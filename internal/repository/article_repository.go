@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"tgbot/internal/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ArticleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewArticleRepository(pool *pgxpool.Pool) *ArticleRepository {
+	return &ArticleRepository{pool: pool}
+}
+
+// SaveArticles stores the articles and sets their IDs.
+func (r *ArticleRepository) SaveArticles(ctx context.Context, category string, articles []entities.Article) error {
+	batch := &pgx.Batch{}
+	for i, article := range articles {
+		batch.Queue(
+			`INSERT INTO articles (url, title, description, published_at, category, source_id, source_name,
+			 author, image_url, language)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			 ON CONFLICT (url) DO UPDATE SET title = EXCLUDED.title, description = EXCLUDED.description,
+			 published_at = EXCLUDED.published_at, source_id = EXCLUDED.source_id,
+			 source_name = EXCLUDED.source_name, author = EXCLUDED.author, image_url = EXCLUDED.image_url,
+			 language = EXCLUDED.language, fetched_at = CURRENT_TIMESTAMP
+			 RETURNING id`,
+			article.URL, article.Title, article.Description, article.PublishedAt, category,
+			article.SourceID, article.SourceName, article.Author, article.ImageURL, article.Language).QueryRow(func(row pgx.Row) error {
+			return row.Scan(&articles[i].ID)
+		})
+	}
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+// GetLatestArticles returns the newest stored articles of the category. A
+// non-empty language keeps only articles detected in that language.
+func (r *ArticleRepository) GetLatestArticles(ctx context.Context, category, language string, limit int) ([]entities.Article, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, title, description, url, published_at, source_id, source_name, author, image_url, language FROM articles
+		 WHERE category = $1 AND ($2 = '' OR language = $2) ORDER BY published_at DESC LIMIT $3`,
+		category, language, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []entities.Article
+	for rows.Next() {
+		var article entities.Article
+		if err := rows.Scan(&article.ID, &article.Title, &article.Description, &article.URL, &article.PublishedAt,
+			&article.SourceID, &article.SourceName, &article.Author, &article.ImageURL, &article.Language); err != nil {
+			return nil, err
+		}
+		articles = append(articles, article)
+	}
+	return articles, rows.Err()
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"tgbot/internal/entities"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BookmarkRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBookmarkRepository(pool *pgxpool.Pool) *BookmarkRepository {
+	return &BookmarkRepository{pool: pool}
+}
+
+// AddBookmark saves the stored article for the user. It reports false if it
+// was already saved or the article doesn't exist.
+func (r *BookmarkRepository) AddBookmark(ctx context.Context, userID, articleID int64) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO bookmarks (user_id, article_id)
+		 SELECT $1, id FROM articles WHERE id = $2
+		 ON CONFLICT DO NOTHING`,
+		userID, articleID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *BookmarkRepository) RemoveBookmark(ctx context.Context, userID, articleID int64) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		"DELETE FROM bookmarks WHERE user_id = $1 AND article_id = $2",
+		userID, articleID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetBookmarks returns a page of the user's bookmarks, newest first.
+func (r *BookmarkRepository) GetBookmarks(ctx context.Context, userID int64, limit, offset int) ([]entities.Bookmark, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT a.id, a.title, a.description, a.url, a.published_at, a.source_id, a.source_name, b.saved_at
+		 FROM bookmarks b JOIN articles a ON a.id = b.article_id
+		 WHERE b.user_id = $1 ORDER BY b.saved_at DESC, a.id DESC LIMIT $2 OFFSET $3`,
+		userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bookmarks []entities.Bookmark
+	for rows.Next() {
+		var b entities.Bookmark
+		a := &b.Article
+		if err := rows.Scan(&a.ID, &a.Title, &a.Description, &a.URL, &a.PublishedAt, &a.SourceID, &a.SourceName, &b.SavedAt); err != nil {
+			return nil, err
+		}
+		bookmarks = append(bookmarks, b)
+	}
+	return bookmarks, rows.Err()
+}
+
+func (r *BookmarkRepository) CountBookmarks(ctx context.Context, userID int64) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, "SELECT count(*) FROM bookmarks WHERE user_id = $1", userID).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"tgbot/internal/entities"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BreakingRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBreakingRepository(pool *pgxpool.Pool) *BreakingRepository {
+	return &BreakingRepository{pool: pool}
+}
+
+// GetRecentArticles returns the stored articles of the category first seen
+// since the given time, earliest first.
+func (r *BreakingRepository) GetRecentArticles(ctx context.Context, category string, since time.Time) ([]entities.Article, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, title, description, url, published_at, source_id, source_name FROM articles
+		 WHERE category = $1 AND first_seen_at >= $2 ORDER BY first_seen_at, id`,
+		category, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var articles []entities.Article
+	for rows.Next() {
+		var article entities.Article
+		if err := rows.Scan(&article.ID, &article.Title, &article.Description, &article.URL, &article.PublishedAt,
+			&article.SourceID, &article.SourceName); err != nil {
+			return nil, err
+		}
+		articles = append(articles, article)
+	}
+	return articles, rows.Err()
+}
+
+// RecordScores stores the scores, keeping the peak score of each story.
+func (r *BreakingRepository) RecordScores(ctx context.Context, scores []entities.TrendScore) error {
+	batch := &pgx.Batch{}
+	for _, s := range scores {
+		batch.Queue(
+			`INSERT INTO trend_scores (category, article_id, score) VALUES ($1, $2, $3)
+			 ON CONFLICT (category, article_id) DO UPDATE SET score = GREATEST(trend_scores.score, EXCLUDED.score)`,
+			s.Category, s.ArticleID, s.Score)
+	}
+	return r.pool.SendBatch(ctx, batch).Close()
+}
+
+// GetScoreCounts counts the category's stories first recorded since the
+// given time by peak score.
+func (r *BreakingRepository) GetScoreCounts(ctx context.Context, category string, since time.Time) (map[int]int, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT score, COUNT(*) FROM trend_scores WHERE category = $1 AND recorded_at >= $2 GROUP BY score",
+		category, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[int]int)
+	for rows.Next() {
+		var score, count int
+		if err := rows.Scan(&score, &count); err != nil {
+			return nil, err
+		}
+		counts[score] = count
+	}
+	return counts, rows.Err()
+}
+
+// MarkAlerted remembers the articles of a story sent as breaking news. It
+// reports false if any of them was part of a story sent before; the rest
+// are remembered anyway, so the story isn't sent again as it grows.
+func (r *BreakingRepository) MarkAlerted(ctx context.Context, articleIDs []int64) (bool, error) {
+	var fresh bool
+	err := r.pool.QueryRow(ctx,
+		`WITH known AS (SELECT 1 FROM breaking_articles WHERE article_id = ANY($1) LIMIT 1),
+		      marked AS (
+		          INSERT INTO breaking_articles (article_id) SELECT unnest($1::BIGINT[])
+		          ON CONFLICT DO NOTHING
+		      )
+		 SELECT NOT EXISTS (SELECT 1 FROM known)`,
+		articleIDs).Scan(&fresh)
+	return fresh, err
+}
+
+// GetThreshold returns the category's threshold, or 0 if it uses the
+// default.
+func (r *BreakingRepository) GetThreshold(ctx context.Context, category string) (int, error) {
+	var threshold int
+	err := r.pool.QueryRow(ctx, "SELECT threshold FROM breaking_thresholds WHERE category = $1", category).Scan(&threshold)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	return threshold, err
+}
+
+// SetThreshold sets the category's threshold; 0 restores the default.
+func (r *BreakingRepository) SetThreshold(ctx context.Context, category string, threshold int) error {
+	if threshold == 0 {
+		_, err := r.pool.Exec(ctx, "DELETE FROM breaking_thresholds WHERE category = $1", category)
+		return err
+	}
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO breaking_thresholds (category, threshold) VALUES ($1, $2)
+		 ON CONFLICT (category) DO UPDATE SET threshold = EXCLUDED.threshold`,
+		category, threshold)
+	return err
+}
+
+func (r *BreakingRepository) SetBreakingAlerts(ctx context.Context, userID int64, enabled bool) error {
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO users (id, breaking_alerts) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET breaking_alerts = EXCLUDED.breaking_alerts",
+		userID, enabled)
+	return err
+}
+
+func (r *BreakingRepository) HasBreakingAlerts(ctx context.Context, userID int64) (bool, error) {
+	var enabled bool
+	err := r.pool.QueryRow(ctx, "SELECT breaking_alerts FROM users WHERE id = $1", userID).Scan(&enabled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return enabled, err
+}
+
+// GetBreakingRecipients returns the subscriptions to the category of users
+// who turned on breaking-news alerts and aren't banned or unreachable.
+func (r *BreakingRepository) GetBreakingRecipients(ctx context.Context, category string) ([]entities.Subscription, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT u.id, s.thread_id FROM users u JOIN subscriptions s ON s.user_id = u.id
+		 WHERE s.category = $1 AND u.breaking_alerts AND NOT u.banned AND NOT u.unreachable ORDER BY u.id`,
+		category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []entities.Subscription
+	for rows.Next() {
+		sub := entities.Subscription{Category: category}
+		if err := rows.Scan(&sub.UserID, &sub.ThreadID); err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, sub)
+	}
+	return subscriptions, rows.Err()
+}
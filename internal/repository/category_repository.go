@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"tgbot/internal/entities"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type CategoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCategoryRepository(pool *pgxpool.Pool) *CategoryRepository {
+	return &CategoryRepository{pool: pool}
+}
+
+// GetCategories returns the whole catalogue, disabled categories included,
+// in display order.
+func (r *CategoryRepository) GetCategories(ctx context.Context) ([]entities.Category, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT slug, names, provider, newsapi_category, keywords, feeds, enabled, sort_order, schedule
+		 FROM categories ORDER BY sort_order, slug`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []entities.Category
+	for rows.Next() {
+		var c entities.Category
+		if err := rows.Scan(&c.Slug, &c.Names, &c.Provider, &c.NewsAPICategory, &c.Keywords,
+			&c.Feeds, &c.Enabled, &c.SortOrder, &c.Schedule); err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+	return categories, rows.Err()
+}
+
+// SaveCategory creates the category or replaces its provider mapping. Names,
+// the enabled flag, the sort order and the schedule of an existing category
+// are kept.
+func (r *CategoryRepository) SaveCategory(ctx context.Context, c *entities.Category) error {
+	names := c.Names
+	if names == nil {
+		names = map[string]string{}
+	}
+	feeds := c.Feeds
+	if feeds == nil {
+		feeds = []string{}
+	}
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO categories (slug, names, provider, newsapi_category, keywords, feeds, enabled, sort_order, schedule)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 ON CONFLICT (slug) DO UPDATE SET provider = EXCLUDED.provider,
+		 newsapi_category = EXCLUDED.newsapi_category, keywords = EXCLUDED.keywords, feeds = EXCLUDED.feeds`,
+		c.Slug, names, c.Provider, c.NewsAPICategory, c.Keywords, feeds, c.Enabled, c.SortOrder, c.Schedule)
+	return err
+}
+
+// SeedCategories adds NewsAPI-backed categories for the slugs that are not in
+// the catalogue yet.
+func (r *CategoryRepository) SeedCategories(ctx context.Context, slugs []string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO categories (slug, provider, newsapi_category, sort_order)
+		 SELECT slug, 'newsapi', slug, ord FROM unnest($1::text[]) WITH ORDINALITY AS s(slug, ord)
+		 ON CONFLICT (slug) DO NOTHING`,
+		slugs)
+	return err
+}
+
+func (r *CategoryRepository) SetEnabled(ctx context.Context, slug string, enabled bool) (bool, error) {
+	tag, err := r.pool.Exec(ctx, "UPDATE categories SET enabled = $2 WHERE slug = $1", slug, enabled)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *CategoryRepository) SetName(ctx context.Context, slug, lang, name string) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		"UPDATE categories SET names = names || jsonb_build_object($2::text, $3::text) WHERE slug = $1",
+		slug, lang, name)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *CategoryRepository) SetSortOrder(ctx context.Context, slug string, order int) (bool, error) {
+	tag, err := r.pool.Exec(ctx, "UPDATE categories SET sort_order = $2 WHERE slug = $1", slug, order)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *CategoryRepository) SetSchedule(ctx context.Context, slug, schedule string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, "UPDATE categories SET schedule = $2 WHERE slug = $1", slug, schedule)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
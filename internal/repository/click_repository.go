@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ClickRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewClickRepository(pool *pgxpool.Pool) *ClickRepository {
+	return &ClickRepository{pool: pool}
+}
+
+// RecordClick stores a click of the user on the stored article and returns
+// the article's URL. The click isn't stored if the user has opted out of
+// tracking since the link was sent. It reports false if the article
+// doesn't exist.
+func (r *ClickRepository) RecordClick(ctx context.Context, userID, articleID int64) (string, bool, error) {
+	var url string
+	err := r.pool.QueryRow(ctx,
+		`WITH article AS (SELECT id, url FROM articles WHERE id = $2),
+		      click AS (
+		          INSERT INTO clicks (user_id, article_id)
+		          SELECT $1, id FROM article
+		          WHERE NOT EXISTS (SELECT 1 FROM users WHERE id = $1 AND tracking_opt_out)
+		      )
+		 SELECT url FROM article`,
+		userID, articleID).Scan(&url)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return url, true, nil
+}
+
+func (r *ClickRepository) SetTrackingOptOut(ctx context.Context, userID int64, optOut bool) error {
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO users (id, tracking_opt_out) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET tracking_opt_out = EXCLUDED.tracking_opt_out",
+		userID, optOut)
+	return err
+}
+
+func (r *ClickRepository) IsTrackingOptOut(ctx context.Context, userID int64) (bool, error) {
+	var optOut bool
+	err := r.pool.QueryRow(ctx, "SELECT tracking_opt_out FROM users WHERE id = $1", userID).Scan(&optOut)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return optOut, err
+}
+
+// GetTrackingOptOuts returns the users who opted out of click tracking, of
+// userIDs only unless it is nil.
+func (r *ClickRepository) GetTrackingOptOuts(ctx context.Context, userIDs []int64) (map[int64]bool, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT id FROM users WHERE tracking_opt_out AND ($1::bigint[] IS NULL OR id = ANY($1))", userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	optOuts := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		optOuts[id] = true
+	}
+	return optOuts, rows.Err()
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"tgbot/internal/entities"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DeliveryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDeliveryRepository(pool *pgxpool.Pool) *DeliveryRepository {
+	return &DeliveryRepository{pool: pool}
+}
+
+func (r *DeliveryRepository) RecordDelivery(ctx context.Context, userID int64, article *entities.Article, category string) error {
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO deliveries (user_id, article_url, category) VALUES ($1, $2, $3)",
+		userID, article.URL, category)
+	return err
+}
+
+// GetDeliveries returns the latest deliveries, newest first, optionally only
+// those of one user.
+func (r *DeliveryRepository) GetDeliveries(ctx context.Context, userID int64, limit int) ([]entities.Delivery, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, user_id, article_url, category, sent_at FROM deliveries
+		 WHERE $1 = 0 OR user_id = $1 ORDER BY sent_at DESC, id DESC LIMIT $2`,
+		userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []entities.Delivery
+	for rows.Next() {
+		var d entities.Delivery
+		if err := rows.Scan(&d.ID, &d.UserID, &d.ArticleURL, &d.Category, &d.SentAt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
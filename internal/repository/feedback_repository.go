@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"tgbot/internal/entities"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type FeedbackRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewFeedbackRepository(pool *pgxpool.Pool) *FeedbackRepository {
+	return &FeedbackRepository{pool: pool}
+}
+
+// SaveFeedback records the user's vote on a stored article, replacing an
+// earlier vote. It reports false if the article doesn't exist.
+func (r *FeedbackRepository) SaveFeedback(ctx context.Context, userID, articleID int64, vote int) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO article_feedback (user_id, article_id, vote)
+		 SELECT $1, id, $3 FROM articles WHERE id = $2
+		 ON CONFLICT (user_id, article_id) DO UPDATE SET vote = EXCLUDED.vote, created_at = CURRENT_TIMESTAMP`,
+		userID, articleID, vote)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetRecentFeedback returns up to perUser of the latest votes of every user
+// with the voted articles.
+func (r *FeedbackRepository) GetRecentFeedback(ctx context.Context, perUser int) ([]entities.Feedback, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT f.user_id, f.vote, a.category, a.id, a.title, a.source_id, a.source_name
+		 FROM (
+		     SELECT user_id, article_id, vote,
+		            row_number() OVER (PARTITION BY user_id ORDER BY created_at DESC) AS n
+		     FROM article_feedback
+		 ) f JOIN articles a ON a.id = f.article_id
+		 WHERE f.n <= $1`,
+		perUser)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var feedback []entities.Feedback
+	for rows.Next() {
+		var f entities.Feedback
+		if err := rows.Scan(&f.UserID, &f.Vote, &f.Category, &f.Article.ID, &f.Article.Title,
+			&f.Article.SourceID, &f.Article.SourceName); err != nil {
+			return nil, err
+		}
+		feedback = append(feedback, f)
+	}
+	return feedback, rows.Err()
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"tgbot/internal/entities"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type FilterRepository interface {
+	SaveFilter(ctx context.Context, rule *entities.FilterRule) error
+	GetFiltersByUser(ctx context.Context, userID int64) ([]entities.FilterRule, error)
+	GetAllFilters(ctx context.Context) ([]entities.FilterRule, error)
+	DeleteFilter(ctx context.Context, userID int64, id int) (bool, error)
+}
+
+type filterRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewFilterRepository(pool *pgxpool.Pool) FilterRepository {
+	return &filterRepository{pool: pool}
+}
+
+func (r *filterRepository) SaveFilter(ctx context.Context, rule *entities.FilterRule) error {
+	return r.pool.QueryRow(ctx,
+		"INSERT INTO filter_rules (user_id, category, kind, expression) VALUES ($1, $2, $3, $4) RETURNING id",
+		rule.UserID, rule.Category, rule.Kind, rule.Expression).Scan(&rule.ID)
+}
+
+func (r *filterRepository) GetFiltersByUser(ctx context.Context, userID int64) ([]entities.FilterRule, error) {
+	return r.query(ctx,
+		"SELECT id, user_id, category, kind, expression FROM filter_rules WHERE user_id = $1 ORDER BY id",
+		userID)
+}
+
+func (r *filterRepository) GetAllFilters(ctx context.Context) ([]entities.FilterRule, error) {
+	return r.query(ctx, "SELECT id, user_id, category, kind, expression FROM filter_rules ORDER BY id")
+}
+
+func (r *filterRepository) DeleteFilter(ctx context.Context, userID int64, id int) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		"DELETE FROM filter_rules WHERE id = $1 AND user_id = $2",
+		id, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *filterRepository) query(ctx context.Context, sql string, args ...any) ([]entities.FilterRule, error) {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []entities.FilterRule
+	for rows.Next() {
+		var rule entities.FilterRule
+		if err := rows.Scan(&rule.ID, &rule.UserID, &rule.Category, &rule.Kind, &rule.Expression); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"tgbot/internal/entities"
+	"tgbot/internal/queue"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type JobRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewJobRepository(pool *pgxpool.Pool) *JobRepository {
+	return &JobRepository{pool: pool}
+}
+
+// querier runs queries on the pool or in a transaction.
+type querier interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// Enqueue adds a job to run at job.RunAt, or now if it is zero. It reports
+// false if a job with the same dedupe key is already queued.
+func (r *JobRepository) Enqueue(ctx context.Context, job *entities.Job) (bool, error) {
+	return enqueue(ctx, r.pool, job)
+}
+
+// EnqueueClaimed claims the articles like ClaimArticles and queues the jobs
+// build returns for the claimed URLs, in one transaction: if the jobs can't
+// be queued, the articles stay unsent.
+func (r *JobRepository) EnqueueClaimed(ctx context.Context, articles []entities.Article, category string, build func(claimed []string) ([]*entities.Job, error)) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		claimed, err := claimArticles(ctx, tx, articles, category)
+		if err != nil {
+			return err
+		}
+		jobs, err := build(claimed)
+		if err != nil {
+			return err
+		}
+		for _, job := range jobs {
+			if _, err := enqueue(ctx, tx, job); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func enqueue(ctx context.Context, q querier, job *entities.Job) (bool, error) {
+	runAt := job.RunAt
+	if runAt.IsZero() {
+		runAt = time.Now()
+	}
+	var dedupeKey *string
+	if job.DedupeKey != "" {
+		dedupeKey = &job.DedupeKey
+	}
+	tag, err := q.Exec(ctx,
+		`INSERT INTO jobs (kind, payload, dedupe_key, run_at) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (dedupe_key) WHERE dedupe_key IS NOT NULL AND dead_at IS NULL DO NOTHING`,
+		job.Kind, []byte(job.Payload), dedupeKey, runAt)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// Claim locks the next due job for workerID until the lease runs out. It
+// returns nil if no job is due. Concurrent workers skip each other's rows.
+func (r *JobRepository) Claim(ctx context.Context, workerID string, lease time.Duration) (*entities.Job, error) {
+	var job entities.Job
+	var dedupeKey *string
+	err := r.pool.QueryRow(ctx,
+		`UPDATE jobs SET locked_by = $1, locked_until = now() + $2::interval, attempts = attempts + 1
+		 WHERE id = (
+		     SELECT id FROM jobs
+		     WHERE dead_at IS NULL AND run_at <= now() AND (locked_until IS NULL OR locked_until < now())
+		     ORDER BY run_at, id
+		     FOR UPDATE SKIP LOCKED
+		     LIMIT 1
+		 )
+		 RETURNING id, kind, payload, dedupe_key, run_at, attempts`,
+		workerID, lease).Scan(&job.ID, &job.Kind, &job.Payload, &dedupeKey, &job.RunAt, &job.Attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if dedupeKey != nil {
+		job.DedupeKey = *dedupeKey
+	}
+	return &job, nil
+}
+
+// Extend renews the lease of a job workerID still holds. It returns
+// queue.ErrLeaseLost if another worker claimed the job after the lease ran
+// out.
+func (r *JobRepository) Extend(ctx context.Context, id int64, workerID string, lease time.Duration) error {
+	tag, err := r.pool.Exec(ctx,
+		"UPDATE jobs SET locked_until = now() + $3::interval WHERE id = $1 AND locked_by = $2",
+		id, workerID, lease)
+	return held(tag, err)
+}
+
+// Complete removes a finished job.
+func (r *JobRepository) Complete(ctx context.Context, id int64, workerID string) error {
+	tag, err := r.pool.Exec(ctx, "DELETE FROM jobs WHERE id = $1 AND locked_by = $2", id, workerID)
+	return held(tag, err)
+}
+
+// Retry releases a failed job to run again at runAt.
+func (r *JobRepository) Retry(ctx context.Context, id int64, workerID string, runAt time.Time, lastError string) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE jobs SET run_at = $3, last_error = $4, locked_by = NULL, locked_until = NULL
+		 WHERE id = $1 AND locked_by = $2`,
+		id, workerID, runAt, lastError)
+	return held(tag, err)
+}
+
+// Bury keeps a job that ran out of attempts for inspection without running
+// it again.
+func (r *JobRepository) Bury(ctx context.Context, id int64, workerID string, lastError string) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE jobs SET dead_at = now(), last_error = $3, locked_by = NULL, locked_until = NULL
+		 WHERE id = $1 AND locked_by = $2`,
+		id, workerID, lastError)
+	return held(tag, err)
+}
+
+// held turns an update of a job that is no longer locked by the worker into
+// queue.ErrLeaseLost.
+func held(tag pgconn.CommandTag, err error) error {
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return queue.ErrLeaseLost
+	}
+	return nil
+}
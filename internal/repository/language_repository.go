@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LanguageRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLanguageRepository(pool *pgxpool.Pool) *LanguageRepository {
+	return &LanguageRepository{pool: pool}
+}
+
+// SetLanguages replaces the languages the user reads. No languages means
+// all of them.
+func (r *LanguageRepository) SetLanguages(ctx context.Context, userID int64, languages []string) error {
+	if languages == nil {
+		languages = []string{}
+	}
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO users (id, languages) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET languages = EXCLUDED.languages",
+		userID, languages)
+	return err
+}
+
+func (r *LanguageRepository) GetLanguages(ctx context.Context, userID int64) ([]string, error) {
+	var languages []string
+	err := r.pool.QueryRow(ctx, "SELECT languages FROM users WHERE id = $1", userID).Scan(&languages)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return languages, err
+}
+
+// GetAllLanguages returns the languages of the users who restricted them.
+func (r *LanguageRepository) GetAllLanguages(ctx context.Context) (map[int64][]string, error) {
+	rows, err := r.pool.Query(ctx, "SELECT id, languages FROM users WHERE cardinality(languages) > 0")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[int64][]string)
+	for rows.Next() {
+		var (
+			userID    int64
+			languages []string
+		)
+		if err := rows.Scan(&userID, &languages); err != nil {
+			return nil, err
+		}
+		result[userID] = languages
+	}
+	return result, rows.Err()
+}
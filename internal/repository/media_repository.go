@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"tgbot/internal/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MediaRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewMediaRepository(pool *pgxpool.Pool) *MediaRepository {
+	return &MediaRepository{pool: pool}
+}
+
+func (r *MediaRepository) SetMediaMode(ctx context.Context, userID int64, mode string) error {
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO users (id, media) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET media = EXCLUDED.media",
+		userID, mode)
+	return err
+}
+
+func (r *MediaRepository) GetMediaMode(ctx context.Context, userID int64) (string, error) {
+	var mode string
+	err := r.pool.QueryRow(ctx, "SELECT media FROM users WHERE id = $1", userID).Scan(&mode)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return entities.MediaPreview, nil
+	}
+	return mode, err
+}
+
+// GetMediaModes returns the media modes of the users who changed theirs
+// from the default, of userIDs only unless it is nil.
+func (r *MediaRepository) GetMediaModes(ctx context.Context, userIDs []int64) (map[int64]string, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT id, media FROM users WHERE media <> $1 AND ($2::bigint[] IS NULL OR id = ANY($2))",
+		entities.MediaPreview, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	modes := make(map[int64]string)
+	for rows.Next() {
+		var (
+			userID int64
+			mode   string
+		)
+		if err := rows.Scan(&userID, &mode); err != nil {
+			return nil, err
+		}
+		modes[userID] = mode
+	}
+	return modes, rows.Err()
+}
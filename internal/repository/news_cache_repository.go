@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"tgbot/internal/entities"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// NewsCacheRepository is a news cache shared by every bot replica.
+type NewsCacheRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewNewsCacheRepository(pool *pgxpool.Pool) *NewsCacheRepository {
+	return &NewsCacheRepository{pool: pool}
+}
+
+func (r *NewsCacheRepository) Get(ctx context.Context, key string) ([]entities.Article, bool, error) {
+	var payload []byte
+	err := r.pool.QueryRow(ctx,
+		"SELECT payload FROM news_cache WHERE key = $1 AND expires_at > CURRENT_TIMESTAMP",
+		key).Scan(&payload)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	var articles []entities.Article
+	if err := json.Unmarshal(payload, &articles); err != nil {
+		return nil, false, err
+	}
+	return articles, true, nil
+}
+
+func (r *NewsCacheRepository) Set(ctx context.Context, key string, articles []entities.Article, ttl time.Duration) error {
+	payload, err := json.Marshal(articles)
+	if err != nil {
+		return err
+	}
+	_, err = r.pool.Exec(ctx,
+		`INSERT INTO news_cache (key, payload, expires_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (key) DO UPDATE SET payload = EXCLUDED.payload, expires_at = EXCLUDED.expires_at`,
+		key, payload, time.Now().Add(ttl))
+	return err
+}
@@ -3,8 +3,12 @@ package repository
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"tgbot/db"
 	"tgbot/internal/config"
+	"tgbot/internal/metrics"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -12,7 +16,7 @@ type PostgresRepository struct {
 	pool *pgxpool.Pool
 }
 
-func NewPostgresRepository(ctx context.Context, maxConns int, cfg config.StorageConfig) (*PostgresRepository, error) {
+func NewPostgresRepository(ctx context.Context, cfg config.StorageConfig, logger *slog.Logger) (*PostgresRepository, error) {
 	dsn := fmt.Sprintf("postgres://%s:%s@%s:%d/%s",
 		cfg.Username, cfg.Password, cfg.Host, cfg.Port, cfg.Database)
 
@@ -21,7 +25,8 @@ func NewPostgresRepository(ctx context.Context, maxConns int, cfg config.Storage
 		return nil, fmt.Errorf("failed to parse db config: %w", err)
 	}
 
-	poolConfig.MaxConns = int32(maxConns)
+	poolConfig.MaxConns = int32(cfg.MaxConns)
+	poolConfig.ConnConfig.Tracer = newQueryTracer(logger)
 
 	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
 	if err != nil {
@@ -36,6 +41,46 @@ func NewPostgresRepository(ctx context.Context, maxConns int, cfg config.Storage
 	return &PostgresRepository{pool: pool}, nil
 }
 
+// migrationLockID keys the advisory lock that keeps instances starting at the
+// same time from applying the schema concurrently.
+const migrationLockID = 7341020
+
+// Migrate applies the schema to the database. Postgres runs init.sql only
+// when the volume is first created, so existing databases get new tables
+// and columns from here.
+func (r *PostgresRepository) Migrate(ctx context.Context) error {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", migrationLockID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, db.Schema)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply schema: %w", err)
+	}
+	return nil
+}
+
 func (r *PostgresRepository) Conn() *pgxpool.Pool {
 	return r.pool
 }
+
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	return r.pool.Ping(ctx)
+}
+
+// PoolStats reports the connection pool statistics as metrics samples.
+func (r *PostgresRepository) PoolStats() []metrics.Sample {
+	stat := r.pool.Stat()
+	return []metrics.Sample{
+		{Name: "tgbot_db_pool_acquired_conns", Help: "Connections currently in use.", Type: "gauge", Value: float64(stat.AcquiredConns())},
+		{Name: "tgbot_db_pool_idle_conns", Help: "Idle connections.", Type: "gauge", Value: float64(stat.IdleConns())},
+		{Name: "tgbot_db_pool_total_conns", Help: "Open connections.", Type: "gauge", Value: float64(stat.TotalConns())},
+		{Name: "tgbot_db_pool_max_conns", Help: "Maximum pool size.", Type: "gauge", Value: float64(stat.MaxConns())},
+		{Name: "tgbot_db_pool_acquires_total", Help: "Successful connection acquires.", Type: "counter", Value: float64(stat.AcquireCount())},
+		{Name: "tgbot_db_pool_empty_acquires_total", Help: "Acquires that had to wait for a connection.", Type: "counter", Value: float64(stat.EmptyAcquireCount())},
+		{Name: "tgbot_db_pool_canceled_acquires_total", Help: "Acquires canceled by their context.", Type: "counter", Value: float64(stat.CanceledAcquireCount())},
+		{Name: "tgbot_db_pool_acquire_duration_seconds_total", Help: "Total time spent acquiring connections.", Type: "counter", Value: stat.AcquireDuration().Seconds()},
+	}
+}
@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"tgbot/internal/logging"
+
+	"github.com/jackc/pgx/v5/tracelog"
+)
+
+// newQueryTracer logs every query through slog. Successful queries are
+// logged at debug level; query arguments are never logged since they carry
+// user data.
+func newQueryTracer(logger *slog.Logger) *tracelog.TraceLog {
+	return &tracelog.TraceLog{
+		Logger: tracelog.LoggerFunc(func(ctx context.Context, level tracelog.LogLevel, msg string, data map[string]any) {
+			delete(data, "args")
+			attrs := make([]slog.Attr, 0, len(data))
+			for key, value := range data {
+				attrs = append(attrs, slog.Any(key, value))
+			}
+			logging.FromContext(ctx, logger).LogAttrs(ctx, slogLevel(level), msg, attrs...)
+		}),
+		LogLevel: tracelog.LogLevelInfo,
+	}
+}
+
+func slogLevel(level tracelog.LogLevel) slog.Level {
+	switch level {
+	case tracelog.LogLevelError:
+		return slog.LevelError
+	case tracelog.LogLevelWarn:
+		return slog.LevelWarn
+	}
+	// pgx logs every query at info level, which is debug noise for the bot.
+	return slog.LevelDebug
+}
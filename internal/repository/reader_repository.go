@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"tgbot/internal/entities"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReaderRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewReaderRepository(pool *pgxpool.Pool) *ReaderRepository {
+	return &ReaderRepository{pool: pool}
+}
+
+// GetArticle returns the stored article together with its extracted text.
+// The article is nil if it doesn't exist, and extracted reports false if
+// the text wasn't extracted yet.
+func (r *ReaderRepository) GetArticle(ctx context.Context, articleID int64) (*entities.Article, string, bool, error) {
+	var article entities.Article
+	var content *string
+	err := r.pool.QueryRow(ctx,
+		`SELECT id, title, description, url, published_at, source_id, source_name, content
+		 FROM articles WHERE id = $1`,
+		articleID).Scan(&article.ID, &article.Title, &article.Description, &article.URL, &article.PublishedAt,
+		&article.SourceID, &article.SourceName, &content)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", false, nil
+	}
+	if err != nil {
+		return nil, "", false, err
+	}
+	if content == nil {
+		return &article, "", false, nil
+	}
+	return &article, *content, true, nil
+}
+
+func (r *ReaderRepository) SaveContent(ctx context.Context, articleID int64, content string) error {
+	_, err := r.pool.Exec(ctx, "UPDATE articles SET content = $2 WHERE id = $1", articleID, content)
+	return err
+}
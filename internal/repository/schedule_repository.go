@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ScheduleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewScheduleRepository(pool *pgxpool.Pool) *ScheduleRepository {
+	return &ScheduleRepository{pool: pool}
+}
+
+func (r *ScheduleRepository) GetLastRuns(ctx context.Context) (map[string]time.Time, error) {
+	rows, err := r.pool.Query(ctx, "SELECT job, last_run FROM schedule_runs")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lastRuns := make(map[string]time.Time)
+	for rows.Next() {
+		var job string
+		var lastRun time.Time
+		if err := rows.Scan(&job, &lastRun); err != nil {
+			return nil, err
+		}
+		lastRuns[job] = lastRun
+	}
+	return lastRuns, rows.Err()
+}
+
+func (r *ScheduleRepository) SetLastRun(ctx context.Context, job string, at time.Time) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO schedule_runs (job, last_run) VALUES ($1, $2)
+		 ON CONFLICT (job) DO UPDATE SET last_run = EXCLUDED.last_run`,
+		job, at)
+	return err
+}
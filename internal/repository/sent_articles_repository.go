@@ -15,13 +15,6 @@ func NewSentArticlesRepository(pool *pgxpool.Pool) *SentArticlesRepository {
 	return &SentArticlesRepository{pool: pool}
 }
 
-func (r *SentArticlesRepository) SaveSentArticle(ctx context.Context, article *entities.Article, category string) error {
-	_, err := r.pool.Exec(ctx,
-		"INSERT INTO sent_articles (url, category) VALUES ($1, $2) ON CONFLICT (url) DO NOTHING",
-		article.URL, category)
-	return err
-}
-
 func (r *SentArticlesRepository) IsArticleSent(ctx context.Context, url string) (bool, error) {
 	var exists bool
 	err := r.pool.QueryRow(ctx,
@@ -29,3 +22,34 @@ func (r *SentArticlesRepository) IsArticleSent(ctx context.Context, url string)
 		url).Scan(&exists)
 	return exists, err
 }
+
+// ClaimArticles marks the articles as sent and returns the URLs of those
+// that weren't sent before. Of concurrent claims of a URL only one gets it.
+func (r *SentArticlesRepository) ClaimArticles(ctx context.Context, articles []entities.Article, category string) ([]string, error) {
+	return claimArticles(ctx, r.pool, articles, category)
+}
+
+func claimArticles(ctx context.Context, q querier, articles []entities.Article, category string) ([]string, error) {
+	urls := make([]string, len(articles))
+	for i := range articles {
+		urls[i] = articles[i].URL
+	}
+	rows, err := q.Query(ctx,
+		`INSERT INTO sent_articles (url, category) SELECT unnest($1::text[]), $2
+		 ON CONFLICT (url) DO NOTHING RETURNING url`,
+		urls, category)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var claimed []string
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, err
+		}
+		claimed = append(claimed, url)
+	}
+	return claimed, rows.Err()
+}
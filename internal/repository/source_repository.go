@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"tgbot/internal/entities"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SourceRepository interface {
+	ReplaceSources(ctx context.Context, sources []entities.Source) error
+	GetSources(ctx context.Context) ([]entities.Source, time.Time, error)
+	FollowSource(ctx context.Context, follow *entities.SourceFollow) error
+	GetFollowsByUser(ctx context.Context, userID int64) ([]string, error)
+	GetAllFollows(ctx context.Context) ([]entities.SourceFollow, error)
+	MuteSource(ctx context.Context, userID int64, source string) error
+	GetAllMutes(ctx context.Context) (map[int64][]string, error)
+}
+
+type sourceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSourceRepository(pool *pgxpool.Pool) SourceRepository {
+	return &sourceRepository{pool: pool}
+}
+
+// ReplaceSources swaps the cached catalogue for a freshly fetched one.
+func (r *sourceRepository) ReplaceSources(ctx context.Context, sources []entities.Source) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "DELETE FROM sources"); err != nil {
+			return err
+		}
+		for _, s := range sources {
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO sources (id, name, description, url, category, language, country)
+				 VALUES ($1, $2, $3, $4, $5, $6, $7) ON CONFLICT (id) DO NOTHING`,
+				s.ID, s.Name, s.Description, s.URL, s.Category, s.Language, s.Country); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetSources returns the cached catalogue and when it was fetched. The time
+// is zero if the catalogue is empty.
+func (r *sourceRepository) GetSources(ctx context.Context) ([]entities.Source, time.Time, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT id, name, description, url, category, language, country, fetched_at FROM sources ORDER BY name")
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	defer rows.Close()
+
+	var (
+		sources   []entities.Source
+		fetchedAt time.Time
+	)
+	for rows.Next() {
+		var (
+			s  entities.Source
+			at time.Time
+		)
+		if err := rows.Scan(&s.ID, &s.Name, &s.Description, &s.URL, &s.Category, &s.Language, &s.Country, &at); err != nil {
+			return nil, time.Time{}, err
+		}
+		if fetchedAt.IsZero() || at.Before(fetchedAt) {
+			fetchedAt = at
+		}
+		sources = append(sources, s)
+	}
+	return sources, fetchedAt, rows.Err()
+}
+
+// FollowSource stores the follow; following a source again moves it to the
+// new forum topic.
+func (r *sourceRepository) FollowSource(ctx context.Context, follow *entities.SourceFollow) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO source_follows (user_id, source_id, thread_id) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, source_id) DO UPDATE SET thread_id = EXCLUDED.thread_id`,
+		follow.UserID, follow.SourceID, follow.ThreadID)
+	return err
+}
+
+func (r *sourceRepository) GetFollowsByUser(ctx context.Context, userID int64) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT source_id FROM source_follows WHERE user_id = $1 ORDER BY source_id",
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sourceIDs []string
+	for rows.Next() {
+		var sourceID string
+		if err := rows.Scan(&sourceID); err != nil {
+			return nil, err
+		}
+		sourceIDs = append(sourceIDs, sourceID)
+	}
+	return sourceIDs, rows.Err()
+}
+
+func (r *sourceRepository) GetAllFollows(ctx context.Context) ([]entities.SourceFollow, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT f.user_id, f.source_id, f.thread_id FROM source_follows f
+		 JOIN users u ON u.id = f.user_id WHERE NOT u.banned AND NOT u.unreachable`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var follows []entities.SourceFollow
+	for rows.Next() {
+		var follow entities.SourceFollow
+		if err := rows.Scan(&follow.UserID, &follow.SourceID, &follow.ThreadID); err != nil {
+			return nil, err
+		}
+		follows = append(follows, follow)
+	}
+	return follows, rows.Err()
+}
+
+func (r *sourceRepository) MuteSource(ctx context.Context, userID int64, source string) error {
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO source_mutes (user_id, source) VALUES ($1, $2) ON CONFLICT DO NOTHING",
+		userID, source)
+	return err
+}
+
+func (r *sourceRepository) GetAllMutes(ctx context.Context) (map[int64][]string, error) {
+	rows, err := r.pool.Query(ctx, "SELECT user_id, source FROM source_mutes")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mutes := make(map[int64][]string)
+	for rows.Next() {
+		var (
+			userID int64
+			source string
+		)
+		if err := rows.Scan(&userID, &source); err != nil {
+			return nil, err
+		}
+		mutes[userID] = append(mutes[userID], source)
+	}
+	return mutes, rows.Err()
+}
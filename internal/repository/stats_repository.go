@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"tgbot/internal/entities"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// clickSourcesLimit caps the sources listed in the click-through report.
+const clickSourcesLimit = 10
+
+// trackedDeliveries lists last week's deliveries of stored articles to users
+// who haven't opted out of click tracking, and whether each was clicked.
+const trackedDeliveries = `
+	WITH tracked AS (
+		SELECT d.category, COALESCE(NULLIF(a.source_name, ''), NULLIF(a.source_id, ''), '?') AS source,
+		       EXISTS (SELECT 1 FROM clicks c WHERE c.user_id = d.user_id AND c.article_id = a.id) AS clicked
+		FROM deliveries d
+		JOIN articles a ON a.url = d.article_url
+		JOIN users u ON u.id = d.user_id
+		WHERE d.sent_at > CURRENT_TIMESTAMP - INTERVAL '7 days' AND NOT u.tracking_opt_out
+	)`
+
+type StatsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewStatsRepository(pool *pgxpool.Pool) *StatsRepository {
+	return &StatsRepository{pool: pool}
+}
+
+// GetStats counts users, users active in the last 24 hours, subscriptions
+// per category and deliveries since midnight UTC, and reports the last
+// week's click-through per category and per source.
+func (r *StatsRepository) GetStats(ctx context.Context) (*entities.Stats, error) {
+	stats := &entities.Stats{SubscriptionsByCategory: make(map[string]int)}
+
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*),
+		        COUNT(*) FILTER (WHERE last_active_at > CURRENT_TIMESTAMP - INTERVAL '24 hours'),
+		        COUNT(*) FILTER (WHERE banned)
+		 FROM users`).Scan(&stats.Users, &stats.ActiveUsers, &stats.BannedUsers)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, "SELECT category, COUNT(*) FROM subscriptions GROUP BY category")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			category string
+			count    int
+		)
+		if err := rows.Scan(&category, &count); err != nil {
+			return nil, err
+		}
+		stats.SubscriptionsByCategory[category] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	err = r.pool.QueryRow(ctx,
+		"SELECT COUNT(*) FROM deliveries WHERE sent_at >= date_trunc('day', CURRENT_TIMESTAMP AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'",
+	).Scan(&stats.DeliveriesToday)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.ClicksByCategory, err = r.clickThrough(ctx,
+		trackedDeliveries+` SELECT category, COUNT(*), COUNT(*) FILTER (WHERE clicked)
+		 FROM tracked GROUP BY category ORDER BY COUNT(*) DESC, category`)
+	if err != nil {
+		return nil, err
+	}
+	stats.ClicksBySource, err = r.clickThrough(ctx,
+		trackedDeliveries+` SELECT source, COUNT(*), COUNT(*) FILTER (WHERE clicked)
+		 FROM tracked GROUP BY source ORDER BY COUNT(*) DESC, source LIMIT $1`,
+		clickSourcesLimit)
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (r *StatsRepository) clickThrough(ctx context.Context, query string, args ...any) ([]entities.ClickThrough, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []entities.ClickThrough
+	for rows.Next() {
+		var c entities.ClickThrough
+		if err := rows.Scan(&c.Name, &c.Deliveries, &c.Clicks); err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
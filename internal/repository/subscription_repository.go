@@ -11,6 +11,7 @@ type SubscriptionRepository interface {
 	SaveSubscription(ctx context.Context, subscription *entities.Subscription) error
 	GetSubscriptionsByUser(ctx context.Context, userID int64) ([]entities.Subscription, error)
 	GetAllSubscriptions(ctx context.Context) ([]entities.Subscription, error)
+	DeleteSubscription(ctx context.Context, userID int64, category string) (bool, error)
 }
 
 type subscriptionRepository struct {
@@ -23,14 +24,16 @@ func NewSubscriptionRepository(pool *pgxpool.Pool) SubscriptionRepository {
 
 func (r *subscriptionRepository) SaveSubscription(ctx context.Context, subscription *entities.Subscription) error {
 	_, err := r.pool.Exec(ctx,
-		"INSERT INTO subscriptions (user_id, category) VALUES ($1, $2) ON CONFLICT (user_id, category) DO NOTHING",
-		subscription.UserID, subscription.Category)
+		`INSERT INTO subscriptions (user_id, category, country, language, thread_id) VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (user_id, category) DO UPDATE
+		 SET country = EXCLUDED.country, language = EXCLUDED.language, thread_id = EXCLUDED.thread_id`,
+		subscription.UserID, subscription.Category, subscription.Country, subscription.Language, subscription.ThreadID)
 	return err
 }
 
 func (r *subscriptionRepository) GetSubscriptionsByUser(ctx context.Context, userID int64) ([]entities.Subscription, error) {
 	rows, err := r.pool.Query(ctx,
-		"SELECT user_id, category FROM subscriptions WHERE user_id = $1",
+		"SELECT user_id, category, country, language, thread_id FROM subscriptions WHERE user_id = $1",
 		userID)
 	if err != nil {
 		return nil, err
@@ -40,7 +43,7 @@ func (r *subscriptionRepository) GetSubscriptionsByUser(ctx context.Context, use
 	var subscriptions []entities.Subscription
 	for rows.Next() {
 		var sub entities.Subscription
-		if err := rows.Scan(&sub.UserID, &sub.Category); err != nil {
+		if err := rows.Scan(&sub.UserID, &sub.Category, &sub.Country, &sub.Language, &sub.ThreadID); err != nil {
 			return nil, err
 		}
 		subscriptions = append(subscriptions, sub)
@@ -49,7 +52,8 @@ func (r *subscriptionRepository) GetSubscriptionsByUser(ctx context.Context, use
 }
 
 func (r *subscriptionRepository) GetAllSubscriptions(ctx context.Context) ([]entities.Subscription, error) {
-	rows, err := r.pool.Query(ctx, "SELECT user_id, category FROM subscriptions")
+	rows, err := r.pool.Query(ctx, `SELECT s.user_id, s.category, s.country, s.language, s.thread_id FROM subscriptions s
+		 JOIN users u ON u.id = s.user_id WHERE NOT u.banned AND NOT u.unreachable`)
 	if err != nil {
 		return nil, err
 	}
@@ -58,10 +62,20 @@ func (r *subscriptionRepository) GetAllSubscriptions(ctx context.Context) ([]ent
 	var subscriptions []entities.Subscription
 	for rows.Next() {
 		var sub entities.Subscription
-		if err := rows.Scan(&sub.UserID, &sub.Category); err != nil {
+		if err := rows.Scan(&sub.UserID, &sub.Category, &sub.Country, &sub.Language, &sub.ThreadID); err != nil {
 			return nil, err
 		}
 		subscriptions = append(subscriptions, sub)
 	}
 	return subscriptions, nil
 }
+
+func (r *subscriptionRepository) DeleteSubscription(ctx context.Context, userID int64, category string) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		"DELETE FROM subscriptions WHERE user_id = $1 AND category = $2",
+		userID, category)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
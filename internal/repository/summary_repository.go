@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type SummaryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSummaryRepository(pool *pgxpool.Pool) *SummaryRepository {
+	return &SummaryRepository{pool: pool}
+}
+
+// GetSummary returns the cached summary of the stored article. It reports
+// false if none was made yet.
+func (r *SummaryRepository) GetSummary(ctx context.Context, articleID int64) (string, bool, error) {
+	var summary *string
+	err := r.pool.QueryRow(ctx, "SELECT summary FROM articles WHERE id = $1", articleID).Scan(&summary)
+	if errors.Is(err, pgx.ErrNoRows) || summary == nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return *summary, true, nil
+}
+
+func (r *SummaryRepository) SaveSummary(ctx context.Context, articleID int64, summary string) error {
+	_, err := r.pool.Exec(ctx, "UPDATE articles SET summary = $2 WHERE id = $1", articleID, summary)
+	return err
+}
+
+func (r *SummaryRepository) SetSummaries(ctx context.Context, userID int64, enabled bool) error {
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO users (id, summaries) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET summaries = EXCLUDED.summaries",
+		userID, enabled)
+	return err
+}
+
+func (r *SummaryRepository) HasSummaries(ctx context.Context, userID int64) (bool, error) {
+	var enabled bool
+	err := r.pool.QueryRow(ctx, "SELECT summaries FROM users WHERE id = $1", userID).Scan(&enabled)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	return enabled, err
+}
+
+// GetSummaryUsers returns the users who turned summaries on, of userIDs
+// only unless it is nil.
+func (r *SummaryRepository) GetSummaryUsers(ctx context.Context, userIDs []int64) (map[int64]bool, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT id FROM users WHERE summaries AND ($1::bigint[] IS NULL OR id = ANY($1))", userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := make(map[int64]bool)
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		users[id] = true
+	}
+	return users, rows.Err()
+}
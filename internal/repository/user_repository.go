@@ -2,13 +2,23 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"tgbot/internal/entities"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 type UserRepository interface {
 	SaveUser(ctx context.Context, user *entities.User) error
+	TouchUser(ctx context.Context, userID int64) (*entities.User, error)
+	SetRole(ctx context.Context, userID int64, role string) error
+	DemoteAdmins(ctx context.Context, keep []int64) error
+	SetBanned(ctx context.Context, userID int64, banned bool) error
+	SetUnreachable(ctx context.Context, chatID int64) error
+	MigrateChat(ctx context.Context, fromID, toID int64) error
+	GetActiveUserIDs(ctx context.Context) ([]int64, error)
+	ListUsers(ctx context.Context, search string, limit, offset int) ([]entities.User, error)
 }
 
 type userRepository struct {
@@ -19,9 +29,136 @@ func NewUserRepository(pool *pgxpool.Pool) UserRepository {
 	return &userRepository{pool: pool}
 }
 
+// SaveUser stores the user, and its chat type if it is set. A chat that
+// changes its settings can be reached again.
 func (r *userRepository) SaveUser(ctx context.Context, user *entities.User) error {
 	_, err := r.pool.Exec(ctx,
-		"INSERT INTO users (id) VALUES ($1) ON CONFLICT (id) DO NOTHING",
-		user.ID)
+		`INSERT INTO users (id, chat_type) VALUES ($1, COALESCE(NULLIF($2, ''), 'private'))
+		 ON CONFLICT (id) DO UPDATE SET chat_type = COALESCE(NULLIF($2, ''), users.chat_type), unreachable = FALSE`,
+		user.ID, user.ChatType)
 	return err
 }
+
+// TouchUser records that the user has just interacted with the bot, so it
+// can be reached again, and returns the stored user.
+func (r *userRepository) TouchUser(ctx context.Context, userID int64) (*entities.User, error) {
+	user := &entities.User{ID: userID}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO users (id, last_active_at) VALUES ($1, CURRENT_TIMESTAMP)
+		 ON CONFLICT (id) DO UPDATE SET last_active_at = CURRENT_TIMESTAMP, unreachable = FALSE
+		 RETURNING role, banned, last_active_at`,
+		userID).Scan(&user.Role, &user.Banned, &user.LastActiveAt)
+	return user, err
+}
+
+func (r *userRepository) SetRole(ctx context.Context, userID int64, role string) error {
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO users (id, role) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET role = EXCLUDED.role",
+		userID, role)
+	return err
+}
+
+// DemoteAdmins takes the admin role from every user not in keep.
+func (r *userRepository) DemoteAdmins(ctx context.Context, keep []int64) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE users SET role = 'user' WHERE role = 'admin' AND id <> ALL(COALESCE($1::bigint[], '{}'))`,
+		keep)
+	return err
+}
+
+func (r *userRepository) SetBanned(ctx context.Context, userID int64, banned bool) error {
+	_, err := r.pool.Exec(ctx,
+		"INSERT INTO users (id, banned) VALUES ($1, $2) ON CONFLICT (id) DO UPDATE SET banned = EXCLUDED.banned",
+		userID, banned)
+	return err
+}
+
+// SetUnreachable stops deliveries to a chat that blocked or removed the bot.
+func (r *userRepository) SetUnreachable(ctx context.Context, chatID int64) error {
+	_, err := r.pool.Exec(ctx, "UPDATE users SET unreachable = TRUE WHERE id = $1", chatID)
+	return err
+}
+
+// chatKeys are the tables that belong to a chat, with the columns that make
+// a row unique within the chat; an empty key means rows never collide.
+var chatKeys = []struct{ table, key string }{
+	{"subscriptions", "category"},
+	{"source_follows", "source_id"},
+	{"source_mutes", "source"},
+	{"filter_rules", ""},
+	{"deliveries", ""},
+	{"bookmarks", "article_id"},
+	{"article_feedback", "article_id"},
+	{"clicks", ""},
+}
+
+// MigrateChat moves everything stored for a group to the supergroup it was
+// upgraded to. Rows the supergroup already has are kept, and migrating a
+// chat twice changes nothing.
+func (r *userRepository) MigrateChat(ctx context.Context, fromID, toID int64) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO users (id, role, banned, tracking_opt_out, breaking_alerts, summaries, languages, media, chat_type, last_active_at)
+			 SELECT $2, role, banned, tracking_opt_out, breaking_alerts, summaries, languages, media, 'supergroup', last_active_at
+			 FROM users WHERE id = $1
+			 ON CONFLICT (id) DO NOTHING`,
+			fromID, toID); err != nil {
+			return err
+		}
+		for _, t := range chatKeys {
+			update := fmt.Sprintf("UPDATE %s SET user_id = $2 WHERE user_id = $1", t.table)
+			if t.key != "" {
+				update += fmt.Sprintf(" AND %[2]s NOT IN (SELECT %[2]s FROM %[1]s WHERE user_id = $2)", t.table, t.key)
+			}
+			if _, err := tx.Exec(ctx, update, fromID, toID); err != nil {
+				return err
+			}
+			if _, err := tx.Exec(ctx, fmt.Sprintf("DELETE FROM %s WHERE user_id = $1", t.table), fromID); err != nil {
+				return err
+			}
+		}
+		_, err := tx.Exec(ctx, "DELETE FROM users WHERE id = $1", fromID)
+		return err
+	})
+}
+
+// GetActiveUserIDs returns every user that isn't banned or unreachable.
+func (r *userRepository) GetActiveUserIDs(ctx context.Context) ([]int64, error) {
+	rows, err := r.pool.Query(ctx, "SELECT id FROM users WHERE NOT banned AND NOT unreachable ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// ListUsers pages through users whose ID starts with search.
+func (r *userRepository) ListUsers(ctx context.Context, search string, limit, offset int) ([]entities.User, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, role, banned, chat_type, COALESCE(last_active_at, 'epoch') FROM users
+		 WHERE id::text LIKE $1 || '%' ORDER BY id LIMIT $2 OFFSET $3`,
+		search, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []entities.User
+	for rows.Next() {
+		var user entities.User
+		if err := rows.Scan(&user.ID, &user.Role, &user.Banned, &user.ChatType, &user.LastActiveAt); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
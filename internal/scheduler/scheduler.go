@@ -0,0 +1,225 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// idleWait is how long the loop sleeps when no job is planned.
+const idleWait = time.Minute
+
+// Job is a named piece of periodic work.
+type Job struct {
+	// Name identifies the job across restarts.
+	Name string
+	// Spec is a schedule accepted by Parse.
+	Spec string
+	Run  func(ctx context.Context)
+}
+
+// LastRunStore persists when each job last started so a restart continues
+// the schedule instead of running everything at once.
+type LastRunStore interface {
+	GetLastRuns(ctx context.Context) (map[string]time.Time, error)
+	SetLastRun(ctx context.Context, name string, at time.Time) error
+}
+
+type Option func(*Scheduler)
+
+// WithStore persists last-run times in store.
+func WithStore(store LastRunStore) Option {
+	return func(s *Scheduler) {
+		s.store = store
+	}
+}
+
+// WithJitter delays every run by a random duration below jitter, so jobs
+// with the same schedule don't all start in the same instant.
+func WithJitter(jitter time.Duration) Option {
+	return func(s *Scheduler) {
+		s.jitter = jitter
+	}
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Scheduler) {
+		s.logger = logger
+	}
+}
+
+// Scheduler runs jobs on their schedules. A run is skipped while the
+// previous run of the same job is still going.
+type Scheduler struct {
+	store  LastRunStore
+	jitter time.Duration
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	entries  map[string]*entry
+	lastRuns map[string]time.Time
+	wake     chan struct{}
+}
+
+type entry struct {
+	job      Job
+	schedule Schedule
+	// due is the run time the schedule asks for and next is due plus
+	// jitter. Zero means the job has to be planned.
+	due, next time.Time
+	running   bool
+}
+
+func New(opts ...Option) *Scheduler {
+	s := &Scheduler{
+		logger:   slog.Default(),
+		entries:  make(map[string]*entry),
+		lastRuns: make(map[string]time.Time),
+		wake:     make(chan struct{}, 1),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Sync replaces the set of jobs. Jobs whose spec is unchanged keep their
+// plan; a running job that is removed finishes its current run. Jobs with
+// an invalid spec are left out and reported in the returned error.
+func (s *Scheduler) Sync(jobs []Job) error {
+	var errs []error
+	s.mu.Lock()
+	seen := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		schedule, err := Parse(job.Spec)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("job %s: %w", job.Name, err))
+			continue
+		}
+		seen[job.Name] = true
+		if e, ok := s.entries[job.Name]; ok {
+			if e.job.Spec != job.Spec {
+				e.schedule, e.due, e.next = schedule, time.Time{}, time.Time{}
+			}
+			e.job = job
+			continue
+		}
+		s.entries[job.Name] = &entry{job: job, schedule: schedule}
+	}
+	for name := range s.entries {
+		if !seen[name] {
+			delete(s.entries, name)
+		}
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return errors.Join(errs...)
+}
+
+// Run starts due jobs until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	if s.store != nil {
+		lastRuns, err := s.store.GetLastRuns(ctx)
+		if err != nil {
+			s.logger.Error("Error loading last job runs", "error", err)
+		}
+		s.mu.Lock()
+		for name, at := range lastRuns {
+			s.lastRuns[name] = at
+		}
+		s.mu.Unlock()
+	}
+
+	for {
+		timer := time.NewTimer(s.tick(ctx, time.Now()))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// tick plans new jobs, starts the due ones and returns how long to wait for
+// the next one.
+func (s *Scheduler) tick(ctx context.Context, now time.Time) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wait := idleWait
+	for _, e := range s.entries {
+		if e.next.IsZero() {
+			s.setDue(e, s.plan(e, now))
+		}
+		if !e.next.IsZero() && !e.next.After(now) {
+			s.start(ctx, e, now)
+			// Planning from the due time keeps jitter from accumulating.
+			due := e.schedule.Next(e.due)
+			if due.Before(now) {
+				due = e.schedule.Next(now)
+			}
+			s.setDue(e, due)
+		}
+		if e.next.IsZero() {
+			continue
+		}
+		wait = min(wait, e.next.Sub(now))
+	}
+	return wait
+}
+
+// plan picks the first run of a job: the run after its last one, or a
+// catch-up run now if that is overdue. A job that never ran waits one
+// period.
+func (s *Scheduler) plan(e *entry, now time.Time) time.Time {
+	last, ok := s.lastRuns[e.job.Name]
+	if !ok {
+		return e.schedule.Next(now)
+	}
+	next := e.schedule.Next(last)
+	if next.Before(now) {
+		next = now
+	}
+	return next
+}
+
+func (s *Scheduler) setDue(e *entry, due time.Time) {
+	e.due, e.next = due, due
+	if s.jitter > 0 && !due.IsZero() {
+		e.next = due.Add(rand.N(s.jitter))
+	}
+}
+
+func (s *Scheduler) start(ctx context.Context, e *entry, now time.Time) {
+	if e.running {
+		s.logger.Warn("Previous run is still going, skipping", "job", e.job.Name)
+		return
+	}
+	e.running = true
+	s.lastRuns[e.job.Name] = now
+	job := e.job
+	go func() {
+		defer func() {
+			s.mu.Lock()
+			e.running = false
+			s.mu.Unlock()
+		}()
+		if s.store != nil {
+			if err := s.store.SetLastRun(ctx, job.Name, now); err != nil {
+				s.logger.Error("Error saving last job run", "job", job.Name, "error", err)
+			}
+		}
+		job.Run(ctx)
+	}()
+}
@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"tgbot/internal/entities"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type AdminService interface {
+	ListUsers(ctx context.Context, search string, limit, offset int) ([]entities.User, error)
+	SetBanned(ctx context.Context, userID int64, banned bool) error
+	GetDeliveries(ctx context.Context, userID int64, limit int) ([]entities.Delivery, error)
+}
+
+type SubscriptionService interface {
+	SaveSubscription(ctx context.Context, user *entities.User, subscription *entities.Subscription) error
+	GetUserSubscriptions(ctx context.Context, userID int64) ([]entities.Subscription, error)
+	DeleteSubscription(ctx context.Context, userID int64, category string) (bool, error)
+}
+
+type NewsCycleTrigger interface {
+	TriggerNewsCycle(ctx context.Context) bool
+}
+
+type CategoryProvider interface {
+	Categories(ctx context.Context) []string
+}
+
+// AdminHandler serves the admin API under /admin/. Every request must carry
+// the configured bearer token.
+type AdminHandler struct {
+	token         string
+	admin         AdminService
+	subscriptions SubscriptionService
+	newsCycle     NewsCycleTrigger
+	categories    CategoryProvider
+	// cycleCtx outlives the request that triggers a news cycle.
+	cycleCtx context.Context
+	mux      *http.ServeMux
+}
+
+func NewAdminHandler(cycleCtx context.Context, token string, admin AdminService, subscriptions SubscriptionService, newsCycle NewsCycleTrigger, categories CategoryProvider) *AdminHandler {
+	h := &AdminHandler{
+		token:         token,
+		admin:         admin,
+		subscriptions: subscriptions,
+		newsCycle:     newsCycle,
+		categories:    categories,
+		cycleCtx:      cycleCtx,
+		mux:           http.NewServeMux(),
+	}
+
+	h.mux.HandleFunc("GET /admin/users", h.listUsers)
+	h.mux.HandleFunc("POST /admin/users/{id}/ban", h.setBanned(true))
+	h.mux.HandleFunc("POST /admin/users/{id}/unban", h.setBanned(false))
+	h.mux.HandleFunc("GET /admin/users/{id}/subscriptions", h.listSubscriptions)
+	h.mux.HandleFunc("POST /admin/users/{id}/subscriptions", h.addSubscription)
+	h.mux.HandleFunc("DELETE /admin/users/{id}/subscriptions/{category}", h.deleteSubscription)
+	h.mux.HandleFunc("GET /admin/categories", h.listCategories)
+	h.mux.HandleFunc("POST /admin/news-cycle", h.triggerNewsCycle)
+	h.mux.HandleFunc("GET /admin/deliveries", h.listDeliveries)
+	return h
+}
+
+func (h *AdminHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.token)) != 1 {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	h.mux.ServeHTTP(w, r)
+}
+
+func (h *AdminHandler) listUsers(w http.ResponseWriter, r *http.Request) {
+	limit, offset, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+	users, err := h.admin.ListUsers(r.Context(), r.URL.Query().Get("q"), limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if users == nil {
+		users = []entities.User{}
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (h *AdminHandler) setBanned(banned bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := pathUserID(w, r)
+		if !ok {
+			return
+		}
+		if err := h.admin.SetBanned(r.Context(), userID, banned); err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"id": userID, "banned": banned})
+	}
+}
+
+func (h *AdminHandler) listSubscriptions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+	subscriptions, err := h.subscriptions.GetUserSubscriptions(r.Context(), userID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if subscriptions == nil {
+		subscriptions = []entities.Subscription{}
+	}
+	writeJSON(w, http.StatusOK, subscriptions)
+}
+
+func (h *AdminHandler) addSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+	var body struct {
+		Category string `json:"category"`
+		Country  string `json:"country"`
+		Language string `json:"language"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid JSON body")
+		return
+	}
+	category := strings.ToLower(strings.TrimSpace(body.Category))
+	if !h.knownCategory(r.Context(), category) {
+		writeError(w, http.StatusBadRequest, "unknown category "+strconv.Quote(category))
+		return
+	}
+
+	country := strings.ToLower(strings.TrimSpace(body.Country))
+	if country != "" && !entities.IsNewsCountry(country) {
+		writeError(w, http.StatusBadRequest, "unknown country "+strconv.Quote(country))
+		return
+	}
+	language := strings.ToLower(strings.TrimSpace(body.Language))
+	if language != "" && !entities.IsNewsLanguage(language) {
+		writeError(w, http.StatusBadRequest, "unknown language "+strconv.Quote(language))
+		return
+	}
+
+	subscription := &entities.Subscription{
+		UserID:   userID,
+		Category: category,
+		Country:  country,
+		Language: language,
+	}
+	if err := h.subscriptions.SaveSubscription(r.Context(), &entities.User{ID: userID}, subscription); err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusCreated, subscription)
+}
+
+func (h *AdminHandler) deleteSubscription(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+	deleted, err := h.subscriptions.DeleteSubscription(r.Context(), userID, r.PathValue("category"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !deleted {
+		writeError(w, http.StatusNotFound, "subscription not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AdminHandler) listCategories(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, h.categories.Categories(r.Context()))
+}
+
+func (h *AdminHandler) triggerNewsCycle(w http.ResponseWriter, r *http.Request) {
+	if !h.newsCycle.TriggerNewsCycle(h.cycleCtx) {
+		writeError(w, http.StatusConflict, "a news cycle is already running")
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "started"})
+}
+
+func (h *AdminHandler) listDeliveries(w http.ResponseWriter, r *http.Request) {
+	limit, _, ok := pagination(w, r)
+	if !ok {
+		return
+	}
+	var userID int64
+	if raw := r.URL.Query().Get("user_id"); raw != "" {
+		var err error
+		if userID, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid user_id")
+			return
+		}
+	}
+	deliveries, err := h.admin.GetDeliveries(r.Context(), userID, limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	if deliveries == nil {
+		deliveries = []entities.Delivery{}
+	}
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+func (h *AdminHandler) knownCategory(ctx context.Context, category string) bool {
+	for _, c := range h.categories.Categories(ctx) {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+func pathUserID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid user id")
+		return 0, false
+	}
+	return userID, true
+}
+
+func pagination(w http.ResponseWriter, r *http.Request) (limit, offset int, ok bool) {
+	limit, offset = defaultPageSize, 0
+	query := r.URL.Query()
+	if raw := query.Get("limit"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return 0, 0, false
+		}
+		limit = min(v, maxPageSize)
+	}
+	if raw := query.Get("offset"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 {
+			writeError(w, http.StatusBadRequest, "invalid offset")
+			return 0, 0, false
+		}
+		offset = v
+	}
+	return limit, offset, true
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const healthCheckTimeout = 3 * time.Second
+
+// HealthCheck is one component of the readiness report.
+type HealthCheck struct {
+	Name string
+	// Critical checks make /readyz fail; the others only mark it degraded.
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+type checkResult struct {
+	Status   string `json:"status"`
+	Critical bool   `json:"critical"`
+	Error    string `json:"error,omitempty"`
+}
+
+type readinessReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]checkResult `json:"checks"`
+}
+
+// HealthHandler serves /healthz, which only says the process is alive, and
+// /readyz, which runs every check and reports what is degraded.
+type HealthHandler struct {
+	checks []HealthCheck
+}
+
+func NewHealthHandler(checks ...HealthCheck) *HealthHandler {
+	return &HealthHandler{checks: checks}
+}
+
+func (h *HealthHandler) Healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (h *HealthHandler) Readyz(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	results := make([]checkResult, len(h.checks))
+	var wg sync.WaitGroup
+	for i, check := range h.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = checkResult{Status: "ok", Critical: check.Critical}
+			if err := check.Check(ctx); err != nil {
+				results[i].Status = "failing"
+				results[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := readinessReport{Status: "ok", Checks: make(map[string]checkResult, len(h.checks))}
+	status := http.StatusOK
+	for i, check := range h.checks {
+		result := results[i]
+		report.Checks[check.Name] = result
+		if result.Status == "ok" {
+			continue
+		}
+		if check.Critical {
+			report.Status = "unavailable"
+			status = http.StatusServiceUnavailable
+		} else if report.Status == "ok" {
+			report.Status = "degraded"
+		}
+	}
+	writeJSON(w, status, report)
+}
+
+// RecencyCheck fails when last reports nothing more recent than maxAge. Until
+// the first success, startedAt counts as the last one so a freshly started
+// process has time to warm up.
+func RecencyCheck(last func() time.Time, startedAt time.Time, maxAge time.Duration) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		at := last()
+		if at.IsZero() {
+			if time.Since(startedAt) <= maxAge {
+				return nil
+			}
+			return fmt.Errorf("no success since start %s ago", time.Since(startedAt).Round(time.Second))
+		}
+		if age := time.Since(at); age > maxAge {
+			return fmt.Errorf("last success %s ago", age.Round(time.Second))
+		}
+		return nil
+	}
+}
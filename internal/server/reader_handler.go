@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"tgbot/internal/entities"
+)
+
+// ReaderPath is where the reader handler is mounted; the signed article
+// token follows it.
+const ReaderPath = "/read/"
+
+type ReaderService interface {
+	ReadPage(ctx context.Context, token string) (*entities.Article, []string, error)
+}
+
+// readerPage follows the markup Telegram's Instant View understands: one
+// <article> with an <h1> title, an <address> byline and plain paragraphs.
+var readerPage = template.Must(template.New("reader").Parse(`<!DOCTYPE html>
+<html lang="ru">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta property="og:type" content="article">
+<meta property="og:title" content="{{.Article.Title}}">
+{{with .Article.SourceName}}<meta property="og:site_name" content="{{.}}">
+{{end}}{{with .Article.Description}}<meta property="og:description" content="{{.}}">
+{{end}}<title>{{.Article.Title}}</title>
+<style>
+body { max-width: 40em; margin: 2em auto; padding: 0 1em; font: 18px/1.6 Georgia, serif; color: #222; }
+h1 { font-size: 1.6em; line-height: 1.25; }
+address { font-style: normal; color: #777; }
+</style>
+</head>
+<body>
+<article>
+<h1>{{.Article.Title}}</h1>
+<address>{{with .Article.SourceName}}{{.}}{{end}}{{if and .Article.SourceName .Article.PublishedAt}} · {{end}}{{with .Article.PublishedAt}}<time datetime="{{.}}">{{.}}</time>{{end}}</address>
+{{range .Paragraphs}}<p>{{.}}</p>
+{{end}}<p><a href="{{.Article.URL}}">Оригинал статьи</a></p>
+</article>
+</body>
+</html>
+`))
+
+// ReaderHandler serves the extracted text of a stored article as a plain
+// HTML page.
+type ReaderHandler struct {
+	reader ReaderService
+}
+
+func NewReaderHandler(reader ReaderService) *ReaderHandler {
+	return &ReaderHandler{reader: reader}
+}
+
+func (h *ReaderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	article, paragraphs, err := h.reader.ReadPage(r.Context(), r.PathValue("token"))
+	if err != nil {
+		slog.Error("Error reading article", "error", err)
+		http.Error(w, "temporarily unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if article == nil || len(paragraphs) == 0 {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=3600")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	if err := readerPage.Execute(w, struct {
+		Article    *entities.Article
+		Paragraphs []string
+	}{article, paragraphs}); err != nil {
+		slog.Error("Error rendering reader page", "error", err)
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+)
+
+// RedirectPath is where the redirect handler is mounted; the token follows
+// it.
+const RedirectPath = "/r/"
+
+type ClickService interface {
+	Open(ctx context.Context, token string) (url string, found bool, err error)
+}
+
+// RedirectHandler records a click on a tracked article link and redirects
+// to the article.
+type RedirectHandler struct {
+	clicks ClickService
+}
+
+func NewRedirectHandler(clicks ClickService) *RedirectHandler {
+	return &RedirectHandler{clicks: clicks}
+}
+
+func (h *RedirectHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	url, found, err := h.clicks.Open(r.Context(), r.PathValue("token"))
+	if err != nil {
+		slog.Error("Error recording click", "error", err)
+		http.Error(w, "temporarily unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if !found {
+		http.NotFound(w, r)
+		return
+	}
+	// Every click has to reach the server, and the token mustn't leak to
+	// the article's site.
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	http.Redirect(w, r, url, http.StatusFound)
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net/url"
+	"tgbot/internal/entities"
+	"tgbot/internal/logging"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// NewsFetcher is the part of a news provider the cache sits in front of.
+type NewsFetcher interface {
+	GetNews(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error)
+}
+
+// Cache stores fetched articles under a provider and query key.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]entities.Article, bool, error)
+	Set(ctx context.Context, key string, articles []entities.Article, ttl time.Duration) error
+}
+
+// CachedNewsService serves repeated queries from the cache and collapses
+// concurrent identical queries into a single provider request.
+type CachedNewsService struct {
+	next     NewsFetcher
+	provider string
+	cache    Cache
+	ttl      time.Duration
+	group    singleflight.Group
+}
+
+func NewCachedNewsService(next NewsFetcher, provider string, cache Cache, ttl time.Duration) *CachedNewsService {
+	return &CachedNewsService{
+		next:     next,
+		provider: provider,
+		cache:    cache,
+		ttl:      ttl,
+	}
+}
+
+func (s *CachedNewsService) GetNews(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+	return s.get(ctx, queryParams(query), func(ctx context.Context) ([]entities.Article, error) {
+		return s.next.GetNews(ctx, query)
+	})
+}
+
+func (s *CachedNewsService) get(ctx context.Context, query url.Values, fetch func(ctx context.Context) ([]entities.Article, error)) ([]entities.Article, error) {
+	key := s.provider + ":" + query.Encode()
+
+	if articles, ok, err := s.cache.Get(ctx, key); err != nil {
+		logging.FromContext(ctx, slog.Default()).Error("Error reading news cache", "key", key, "error", err)
+	} else if ok {
+		return articles, nil
+	}
+
+	ch := s.group.DoChan(key, func() (any, error) {
+		// The shared fetch must not fail just because the first caller went away.
+		fetchCtx := context.WithoutCancel(ctx)
+		articles, err := fetch(fetchCtx)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.cache.Set(fetchCtx, key, articles, s.ttl); err != nil {
+			logging.FromContext(ctx, slog.Default()).Error("Error writing news cache", "key", key, "error", err)
+		}
+		return articles, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]entities.Article), nil
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrAPIKeyInvalid    = errors.New("newsapi: api key invalid")
+	ErrAPIKeyExhausted  = errors.New("newsapi: api key exhausted")
+	ErrAPIKeyDisabled   = errors.New("newsapi: api key disabled")
+	ErrRateLimited      = errors.New("newsapi: rate limited")
+	ErrParameterInvalid = errors.New("newsapi: parameter invalid")
+	ErrQuotaExceeded    = errors.New("newsapi: daily request budget exhausted")
+	ErrCircuitOpen      = errors.New("newsapi: circuit breaker is open")
+)
+
+// APIError is the error body NewsAPI returns alongside a non-ok status.
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	// RetryAfter is the wait the Retry-After header asked for, if any.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("newsapi error (status %d, code %s): %s", e.StatusCode, e.Code, e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	switch e.Code {
+	case "apiKeyInvalid", "apiKeyMissing":
+		return ErrAPIKeyInvalid
+	case "apiKeyExhausted":
+		return ErrAPIKeyExhausted
+	case "apiKeyDisabled":
+		return ErrAPIKeyDisabled
+	case "rateLimited":
+		return ErrRateLimited
+	case "parameterInvalid", "parametersMissing":
+		return ErrParameterInvalid
+	}
+	return nil
+}
+
+// temporary reports whether the request that produced the error is worth retrying.
+func (e *APIError) temporary() bool {
+	return e.StatusCode >= 500
+}
+
+// transportError wraps failures to reach the API at all.
+type transportError struct {
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("failed to fetch news: %v", e.err)
+}
+
+func (e *transportError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNoAvailableKeys means every configured key is exhausted or disabled.
+var ErrNoAvailableKeys = fmt.Errorf("newsapi: no available api keys: %w", ErrQuotaExceeded)
+
+// rateLimitCooldown is how long a rate-limited key rests when NewsAPI
+// doesn't say.
+const rateLimitCooldown = 15 * time.Minute
+
+type KeyStrategy string
+
+const (
+	RoundRobin KeyStrategy = "round_robin"
+	LeastUsed  KeyStrategy = "least_used"
+)
+
+// KeyStatus describes the health of one API key.
+type KeyStatus struct {
+	ID            string
+	Requests      int
+	Remaining     int
+	DisabledUntil time.Time
+	LastError     string
+}
+
+type apiKey struct {
+	value         string
+	requests      int
+	day           time.Time
+	disabledUntil time.Time
+	lastError     string
+}
+
+// KeyPool hands out API keys in turn and skips keys NewsAPI has refused
+// until they can be used again.
+type KeyPool struct {
+	mu       sync.Mutex
+	keys     []*apiKey
+	strategy KeyStrategy
+	next     int
+}
+
+func NewKeyPool(keys []string, strategy KeyStrategy) *KeyPool {
+	pool := &KeyPool{strategy: strategy}
+	for _, key := range keys {
+		pool.keys = append(pool.keys, &apiKey{value: key})
+	}
+	return pool
+}
+
+func (p *KeyPool) Len() int {
+	return len(p.keys)
+}
+
+// Acquire returns the next usable key and counts a request against it.
+func (p *KeyPool) Acquire() (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var chosen *apiKey
+	for i := range p.keys {
+		idx := (p.next + i) % len(p.keys)
+		key := p.keys[idx]
+		key.resetIfNewDay(now)
+		if now.Before(key.disabledUntil) {
+			continue
+		}
+		if p.strategy == LeastUsed {
+			if chosen == nil || key.requests < chosen.requests {
+				chosen = key
+			}
+			continue
+		}
+		chosen = key
+		p.next = idx + 1
+		break
+	}
+	if chosen == nil {
+		return "", ErrNoAvailableKeys
+	}
+
+	chosen.requests++
+	return chosen.value, nil
+}
+
+// Report records the outcome of a request made with the key. Keys that are
+// exhausted, disabled or invalid are skipped until the next daily reset,
+// rate-limited keys for a short cooldown.
+func (p *KeyPool) Report(value string, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, key := range p.keys {
+		if key.value != value {
+			continue
+		}
+		if err == nil {
+			key.lastError = ""
+			return
+		}
+		key.lastError = err.Error()
+		if keyUnusable(err) {
+			key.disabledUntil = disabledUntil(err, time.Now())
+		}
+		return
+	}
+}
+
+func (p *KeyPool) Statuses() []KeyStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	statuses := make([]KeyStatus, 0, len(p.keys))
+	for _, key := range p.keys {
+		key.resetIfNewDay(now)
+		status := KeyStatus{
+			ID:        KeyID(key.value),
+			Requests:  key.requests,
+			LastError: key.lastError,
+		}
+		if now.Before(key.disabledUntil) {
+			status.DisabledUntil = key.disabledUntil
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func (k *apiKey) resetIfNewDay(now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	if !k.day.Equal(day) {
+		k.day = day
+		k.requests = 0
+	}
+}
+
+func keyUnusable(err error) bool {
+	return errors.Is(err, ErrAPIKeyExhausted) ||
+		errors.Is(err, ErrAPIKeyDisabled) ||
+		errors.Is(err, ErrAPIKeyInvalid) ||
+		errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrQuotaExceeded)
+}
+
+// disabledUntil returns when a key refused with err can be used again.
+// Rate limiting is a short-window throttle, so the key rests for as long as
+// Retry-After asks, or rateLimitCooldown; other refusals last until the
+// daily reset.
+func disabledUntil(err error, now time.Time) time.Time {
+	if !errors.Is(err, ErrRateLimited) {
+		return nextReset(now)
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		return now.Add(apiErr.RetryAfter)
+	}
+	return now.Add(rateLimitCooldown)
+}
+
+// nextReset returns the next UTC midnight, when NewsAPI resets daily quotas.
+func nextReset(now time.Time) time.Time {
+	return now.UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"tgbot/internal/entities"
+	"time"
+)
+
+type memoryCacheEntry struct {
+	articles  []entities.Article
+	expiresAt time.Time
+}
+
+// MemoryCache is a process-local Cache.
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[string]memoryCacheEntry
+}
+
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[string]memoryCacheEntry)}
+}
+
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]entities.Article, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	return entry.articles, true, nil
+}
+
+func (c *MemoryCache) Set(ctx context.Context, key string, articles []entities.Article, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = memoryCacheEntry{articles: articles, expiresAt: now.Add(ttl)}
+	return nil
+}
@@ -3,54 +3,126 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"strconv"
 	"tgbot/internal/entities"
+	"tgbot/internal/metrics"
+	"time"
+)
+
+const (
+	defaultBaseURL    = "https://newsapi.org"
+	defaultTimeout    = 10 * time.Second
+	defaultMaxRetries = 3
+	defaultBackoff    = 500 * time.Millisecond
 )
 
 type NewsAPIService struct {
-	apiKey string
+	keys       *KeyPool
+	strategy   KeyStrategy
+	baseURL    string
+	client     *http.Client
+	maxRetries int
+	backoff    time.Duration
+	budget     *Budget
+	breaker    *CircuitBreaker
+	metrics    metrics.Recorder
 }
 
-func NewNewsAPIService(apiKey string) *NewsAPIService {
-	return &NewsAPIService{apiKey: apiKey}
+type Option func(*NewsAPIService)
+
+// WithHTTPClient replaces the shared client, e.g. to change the timeout.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *NewsAPIService) {
+		s.client = client
+	}
 }
 
-func (s *NewsAPIService) GetNewsByCategory(ctx context.Context, category string) ([]entities.Article, error) {
-	url := fmt.Sprintf("https://newsapi.org/v2/top-headlines?category=%s&apiKey=%s", category, s.apiKey)
+// WithBaseURL points the service at another host, e.g. a local stub server.
+func WithBaseURL(baseURL string) Option {
+	return func(s *NewsAPIService) {
+		s.baseURL = baseURL
+	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+// WithRetries sets how many times a transient failure is retried and the
+// initial backoff, which doubles on every attempt.
+func WithRetries(maxRetries int, backoff time.Duration) Option {
+	return func(s *NewsAPIService) {
+		s.maxRetries = maxRetries
+		s.backoff = backoff
 	}
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch news: %w", err)
+// WithBudget counts every request against the key's daily budget.
+func WithBudget(budget *Budget) Option {
+	return func(s *NewsAPIService) {
+		s.budget = budget
 	}
-	defer resp.Body.Close()
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// WithCircuitBreaker short-circuits requests while NewsAPI is failing.
+func WithCircuitBreaker(breaker *CircuitBreaker) Option {
+	return func(s *NewsAPIService) {
+		s.breaker = breaker
 	}
+}
 
-	var response struct {
-		Status   string `json:"status"`
-		Articles []struct {
-			Title       string `json:"title"`
-			Description string `json:"description"`
-			URL         string `json:"url"`
-			PublishedAt string `json:"publishedAt"`
-		} `json:"articles"`
+// WithKeyStrategy selects how requests are spread across the API keys.
+func WithKeyStrategy(strategy KeyStrategy) Option {
+	return func(s *NewsAPIService) {
+		s.strategy = strategy
+	}
+}
+
+// WithRecorder observes the latency and status of every HTTP request.
+func WithRecorder(recorder metrics.Recorder) Option {
+	return func(s *NewsAPIService) {
+		s.metrics = recorder
 	}
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+func NewNewsAPIService(apiKeys []string, opts ...Option) *NewsAPIService {
+	s := &NewsAPIService{
+		strategy:   RoundRobin,
+		baseURL:    defaultBaseURL,
+		client:     &http.Client{Timeout: defaultTimeout},
+		maxRetries: defaultMaxRetries,
+		backoff:    defaultBackoff,
+		metrics:    metrics.Nop{},
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.keys = NewKeyPool(apiKeys, s.strategy)
+	return s
+}
 
-	if response.Status != "ok" {
-		return nil, fmt.Errorf("API error: status %s", response.Status)
+type articlesResponse struct {
+	Articles []struct {
+		Source struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"source"`
+		Author      string `json:"author"`
+		Title       string `json:"title"`
+		Description string `json:"description"`
+		URL         string `json:"url"`
+		URLToImage  string `json:"urlToImage"`
+		PublishedAt string `json:"publishedAt"`
+	} `json:"articles"`
+}
+
+func (s *NewsAPIService) GetNews(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+	params := queryParams(query)
+
+	var response articlesResponse
+	if err := s.get(ctx, "/v2/top-headlines", params, &response); err != nil {
+		return nil, err
 	}
 
 	articles := make([]entities.Article, 0, len(response.Articles))
@@ -60,8 +132,205 @@ func (s *NewsAPIService) GetNewsByCategory(ctx context.Context, category string)
 			Description: a.Description,
 			URL:         a.URL,
 			PublishedAt: a.PublishedAt,
+			SourceID:    a.Source.ID,
+			SourceName:  a.Source.Name,
+			Author:      a.Author,
+			ImageURL:    a.URLToImage,
 		})
 	}
 
 	return articles, nil
 }
+
+// GetSources returns the catalogue of sources NewsAPI serves headlines from.
+func (s *NewsAPIService) GetSources(ctx context.Context) ([]entities.Source, error) {
+	var response struct {
+		Sources []entities.Source `json:"sources"`
+	}
+	if err := s.get(ctx, "/v2/top-headlines/sources", url.Values{}, &response); err != nil {
+		return nil, err
+	}
+	return response.Sources, nil
+}
+
+func queryParams(query entities.NewsQuery) url.Values {
+	params := url.Values{}
+	if query.Category != "" {
+		params.Set("category", query.Category)
+	}
+	if query.Country != "" {
+		params.Set("country", query.Country)
+	}
+	if query.Language != "" {
+		params.Set("language", query.Language)
+	}
+	if query.Sources != "" {
+		params.Set("sources", query.Sources)
+	}
+	if query.Keywords != "" {
+		params.Set("q", query.Keywords)
+	}
+	return params
+}
+
+// get performs a GET request against the API, retrying network errors and
+// 5xx responses with jittered exponential backoff.
+func (s *NewsAPIService) get(ctx context.Context, path string, params url.Values, out any) error {
+	if s.breaker == nil {
+		return s.getWithRetries(ctx, path, params, out)
+	}
+	if !s.breaker.Allow() {
+		return ErrCircuitOpen
+	}
+	err := s.getWithRetries(ctx, path, params, out)
+	if ctx.Err() != nil {
+		// A cancelled request says nothing about the service.
+		s.breaker.Release()
+		return err
+	}
+	s.breaker.Record(err)
+	return err
+}
+
+// CircuitState reports the breaker state, or closed if there is no breaker.
+func (s *NewsAPIService) CircuitState() CircuitState {
+	if s.breaker == nil {
+		return CircuitClosed
+	}
+	return s.breaker.State()
+}
+
+func (s *NewsAPIService) getWithRetries(ctx context.Context, path string, params url.Values, out any) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = s.doGet(ctx, path, params, out)
+		if err == nil || attempt >= s.maxRetries || !isTemporary(ctx, err) {
+			return err
+		}
+
+		delay := s.backoff << attempt
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// KeyStatuses reports the health and remaining budget of every API key.
+func (s *NewsAPIService) KeyStatuses(ctx context.Context) []KeyStatus {
+	statuses := s.keys.Statuses()
+	for i := range statuses {
+		statuses[i].Remaining = -1
+	}
+	if s.budget == nil {
+		return statuses
+	}
+	for i, key := range s.keys.keys {
+		if remaining, err := s.budget.Remaining(ctx, key.value); err == nil {
+			statuses[i].Remaining = remaining
+		}
+	}
+	return statuses
+}
+
+// doGet sends the request with the next available key, moving on to the
+// following key when NewsAPI refuses the current one.
+func (s *NewsAPIService) doGet(ctx context.Context, path string, params url.Values, out any) error {
+	var err error
+	for i := 0; i < s.keys.Len(); i++ {
+		var key string
+		key, err = s.keys.Acquire()
+		if err != nil {
+			return err
+		}
+		err = s.doGetWithKey(ctx, key, path, params, out)
+		s.keys.Report(key, err)
+		if err == nil || !keyUnusable(err) {
+			return err
+		}
+	}
+	// Every key was tried and refused, so callers see the pool as exhausted
+	// rather than the last key's refusal.
+	if err == nil {
+		return ErrNoAvailableKeys
+	}
+	return fmt.Errorf("%w: %w", ErrNoAvailableKeys, err)
+}
+
+func (s *NewsAPIService) doGetWithKey(ctx context.Context, apiKey, path string, params url.Values, out any) error {
+	if s.budget != nil {
+		// Usage that can't be recorded doesn't block the request.
+		if err := s.budget.Reserve(ctx, apiKey); errors.Is(err, ErrQuotaExceeded) {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	// The key goes in a header so it never shows up in logged URLs.
+	req.Header.Set("X-Api-Key", apiKey)
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.metrics.NewsAPIRequest("error", time.Since(start))
+		return &transportError{err: err}
+	}
+	s.metrics.NewsAPIRequest(strconv.Itoa(resp.StatusCode), time.Since(start))
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: resp.StatusCode}
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		var body struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err == nil {
+			apiErr.Code = body.Code
+			apiErr.Message = body.Message
+		}
+		return apiErr
+	}
+
+	var status struct {
+		Status  string `json:"status"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	raw := json.RawMessage{}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if err := json.Unmarshal(raw, &status); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	if status.Status != "ok" {
+		return &APIError{StatusCode: resp.StatusCode, Code: status.Code, Message: status.Message}
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+func isTemporary(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.temporary()
+	}
+	var transportErr *transportError
+	return errors.As(err, &transportErr)
+}
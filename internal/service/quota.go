@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// UsageStore keeps the number of requests made with an API key per day.
+type UsageStore interface {
+	IncrementUsage(ctx context.Context, keyID string, day time.Time) (int, error)
+	GetUsage(ctx context.Context, keyID string, day time.Time) (int, error)
+}
+
+// Budget limits the number of requests a key may make per UTC day.
+type Budget struct {
+	store      UsageStore
+	dailyLimit int
+}
+
+func NewBudget(store UsageStore, dailyLimit int) *Budget {
+	return &Budget{store: store, dailyLimit: dailyLimit}
+}
+
+// Reserve counts one request against the key and fails with
+// ErrQuotaExceeded once the daily limit is used up.
+func (b *Budget) Reserve(ctx context.Context, apiKey string) error {
+	used, err := b.store.IncrementUsage(ctx, KeyID(apiKey), today())
+	if err != nil {
+		return err
+	}
+	if used > b.dailyLimit {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// Remaining returns how many requests the key has left today.
+func (b *Budget) Remaining(ctx context.Context, apiKey string) (int, error) {
+	used, err := b.store.GetUsage(ctx, KeyID(apiKey), today())
+	if err != nil {
+		return 0, err
+	}
+	if used >= b.dailyLimit {
+		return 0, nil
+	}
+	return b.dailyLimit - used, nil
+}
+
+// KeyID identifies an API key in storage and logs without exposing it.
+func KeyID(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+func today() time.Time {
+	return time.Now().UTC().Truncate(24 * time.Hour)
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"tgbot/internal/entities"
+	"time"
+)
+
+const maxFeedSize = 5 << 20
+
+// RSSService reads RSS 2.0 and Atom feeds for categories backed by feeds.
+type RSSService struct {
+	client *http.Client
+}
+
+func NewRSSService(client *http.Client) *RSSService {
+	return &RSSService{client: client}
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title       string `xml:"title"`
+			Link        string `xml:"link"`
+			Description string `xml:"description"`
+			PubDate     string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+type atomDocument struct {
+	Title   string `xml:"title"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+		} `xml:"link"`
+		Summary   string `xml:"summary"`
+		Content   string `xml:"content"`
+		Updated   string `xml:"updated"`
+		Published string `xml:"published"`
+	} `xml:"entry"`
+}
+
+// GetFeeds returns the articles of all feeds. Feeds that fail are skipped;
+// an error is returned only if every feed failed.
+func (s *RSSService) GetFeeds(ctx context.Context, urls []string) ([]entities.Article, error) {
+	var articles []entities.Article
+	var errs []error
+	for _, feedURL := range urls {
+		feed, err := s.getFeed(ctx, feedURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("feed %s: %w", feedURL, err))
+			continue
+		}
+		articles = append(articles, feed...)
+	}
+	if len(errs) > 0 && len(errs) == len(urls) {
+		return nil, errors.Join(errs...)
+	}
+	return articles, nil
+}
+
+func (s *RSSService) getFeed(ctx context.Context, feedURL string) ([]entities.Article, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, err
+	}
+	return ParseFeed(body)
+}
+
+// ParseFeed parses an RSS 2.0 or Atom document.
+func ParseFeed(body []byte) ([]entities.Article, error) {
+	var root struct {
+		XMLName xml.Name
+	}
+	if err := xml.Unmarshal(body, &root); err != nil {
+		return nil, fmt.Errorf("failed to decode feed: %w", err)
+	}
+
+	var articles []entities.Article
+	switch root.XMLName.Local {
+	case "rss":
+		var doc rssDocument
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			return nil, fmt.Errorf("failed to decode feed: %w", err)
+		}
+		for _, item := range doc.Channel.Items {
+			articles = append(articles, entities.Article{
+				Title:       strings.TrimSpace(item.Title),
+				Description: plainText(item.Description),
+				URL:         strings.TrimSpace(item.Link),
+				PublishedAt: feedTime(item.PubDate),
+				SourceName:  strings.TrimSpace(doc.Channel.Title),
+			})
+		}
+	case "feed":
+		var doc atomDocument
+		if err := xml.Unmarshal(body, &doc); err != nil {
+			return nil, fmt.Errorf("failed to decode feed: %w", err)
+		}
+		for _, entry := range doc.Entries {
+			article := entities.Article{
+				Title:       strings.TrimSpace(entry.Title),
+				Description: plainText(entry.Summary),
+				PublishedAt: feedTime(entry.Published),
+				SourceName:  strings.TrimSpace(doc.Title),
+			}
+			if article.Description == "" {
+				article.Description = plainText(entry.Content)
+			}
+			if article.PublishedAt == "" {
+				article.PublishedAt = feedTime(entry.Updated)
+			}
+			for _, link := range entry.Links {
+				if link.Rel == "" || link.Rel == "alternate" {
+					article.URL = link.Href
+					break
+				}
+			}
+			articles = append(articles, article)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported feed format %q", root.XMLName.Local)
+	}
+
+	valid := articles[:0]
+	for _, article := range articles {
+		if article.URL != "" && article.Title != "" {
+			valid = append(valid, article)
+		}
+	}
+	return valid, nil
+}
+
+var feedTimeLayouts = []string{time.RFC1123Z, time.RFC1123, time.RFC3339, "Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST"}
+
+// feedTime converts a feed date to RFC 3339 in UTC, the format NewsAPI uses,
+// so articles from both sources sort together.
+func feedTime(value string) string {
+	value = strings.TrimSpace(value)
+	for _, layout := range feedTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC().Format(time.RFC3339)
+		}
+	}
+	return ""
+}
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+func plainText(value string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(htmlTag.ReplaceAllString(value, " "))), " ")
+}
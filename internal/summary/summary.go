@@ -0,0 +1,159 @@
+// Package summary builds extractive summaries: it picks the sentences of a
+// text that best cover its frequent terms, without any external service.
+package summary
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"tgbot/internal/filter"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// minTermLength skips short words, which are mostly stop words.
+	minTermLength = 4
+	// Sentences outside these word counts are captions, bylines or run-ons.
+	minSentenceWords = 6
+	maxSentenceWords = 60
+	// titleWeight and positionWeight add to a sentence's term score for
+	// sharing terms with the title and for coming early in the text.
+	titleWeight    = 0.5
+	positionWeight = 0.3
+)
+
+// stopWords are frequent words long enough to pass minTermLength.
+var stopWords = toSet(
+	"that", "this", "with", "from", "have", "were", "been", "they", "their", "there", "which", "would",
+	"could", "about", "after", "also", "than", "then", "when", "what", "will", "into", "more", "said", "says",
+	"это", "этот", "эта", "эти", "того", "чтобы", "также", "было", "были", "была", "будет", "который",
+	"которые", "которая", "после", "более", "очень", "может", "если", "уже", "когда", "всего", "сказал",
+)
+
+func toSet(words ...string) map[string]bool {
+	set := make(map[string]bool, len(words))
+	for _, w := range words {
+		set[w] = true
+	}
+	return set
+}
+
+type sentence struct {
+	text  string
+	index int
+	terms []string
+	score float64
+}
+
+// Summarize returns up to n sentences of the paragraphs that best summarise
+// them, in their original order. Terms of the title weigh more. It returns
+// an empty string if there are no usable sentences.
+func Summarize(title string, paragraphs []string, n int) string {
+	var sentences []sentence
+	freq := make(map[string]int)
+	index := 0
+	for _, paragraph := range paragraphs {
+		for _, s := range Sentences(paragraph) {
+			index++
+			words := filter.Words(s)
+			if len(words) < minSentenceWords || len(words) > maxSentenceWords {
+				continue
+			}
+			terms := contentTerms(words)
+			for _, term := range terms {
+				freq[term]++
+			}
+			sentences = append(sentences, sentence{text: s, index: index, terms: terms})
+		}
+	}
+	if len(sentences) == 0 {
+		return ""
+	}
+
+	maxFreq := 0
+	for _, f := range freq {
+		maxFreq = max(maxFreq, f)
+	}
+	titleTerms := toSet(contentTerms(filter.Words(title))...)
+	for i := range sentences {
+		s := &sentences[i]
+		if len(s.terms) == 0 {
+			continue
+		}
+		var sum float64
+		shared := toSet()
+		for _, term := range s.terms {
+			sum += float64(freq[term]) / float64(maxFreq)
+			if titleTerms[term] {
+				shared[term] = true
+			}
+		}
+		// Dividing by the root of the length favours neither long nor short
+		// sentences too much.
+		s.score = sum / math.Sqrt(float64(len(s.terms)))
+		if len(titleTerms) > 0 {
+			s.score += titleWeight * float64(len(shared)) / float64(len(titleTerms))
+		}
+		s.score += positionWeight * (1 - float64(i)/float64(len(sentences)))
+	}
+
+	sort.SliceStable(sentences, func(i, j int) bool { return sentences[i].score > sentences[j].score })
+	sentences = sentences[:min(n, len(sentences))]
+	sort.Slice(sentences, func(i, j int) bool { return sentences[i].index < sentences[j].index })
+
+	texts := make([]string, len(sentences))
+	for i, s := range sentences {
+		texts[i] = s.text
+	}
+	return strings.Join(texts, " ")
+}
+
+func contentTerms(words []string) []string {
+	var terms []string
+	for _, w := range words {
+		if utf8.RuneCountInString(w) >= minTermLength && !stopWords[w] {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// Sentences splits text into sentences. A sentence ends at ., !, ? or …,
+// optionally followed by closing quotes or brackets, when the next word
+// starts with a capital letter, a digit or an opening quote and the period
+// doesn't end an initial.
+func Sentences(text string) []string {
+	var sentences []string
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if !strings.ContainsRune(".!?…", runes[i]) {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && strings.ContainsRune(".!?…\"'»”’)", runes[end]) {
+			end++
+		}
+		if end+1 < len(runes) && runes[end] == ' ' && startsSentence(runes[end+1]) && !initial(runes, i) {
+			sentences = append(sentences, string(runes[start:end]))
+			start = end + 1
+		}
+		i = end - 1
+	}
+	if rest := strings.TrimSpace(string(runes[start:])); rest != "" {
+		sentences = append(sentences, rest)
+	}
+	return sentences
+}
+
+// initial reports whether the period at i ends a single letter, as in
+// "J. Smith" or "U.S.", rather than a sentence.
+func initial(runes []rune, i int) bool {
+	return runes[i] == '.' && i >= 1 && unicode.IsLetter(runes[i-1]) &&
+		(i == 1 || runes[i-2] == ' ' || runes[i-2] == '.')
+}
+
+func startsSentence(r rune) bool {
+	return unicode.IsUpper(r) || unicode.IsDigit(r) || strings.ContainsRune("\"'«“—-", r)
+}
@@ -0,0 +1,79 @@
+// Package tracking signs the tokens of click-tracking links, so a link can
+// only record clicks for the user and article it was made for, and of
+// reader page links, so pages can't be requested for arbitrary articles.
+package tracking
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+)
+
+// macSize is the length of the truncated HMAC in a token. Ten bytes keep
+// tokens short while leaving forgery out of reach.
+const macSize = 10
+
+var ErrInvalidToken = errors.New("invalid tracking token")
+
+// Signer makes and checks tokens naming a user and an article.
+type Signer struct {
+	key []byte
+}
+
+func NewSigner(secret string) *Signer {
+	return &Signer{key: []byte(secret)}
+}
+
+// Sign returns a URL-safe token for the user and article.
+func (s *Signer) Sign(userID, articleID int64) string {
+	payload := binary.AppendVarint(nil, userID)
+	payload = binary.AppendVarint(payload, articleID)
+	return base64.RawURLEncoding.EncodeToString(append(payload, s.mac(payload)...))
+}
+
+// Verify returns the user and article of a token made by Sign.
+func (s *Signer) Verify(token string) (userID, articleID int64, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(data) <= macSize {
+		return 0, 0, ErrInvalidToken
+	}
+	payload, mac := data[:len(data)-macSize], data[len(data)-macSize:]
+	if !hmac.Equal(mac, s.mac(payload)) {
+		return 0, 0, ErrInvalidToken
+	}
+	userID, n := binary.Varint(payload)
+	if n <= 0 {
+		return 0, 0, ErrInvalidToken
+	}
+	articleID, m := binary.Varint(payload[n:])
+	if m <= 0 || n+m != len(payload) {
+		return 0, 0, ErrInvalidToken
+	}
+	return userID, articleID, nil
+}
+
+// SignArticle returns a URL-safe token for the article alone. It is a
+// token of user 0, which no chat has, so it can't pass for a click token.
+func (s *Signer) SignArticle(articleID int64) string {
+	return s.Sign(0, articleID)
+}
+
+// VerifyArticle returns the article of a token made by SignArticle.
+func (s *Signer) VerifyArticle(token string) (int64, error) {
+	userID, articleID, err := s.Verify(token)
+	if err != nil {
+		return 0, err
+	}
+	if userID != 0 {
+		return 0, ErrInvalidToken
+	}
+	return articleID, nil
+}
+
+func (s *Signer) mac(payload []byte) []byte {
+	h := hmac.New(sha256.New, s.key)
+	h.Write(payload)
+	return h.Sum(nil)[:macSize]
+}
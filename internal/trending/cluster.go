@@ -0,0 +1,137 @@
+// Package trending groups articles that cover the same story, so a story
+// many sources pick up at once can be told apart from routine news.
+package trending
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"tgbot/internal/entities"
+	"tgbot/internal/filter"
+	"unicode/utf8"
+)
+
+const (
+	// minTermLength skips short words, which are mostly stop words.
+	minTermLength = 4
+	// Two titles cover the same story if they share at least minShared
+	// terms making up at least minOverlap of the shorter title's terms.
+	minShared  = 2
+	minOverlap = 0.5
+)
+
+// Cluster is a story: articles with overlapping titles.
+type Cluster struct {
+	// Articles are in the order they were given, so the first one is the
+	// earliest report when the input is ordered by time.
+	Articles []entities.Article
+	// Sources are the distinct outlets covering the story.
+	Sources []string
+}
+
+// Lead returns the article that represents the story.
+func (c *Cluster) Lead() *entities.Article {
+	return &c.Articles[0]
+}
+
+// Score is the number of distinct sources covering the story.
+func (c *Cluster) Score() int {
+	return len(c.Sources)
+}
+
+// Detect clusters the articles, linking any two with similar titles, and
+// returns the clusters by descending score.
+func Detect(articles []entities.Article) []Cluster {
+	terms := make([]map[string]bool, len(articles))
+	for i := range articles {
+		terms[i] = titleTerms(articles[i].Title)
+	}
+
+	parent := make([]int, len(articles))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range articles {
+		for j := i + 1; j < len(articles); j++ {
+			if similar(terms[i], terms[j]) {
+				// The earlier article stays the root, and so the lead.
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	index := make(map[int]int)
+	var clusters []Cluster
+	var seen []map[string]bool
+	for i := range articles {
+		root := find(i)
+		n, ok := index[root]
+		if !ok {
+			n = len(clusters)
+			index[root] = n
+			clusters = append(clusters, Cluster{})
+			seen = append(seen, make(map[string]bool))
+		}
+		c := &clusters[n]
+		c.Articles = append(c.Articles, articles[i])
+		key, name := source(&articles[i])
+		if !seen[n][key] {
+			seen[n][key] = true
+			c.Sources = append(c.Sources, name)
+		}
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].Score() > clusters[j].Score()
+	})
+	return clusters
+}
+
+func titleTerms(title string) map[string]bool {
+	terms := make(map[string]bool)
+	for _, word := range filter.Words(title) {
+		if utf8.RuneCountInString(word) >= minTermLength {
+			terms[word] = true
+		}
+	}
+	return terms
+}
+
+func similar(a, b map[string]bool) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for term := range a {
+		if b[term] {
+			shared++
+		}
+	}
+	return shared >= minShared && float64(shared) >= minOverlap*float64(len(a))
+}
+
+// source returns the key an outlet is counted by and its display name.
+func source(article *entities.Article) (key, name string) {
+	name = article.SourceName
+	switch {
+	case article.SourceID != "":
+		key = article.SourceID
+	case article.SourceName != "":
+		key = strings.ToLower(article.SourceName)
+	default:
+		if u, err := url.Parse(article.URL); err == nil {
+			key = strings.TrimPrefix(u.Hostname(), "www.")
+		}
+	}
+	if name == "" {
+		name = key
+	}
+	return key, name
+}
@@ -0,0 +1,61 @@
+package usecases
+
+import (
+	"context"
+	"tgbot/internal/entities"
+	"tgbot/internal/repository"
+)
+
+type AdminUsecase struct {
+	userRepo     repository.UserRepository
+	statsRepo    StatsRepositoryInterface
+	deliveryRepo DeliveryHistoryInterface
+}
+
+func NewAdminUsecase(userRepo repository.UserRepository, statsRepo StatsRepositoryInterface, deliveryRepo DeliveryHistoryInterface) *AdminUsecase {
+	return &AdminUsecase{
+		userRepo:     userRepo,
+		statsRepo:    statsRepo,
+		deliveryRepo: deliveryRepo,
+	}
+}
+
+// SyncAdmins makes the configured admins the only users with the admin
+// role: the config is the one source of it, so users removed from it lose
+// the role even if they were removed while the bot was stopped.
+func (u *AdminUsecase) SyncAdmins(ctx context.Context, adminIDs []int64) error {
+	if err := u.userRepo.DemoteAdmins(ctx, adminIDs); err != nil {
+		return err
+	}
+	for _, id := range adminIDs {
+		if err := u.userRepo.SetRole(ctx, id, entities.RoleAdmin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *AdminUsecase) TouchUser(ctx context.Context, userID int64) (*entities.User, error) {
+	return u.userRepo.TouchUser(ctx, userID)
+}
+
+func (u *AdminUsecase) GetStats(ctx context.Context) (*entities.Stats, error) {
+	return u.statsRepo.GetStats(ctx)
+}
+
+func (u *AdminUsecase) SetBanned(ctx context.Context, userID int64, banned bool) error {
+	return u.userRepo.SetBanned(ctx, userID, banned)
+}
+
+func (u *AdminUsecase) GetBroadcastRecipients(ctx context.Context) ([]int64, error) {
+	return u.userRepo.GetActiveUserIDs(ctx)
+}
+
+func (u *AdminUsecase) ListUsers(ctx context.Context, search string, limit, offset int) ([]entities.User, error) {
+	return u.userRepo.ListUsers(ctx, search, limit, offset)
+}
+
+// GetDeliveries returns the delivery history, of one user if userID isn't 0.
+func (u *AdminUsecase) GetDeliveries(ctx context.Context, userID int64, limit int) ([]entities.Delivery, error) {
+	return u.deliveryRepo.GetDeliveries(ctx, userID, limit)
+}
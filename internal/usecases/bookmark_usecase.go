@@ -0,0 +1,53 @@
+package usecases
+
+import (
+	"context"
+	"tgbot/internal/entities"
+)
+
+// bookmarksPerPage is the number of bookmarks /saved shows at once.
+const bookmarksPerPage = 5
+
+type BookmarkUsecase struct {
+	repo BookmarkRepositoryInterface
+}
+
+func NewBookmarkUsecase(repo BookmarkRepositoryInterface) *BookmarkUsecase {
+	return &BookmarkUsecase{repo: repo}
+}
+
+// BookmarkPage is one page of a user's bookmarks. Page numbers start at 1.
+type BookmarkPage struct {
+	Bookmarks []entities.Bookmark
+	Page      int
+	Pages     int
+	Total     int
+}
+
+// Save bookmarks a stored article. It reports false if the user already
+// saved it.
+func (u *BookmarkUsecase) Save(ctx context.Context, userID, articleID int64) (bool, error) {
+	return u.repo.AddBookmark(ctx, userID, articleID)
+}
+
+func (u *BookmarkUsecase) Remove(ctx context.Context, userID, articleID int64) (bool, error) {
+	return u.repo.RemoveBookmark(ctx, userID, articleID)
+}
+
+// Page returns the given page of the user's bookmarks, newest first. Pages
+// out of range are clamped, so removing the last item of the last page
+// shows the page before it.
+func (u *BookmarkUsecase) Page(ctx context.Context, userID int64, page int) (*BookmarkPage, error) {
+	total, err := u.repo.CountBookmarks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	pages := max(1, (total+bookmarksPerPage-1)/bookmarksPerPage)
+	page = min(max(page, 1), pages)
+
+	bookmarks, err := u.repo.GetBookmarks(ctx, userID, bookmarksPerPage, (page-1)*bookmarksPerPage)
+	if err != nil {
+		return nil, err
+	}
+	return &BookmarkPage{Bookmarks: bookmarks, Page: page, Pages: pages, Total: total}, nil
+}
@@ -0,0 +1,114 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"tgbot/internal/entities"
+	"tgbot/internal/logging"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+func (u *BotUsecase) handleAdminCommand(ctx context.Context, chatID int64, command, args string) string {
+	switch command {
+	case "stats":
+		stats, err := u.adminUsecase.GetStats(ctx)
+		if err != nil {
+			return "Ошибка при получении статистики: " + err.Error()
+		}
+		return formatStats(stats, u.clickTracker != nil)
+	case "broadcast":
+		text := strings.TrimSpace(args)
+		if text == "" {
+			return "Пожалуйста, укажите текст рассылки (например, /broadcast Плановые работы в 22:00)."
+		}
+		recipients, err := u.adminUsecase.GetBroadcastRecipients(ctx)
+		if err != nil {
+			return "Ошибка при получении получателей: " + err.Error()
+		}
+		go u.broadcast(u.log(ctx), chatID, recipients, text)
+		return fmt.Sprintf("Рассылка запущена для %d пользователей.", len(recipients))
+	case "ban", "unban":
+		userID, err := strconv.ParseInt(strings.TrimSpace(args), 10, 64)
+		if err != nil {
+			return fmt.Sprintf("Пожалуйста, укажите Telegram ID пользователя (например, /%s 123456).", command)
+		}
+		banned := command == "ban"
+		if err := u.adminUsecase.SetBanned(ctx, userID, banned); err != nil {
+			return "Ошибка при изменении пользователя: " + err.Error()
+		}
+		if banned {
+			return fmt.Sprintf("Пользователь %d заблокирован.", userID)
+		}
+		return fmt.Sprintf("Пользователь %d разблокирован.", userID)
+	}
+	return unknownCommandText
+}
+
+// broadcast sends the text to every recipient through the bot's rate-limited
+// sender and reports the result back to the admin chat.
+func (u *BotUsecase) broadcast(logger *slog.Logger, adminChatID int64, recipients []int64, text string) {
+	ctx := logging.WithLogger(context.Background(), logger)
+	sent, failed := 0, 0
+	for _, userID := range recipients {
+		if _, err := u.send(tgbotapi.NewMessage(userID, text)); err != nil {
+			logger.Error("Error sending broadcast", "user_id", userID, "error", err)
+			u.dropChat(ctx, userID, err)
+			failed++
+			continue
+		}
+		sent++
+	}
+
+	report := tgbotapi.NewMessage(adminChatID, fmt.Sprintf("Рассылка завершена: отправлено %d, ошибок %d.", sent, failed))
+	if _, err := u.send(report); err != nil {
+		logger.Error("Error sending broadcast report", "error", err)
+	}
+}
+
+// formatStats renders the stats; the click-through reports are only shown
+// if click tracking is enabled.
+func formatStats(stats *entities.Stats, clicks bool) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Пользователи: %d\n", stats.Users)
+	fmt.Fprintf(&b, "Активные за сутки: %d\n", stats.ActiveUsers)
+	fmt.Fprintf(&b, "Заблокированы: %d\n", stats.BannedUsers)
+	fmt.Fprintf(&b, "Доставлено сегодня: %d\n", stats.DeliveriesToday)
+	b.WriteString("Подписки по категориям:")
+
+	categories := make([]string, 0, len(stats.SubscriptionsByCategory))
+	for category := range stats.SubscriptionsByCategory {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		fmt.Fprintf(&b, "\n%s: %d", category, stats.SubscriptionsByCategory[category])
+	}
+	if len(categories) == 0 {
+		b.WriteString(" нет")
+	}
+
+	if clicks {
+		writeClickThrough(&b, "Переходы за неделю по категориям:", stats.ClicksByCategory)
+		writeClickThrough(&b, "Переходы за неделю по источникам:", stats.ClicksBySource)
+	}
+	return b.String()
+}
+
+func writeClickThrough(b *strings.Builder, title string, report []entities.ClickThrough) {
+	b.WriteString("\n" + title)
+	for _, c := range report {
+		name := c.Name
+		if name == "" {
+			name = "(подписки на источники)"
+		}
+		fmt.Fprintf(b, "\n%s: %d из %d (%.1f%%)", name, c.Clicks, c.Deliveries, 100*c.Rate())
+	}
+	if len(report) == 0 {
+		b.WriteString(" нет")
+	}
+}
@@ -0,0 +1,126 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+// Callback data of the bookmark buttons, prefixed to the article ID or the
+// page. Telegram limits callback data to 64 bytes.
+const (
+	callbackBookmarks    = "bm:"
+	callbackSave         = callbackBookmarks + "save:"
+	callbackUnsave       = callbackBookmarks + "del:"
+	callbackBookmarkPage = callbackBookmarks + "page:"
+)
+
+func (u *BotUsecase) handleSaved(ctx context.Context, userID int64, args string) (string, *tgbotapi.InlineKeyboardMarkup) {
+	page := 1
+	if args != "" {
+		n, err := strconv.Atoi(strings.TrimSpace(args))
+		if err != nil {
+			return "Использование: /saved [страница]", nil
+		}
+		page = n
+	}
+	return u.savedPage(ctx, userID, page)
+}
+
+// savedPage renders a page of bookmarks with a remove button per item and
+// buttons to the neighbouring pages.
+func (u *BotUsecase) savedPage(ctx context.Context, userID int64, page int) (string, *tgbotapi.InlineKeyboardMarkup) {
+	result, err := u.bookmarkUsecase.Page(ctx, userID, page)
+	if err != nil {
+		return "Ошибка при получении сохранённых статей: " + err.Error(), nil
+	}
+	if result.Total == 0 {
+		return "У вас нет сохранённых статей. Нажмите «🔖 Сохранить» под новостью, чтобы добавить её сюда.", nil
+	}
+
+	lines := []string{fmt.Sprintf("*Сохранённые статьи* (страница %d из %d):", result.Page, result.Pages), ""}
+	var removeRow []tgbotapi.InlineKeyboardButton
+	for i, bookmark := range result.Bookmarks {
+		n := (result.Page-1)*bookmarksPerPage + i + 1
+		lines = append(lines, fmt.Sprintf("%d. [%s](%s)", n, bookmark.Article.Title, bookmark.Article.URL))
+		removeRow = append(removeRow, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("🗑 %d", n),
+			fmt.Sprintf("%s%d:%d", callbackUnsave, bookmark.Article.ID, result.Page),
+		))
+	}
+
+	rows := [][]tgbotapi.InlineKeyboardButton{removeRow}
+	var navRow []tgbotapi.InlineKeyboardButton
+	if result.Page > 1 {
+		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", callbackBookmarkPage+strconv.Itoa(result.Page-1)))
+	}
+	if result.Page < result.Pages {
+		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData("Вперёд ▶️", callbackBookmarkPage+strconv.Itoa(result.Page+1)))
+	}
+	if len(navRow) > 0 {
+		rows = append(rows, navRow)
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return strings.Join(lines, "\n"), &keyboard
+}
+
+// handleBookmarkCallback handles the bookmark buttons and returns the text
+// of the callback answer.
+func (u *BotUsecase) handleBookmarkCallback(ctx context.Context, query *tgbotapi.CallbackQuery) string {
+	if u.bookmarkUsecase == nil {
+		return ""
+	}
+	userID := query.From.ID
+	action, arg, _ := strings.Cut(strings.TrimPrefix(query.Data, callbackBookmarks), ":")
+
+	switch action {
+	case "save":
+		articleID, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil {
+			return ""
+		}
+		saved, err := u.bookmarkUsecase.Save(ctx, userID, articleID)
+		if err != nil {
+			u.log(ctx).Error("Error saving bookmark", "article_id", articleID, "error", err)
+			return "Не удалось сохранить статью, попробуйте позже."
+		}
+		if !saved {
+			return "Статья уже сохранена."
+		}
+		return "Сохранено. Список — /saved"
+	case "del":
+		idText, pageText, _ := strings.Cut(arg, ":")
+		articleID, err := strconv.ParseInt(idText, 10, 64)
+		if err != nil {
+			return ""
+		}
+		page, _ := strconv.Atoi(pageText)
+		if _, err := u.bookmarkUsecase.Remove(ctx, userID, articleID); err != nil {
+			u.log(ctx).Error("Error removing bookmark", "article_id", articleID, "error", err)
+			return "Не удалось удалить статью, попробуйте позже."
+		}
+		u.editSavedPage(ctx, query.Message, userID, page)
+		return "Удалено."
+	case "page":
+		page, _ := strconv.Atoi(arg)
+		u.editSavedPage(ctx, query.Message, userID, page)
+	}
+	return ""
+}
+
+// editSavedPage replaces the /saved message with the given page.
+func (u *BotUsecase) editSavedPage(ctx context.Context, message *tgbotapi.Message, userID int64, page int) {
+	if message == nil {
+		return
+	}
+	text, keyboard := u.savedPage(ctx, userID, page)
+	edit := tgbotapi.NewEditMessageText(message.Chat.ID, message.MessageID, text)
+	edit.ParseMode = "Markdown"
+	edit.ReplyMarkup = keyboard
+	if _, err := u.send(edit); err != nil {
+		u.log(ctx).Error("Error updating saved list", "error", err)
+	}
+}
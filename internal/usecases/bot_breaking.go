@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"tgbot/internal/entities"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+// breakingSourcesShown caps the sources named in a breaking-news alert.
+const breakingSourcesShown = 5
+
+const breakingUsage = "Использование: /breaking on|off — присылать срочные новости по вашим категориям сразу, как только их подхватывают многие источники."
+
+const breakingAdminUsage = "\n\nДля администраторов:\n" +
+	"/breaking threshold <category> [число|default] - Порог источников\n" +
+	"/breaking scores <category> - Оценки сюжетов за неделю"
+
+// breakingStory is the part of a breaking-news alert shared by all its
+// recipients.
+type breakingStory struct {
+	Sources []string `json:"sources"`
+}
+
+func (u *BotUsecase) handleBreaking(ctx context.Context, userID int64, admin bool, args string) string {
+	if u.breakingUsecase == nil {
+		return unknownCommandText
+	}
+	usage := breakingUsage
+	if admin {
+		usage += breakingAdminUsage
+	}
+
+	fields := strings.Fields(strings.ToLower(args))
+	if len(fields) == 0 {
+		enabled, err := u.breakingUsecase.HasAlerts(ctx, userID)
+		if err != nil {
+			return "Ошибка при получении настроек: " + err.Error()
+		}
+		status := "Срочные новости выключены."
+		if enabled {
+			status = "Срочные новости включены."
+		}
+		return status + "\n\n" + usage
+	}
+
+	switch fields[0] {
+	case "on", "off":
+		enabled := fields[0] == "on"
+		if err := u.breakingUsecase.SetAlerts(ctx, userID, enabled); err != nil {
+			return "Ошибка при сохранении настроек: " + err.Error()
+		}
+		if enabled {
+			return "Срочные новости включены: 🔴 Breaking приходят сразу по категориям, на которые вы подписаны."
+		}
+		return "Срочные новости выключены."
+	case "threshold":
+		if admin && len(fields) >= 2 {
+			return u.breakingThreshold(ctx, fields[1], fields[2:])
+		}
+	case "scores":
+		if admin && len(fields) == 2 {
+			return u.breakingScores(ctx, fields[1])
+		}
+	}
+	return usage
+}
+
+func (u *BotUsecase) breakingThreshold(ctx context.Context, category string, args []string) string {
+	if !contains(u.Categories(ctx), category) {
+		return fmt.Sprintf("Категория '%s' не найдена.", category)
+	}
+	if len(args) == 0 {
+		threshold, err := u.breakingUsecase.Threshold(ctx, category)
+		if err != nil {
+			return "Ошибка при получении порога: " + err.Error()
+		}
+		return fmt.Sprintf("Порог для категории '%s': сюжет срочный, если за %d ч. его подхватили не меньше %d источников.",
+			category, int(breakingWindow.Hours()), threshold)
+	}
+
+	threshold := 0
+	if args[0] != "default" {
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return "Порог должен быть числом или default."
+		}
+		threshold = n
+	}
+	if err := u.breakingUsecase.SetThreshold(ctx, category, threshold); err != nil {
+		return "Ошибка при изменении порога: " + err.Error()
+	}
+	if threshold == 0 {
+		return fmt.Sprintf("Для категории '%s' восстановлен порог по умолчанию (%d).", category, DefaultBreakingThreshold)
+	}
+	return fmt.Sprintf("Порог для категории '%s' установлен: %d.", category, threshold)
+}
+
+// breakingScores shows how many of the category's stories reached each
+// score, which tells how often a given threshold would have fired.
+func (u *BotUsecase) breakingScores(ctx context.Context, category string) string {
+	counts, err := u.breakingUsecase.ScoreCounts(ctx, category)
+	if err != nil {
+		return "Ошибка при получении оценок: " + err.Error()
+	}
+	threshold, err := u.breakingUsecase.Threshold(ctx, category)
+	if err != nil {
+		return "Ошибка при получении порога: " + err.Error()
+	}
+	if len(counts) == 0 {
+		return fmt.Sprintf("За неделю в категории '%s' не было сюжетов из нескольких источников.", category)
+	}
+
+	scores := make([]int, 0, len(counts))
+	for score := range counts {
+		scores = append(scores, score)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(scores)))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Сюжеты категории '%s' за неделю по числу источников (порог %d):", category, threshold)
+	atLeast := 0
+	for _, score := range scores {
+		atLeast += counts[score]
+		marker := ""
+		if score >= threshold {
+			marker = " 🔴"
+		}
+		fmt.Fprintf(&b, "\n%d: %d (не меньше %d: %d)%s", score, counts[score], score, atLeast, marker)
+	}
+	return b.String()
+}
+
+// checkBreaking looks for breaking news in the category's recent articles
+// and alerts the users who turned alerts on.
+func (u *BotUsecase) checkBreaking(ctx context.Context, category string, prefs func() recipientPrefs) {
+	stories, err := u.breakingUsecase.Detect(ctx, category)
+	if err != nil {
+		u.log(ctx).Error("Error detecting breaking news", "category", category, "error", err)
+	}
+	if len(stories) == 0 {
+		return
+	}
+	subscriptions, err := u.breakingUsecase.Recipients(ctx, category)
+	if err != nil {
+		u.log(ctx).Error("Error getting breaking news recipients", "category", category, "error", err)
+		return
+	}
+
+	for _, story := range stories {
+		u.log(ctx).Info("Breaking news", "category", category, "url", story.Lead().URL, "sources", story.Score())
+		batch := deliveryBatch{Category: category, Breaking: &breakingStory{Sources: story.Sources}}
+		for _, sub := range subscriptions {
+			batch.Recipients = append(batch.Recipients, recipient{UserID: sub.UserID, ThreadID: sub.ThreadID, Articles: []entities.Article{*story.Lead()}})
+		}
+		u.dispatch(ctx, prefs, batch)
+	}
+}
+
+func (u *BotUsecase) sendBreaking(ctx context.Context, userID int64, threadID int, category string, article *entities.Article, story *breakingStory, track bool) {
+	link := article.URL
+	if track && u.clickTracker != nil {
+		link = u.clickTracker.Link(userID, article)
+	}
+	var sources []string
+	for i, source := range story.Sources {
+		if i == breakingSourcesShown {
+			sources = append(sources, "…")
+			break
+		}
+		sources = append(sources, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, source))
+	}
+
+	msg := tgbotapi.NewMessage(userID, fmt.Sprintf("🔴 *Breaking*\n%s\nИсточников: %d (%s)",
+		formatArticle(article, link), len(story.Sources), strings.Join(sources, ", ")))
+	msg.ParseMode = "Markdown"
+	if keyboard := u.articleKeyboard(article); keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+
+	logger := u.log(ctx).With("chat_id", userID)
+	logger.Debug("Sending breaking news", "url", article.URL, u.textAttr(msg.Text))
+	if err := u.sendTo(msg, threadID); err != nil {
+		logger.Error("Error sending breaking news", "error", err)
+		u.dropChat(ctx, userID, err)
+		return
+	}
+	if u.deliveryRepo != nil {
+		if err := u.deliveryRepo.RecordDelivery(ctx, userID, article, category); err != nil {
+			logger.Error("Error recording delivery", "error", err)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"tgbot/internal/entities"
+)
+
+// displayLanguage is the language of category names shown to users.
+const displayLanguage = "ru"
+
+const categoryUsage = "Использование:\n" +
+	"/category list - Все категории\n" +
+	"/category add <slug> newsapi <категория NewsAPI>\n" +
+	"/category add <slug> keyword <поисковый запрос>\n" +
+	"/category add <slug> rss <url> [url...]\n" +
+	"/category name <slug> <язык> <название>\n" +
+	"/category order <slug> <номер>\n" +
+	"/category schedule <slug> <cron-выражение|@every 10m|default>\n" +
+	"/category enable|disable <slug>"
+
+func (u *BotUsecase) handleCategories(ctx context.Context) string {
+	if u.categoryUsecase == nil {
+		return "Доступные категории: " + strings.Join(u.Categories(ctx), ", ")
+	}
+	categories, err := u.categoryUsecase.ListCategories(ctx, false)
+	if err != nil {
+		return "Ошибка при получении категорий: " + err.Error()
+	}
+	if len(categories) == 0 {
+		return "Категории пока не настроены."
+	}
+	lines := []string{"Доступные категории:"}
+	for _, c := range categories {
+		line := c.Slug
+		if name := c.DisplayName(displayLanguage); name != c.Slug {
+			line += " — " + name
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (u *BotUsecase) handleCategoryAdmin(ctx context.Context, args string) string {
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return categoryUsage
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "list":
+		return u.listAllCategories(ctx)
+	case "add":
+		return u.addCategory(ctx, fields[1:])
+	case "enable", "disable":
+		if len(fields) != 2 {
+			return categoryUsage
+		}
+		enabled := strings.ToLower(fields[0]) == "enable"
+		return categoryUpdateText(fields[1], enabled, func() (bool, error) {
+			return u.categoryUsecase.SetEnabled(ctx, fields[1], enabled)
+		})
+	case "name":
+		if len(fields) < 4 {
+			return categoryUsage
+		}
+		name := strings.Join(fields[3:], " ")
+		return categoryUpdateText(fields[1], true, func() (bool, error) {
+			return u.categoryUsecase.SetName(ctx, fields[1], strings.ToLower(fields[2]), name)
+		})
+	case "order":
+		if len(fields) != 3 {
+			return categoryUsage
+		}
+		order, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return "Некорректный номер: " + fields[2]
+		}
+		return categoryUpdateText(fields[1], true, func() (bool, error) {
+			return u.categoryUsecase.SetSortOrder(ctx, fields[1], order)
+		})
+	case "schedule":
+		if len(fields) < 3 {
+			return categoryUsage
+		}
+		schedule := strings.Join(fields[2:], " ")
+		if schedule == "default" {
+			schedule = ""
+		}
+		return categoryUpdateText(fields[1], true, func() (bool, error) {
+			return u.categoryUsecase.SetSchedule(ctx, fields[1], schedule)
+		})
+	}
+	return categoryUsage
+}
+
+func (u *BotUsecase) addCategory(ctx context.Context, fields []string) string {
+	if len(fields) < 3 {
+		return categoryUsage
+	}
+	category := &entities.Category{
+		Slug:     strings.ToLower(fields[0]),
+		Provider: strings.ToLower(fields[1]),
+		Enabled:  true,
+	}
+	switch category.Provider {
+	case entities.ProviderNewsAPI:
+		category.NewsAPICategory = strings.ToLower(fields[2])
+	case entities.ProviderKeyword:
+		category.Keywords = strings.Join(fields[2:], " ")
+	case entities.ProviderRSS:
+		category.Feeds = fields[2:]
+	}
+	if err := u.categoryUsecase.SaveCategory(ctx, category); err != nil {
+		return "Ошибка при сохранении категории: " + err.Error()
+	}
+	return fmt.Sprintf("Категория '%s' сохранена.", category.Slug)
+}
+
+func (u *BotUsecase) listAllCategories(ctx context.Context) string {
+	categories, err := u.categoryUsecase.ListCategories(ctx, true)
+	if err != nil {
+		return "Ошибка при получении категорий: " + err.Error()
+	}
+	if len(categories) == 0 {
+		return "Категории пока не настроены."
+	}
+	lines := []string{"Категории:"}
+	for _, c := range categories {
+		state := "включена"
+		if !c.Enabled {
+			state = "отключена"
+		}
+		line := fmt.Sprintf("%d. %s (%s) — %s, %s", c.SortOrder, c.Slug, c.DisplayName(displayLanguage), formatProvider(c), state)
+		if c.Schedule != "" {
+			line += ", расписание: " + c.Schedule
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func formatProvider(c entities.Category) string {
+	switch c.Provider {
+	case entities.ProviderKeyword:
+		return "запрос: " + c.Keywords
+	case entities.ProviderRSS:
+		return fmt.Sprintf("RSS: %d лент", len(c.Feeds))
+	}
+	return "NewsAPI: " + c.NewsAPICategory
+}
+
+func categoryUpdateText(slug string, enabled bool, update func() (bool, error)) string {
+	found, err := update()
+	if err != nil {
+		return "Ошибка при изменении категории: " + err.Error()
+	}
+	if !found {
+		return fmt.Sprintf("Категория '%s' не найдена.", slug)
+	}
+	if !enabled {
+		return fmt.Sprintf("Категория '%s' отключена.", slug)
+	}
+	return fmt.Sprintf("Категория '%s' обновлена.", slug)
+}
@@ -0,0 +1,151 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+// chatSettingsCommands change a chat's subscriptions or settings, which in
+// groups only the group's administrators may do.
+var chatSettingsCommands = map[string]bool{
+	"add":           true,
+	"follow_source": true,
+	"mute_source":   true,
+	"filter":        true,
+	"breaking":      true,
+	"summary":       true,
+	"languages":     true,
+	"media":         true,
+	"privacy":       true,
+}
+
+// commandMessage returns the message of an update that may hold a command:
+// a message in a private chat or a group, or a channel post.
+func commandMessage(update tgbotapi.Update) *tgbotapi.Message {
+	if update.Message != nil {
+		return update.Message
+	}
+	return update.ChannelPost
+}
+
+// addressedToBot reports whether a command is meant for this bot. In groups
+// /cmd@otherbot is meant for another bot.
+func (u *BotUsecase) addressedToBot(message *tgbotapi.Message) bool {
+	_, username, found := strings.Cut(message.CommandWithAt(), "@")
+	return !found || strings.EqualFold(username, u.bot.Self().UserName)
+}
+
+func isGroup(chat *tgbotapi.Chat) bool {
+	return chat.IsGroup() || chat.IsSuperGroup()
+}
+
+// threadID returns the forum topic a message was sent in, or 0 for the
+// General topic and chats without topics. Only supergroups have topics.
+func (u *BotUsecase) threadID(message *tgbotapi.Message) int {
+	if !message.Chat.IsSuperGroup() {
+		return 0
+	}
+	return u.bot.MessageThreadID(message.Chat.ID, message.MessageID)
+}
+
+// threadGone reports whether Telegram refused a message because its forum
+// topic was deleted.
+func threadGone(err error) bool {
+	var tgErr *tgbotapi.Error
+	return errors.As(err, &tgErr) && strings.Contains(tgErr.Message, "thread not found")
+}
+
+// senderID returns who sent a message: the user, or the chat for channel
+// posts and messages of anonymous group admins.
+func senderID(message *tgbotapi.Message) int64 {
+	if message.SenderChat != nil {
+		return message.SenderChat.ID
+	}
+	if message.From != nil {
+		return message.From.ID
+	}
+	return message.Chat.ID
+}
+
+// callbackChatID returns the chat a button was pressed in.
+func callbackChatID(query *tgbotapi.CallbackQuery) int64 {
+	if query.Message != nil && query.Message.Chat != nil {
+		return query.Message.Chat.ID
+	}
+	return query.From.ID
+}
+
+// canConfigureChat reports whether the sender of a message may change the
+// chat's subscriptions: anyone in a private chat, and in groups only the
+// administrators, as getChatMember reports them.
+func (u *BotUsecase) canConfigureChat(ctx context.Context, message *tgbotapi.Message) bool {
+	chat := message.Chat
+	if !isGroup(chat) {
+		// Only a channel's administrators can post in it.
+		return true
+	}
+	// Anonymous admins send messages on behalf of the group itself.
+	if message.SenderChat != nil {
+		return message.SenderChat.ID == chat.ID
+	}
+	if message.From == nil {
+		return false
+	}
+	resp, err := u.bot.Request(tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: chat.ID, UserID: message.From.ID},
+	})
+	if err != nil {
+		u.log(ctx).Error("Error getting chat member", "user_id", message.From.ID, "error", err)
+		return false
+	}
+	var member tgbotapi.ChatMember
+	if err := json.Unmarshal(resp.Result, &member); err != nil {
+		u.log(ctx).Error("Error decoding chat member", "user_id", message.From.ID, "error", err)
+		return false
+	}
+	return member.IsCreator() || member.IsAdministrator()
+}
+
+// chatGone reports whether Telegram refused a message because the chat can't
+// get messages any more: the bot was blocked or removed from it, or the
+// group was upgraded to a supergroup with a new ID.
+func chatGone(err error) bool {
+	var tgErr *tgbotapi.Error
+	return errors.As(err, &tgErr) && (tgErr.Code == 403 || tgErr.MigrateToChatID != 0)
+}
+
+// dropChat stops deliveries to a chat Telegram refused a message for, and
+// reports whether it did. A migrated group's deliveries move to its
+// supergroup; other chats are skipped until a command is sent from them.
+func (u *BotUsecase) dropChat(ctx context.Context, chatID int64, err error) bool {
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return false
+	}
+	switch {
+	case tgErr.MigrateToChatID != 0:
+		u.migrateChat(ctx, chatID, tgErr.MigrateToChatID)
+	case tgErr.Code == 403:
+		u.log(ctx).Info("Chat is unreachable, stopping deliveries", "chat_id", chatID)
+		if err := u.subscriptionUsecase.SetUnreachable(ctx, chatID); err != nil {
+			u.log(ctx).Error("Error marking chat unreachable", "chat_id", chatID, "error", err)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// migrateChat moves a group's subscriptions and settings to the supergroup
+// it became. Telegram reports a migration both with an update and with an
+// error on the next message, so it may run twice.
+func (u *BotUsecase) migrateChat(ctx context.Context, fromID, toID int64) {
+	u.log(ctx).Info("Group became a supergroup", "chat_id", fromID, "new_chat_id", toID)
+	if err := u.subscriptionUsecase.MigrateChat(ctx, fromID, toID); err != nil {
+		u.log(ctx).Error("Error migrating chat", "chat_id", fromID, "new_chat_id", toID, "error", err)
+	}
+}
@@ -0,0 +1,60 @@
+package usecases
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"tgbot/internal/entities"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+// Callback data of the feedback buttons, prefixed to the article ID.
+const (
+	callbackFeedback = "fb:"
+	callbackVoteUp   = callbackFeedback + "up:"
+	callbackVoteDown = callbackFeedback + "down:"
+)
+
+// rankingCandidates is how many times the per-category limit is fetched when
+// articles are ranked per user, so the ranking has something to choose from.
+const rankingCandidates = 3
+
+// candidateLimit returns how many new articles to fetch per query.
+func (u *BotUsecase) candidateLimit() int {
+	limit := u.settings().articlesPerCategory
+	if u.feedbackUsecase != nil {
+		return limit * rankingCandidates
+	}
+	return limit
+}
+
+// handleFeedbackCallback records a 👍/👎 and returns the text of the
+// callback answer.
+func (u *BotUsecase) handleFeedbackCallback(ctx context.Context, query *tgbotapi.CallbackQuery) string {
+	if u.feedbackUsecase == nil {
+		return ""
+	}
+	action, arg, _ := strings.Cut(strings.TrimPrefix(query.Data, callbackFeedback), ":")
+	articleID, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil {
+		return ""
+	}
+	vote := entities.VoteUp
+	if action == "down" {
+		vote = entities.VoteDown
+	}
+
+	found, err := u.feedbackUsecase.Vote(ctx, callbackChatID(query), articleID, vote)
+	if err != nil {
+		u.log(ctx).Error("Error saving feedback", "article_id", articleID, "error", err)
+		return "Не удалось сохранить оценку, попробуйте позже."
+	}
+	if !found {
+		return "Статья больше недоступна."
+	}
+	if vote == entities.VoteUp {
+		return "Спасибо! Будем присылать больше похожих новостей."
+	}
+	return "Спасибо! Будем присылать меньше похожих новостей."
+}
@@ -0,0 +1,104 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"tgbot/internal/entities"
+)
+
+const filterUsage = "Использование:\n" +
+	"/filter add [category] include|exclude <выражение> - Добавить фильтр\n" +
+	"/filter list - Показать фильтры\n" +
+	"/filter remove <id> - Удалить фильтр\n" +
+	"Пример: /filter add exclude crypto OR celebrity, /filter add technology include AI"
+
+func (u *BotUsecase) handleFilter(ctx context.Context, userID int64, args string) string {
+	if u.filterUsecase == nil {
+		return unknownCommandText
+	}
+
+	fields := strings.Fields(args)
+	if len(fields) == 0 {
+		return filterUsage
+	}
+
+	switch strings.ToLower(fields[0]) {
+	case "add":
+		return u.addFilter(ctx, userID, fields[1:])
+	case "list":
+		return u.listFilters(ctx, userID)
+	case "remove":
+		if len(fields) != 2 {
+			return filterUsage
+		}
+		id, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return "Некорректный номер фильтра: " + fields[1]
+		}
+		removed, err := u.filterUsecase.RemoveFilter(ctx, userID, id)
+		if err != nil {
+			return "Ошибка при удалении фильтра: " + err.Error()
+		}
+		if !removed {
+			return fmt.Sprintf("Фильтр #%d не найден.", id)
+		}
+		return fmt.Sprintf("Фильтр #%d удалён.", id)
+	}
+	return filterUsage
+}
+
+func (u *BotUsecase) addFilter(ctx context.Context, userID int64, fields []string) string {
+	rule := &entities.FilterRule{UserID: userID}
+	if len(fields) > 0 && !isFilterKind(fields[0]) {
+		rule.Category = strings.ToLower(fields[0])
+		categories := u.Categories(ctx)
+		if !contains(categories, rule.Category) {
+			return fmt.Sprintf("Категория '%s' не поддерживается. Доступные категории: %s", rule.Category, strings.Join(categories, ", "))
+		}
+		fields = fields[1:]
+	}
+	if len(fields) < 2 || !isFilterKind(fields[0]) {
+		return filterUsage
+	}
+	rule.Kind = strings.ToLower(fields[0])
+	rule.Expression = strings.Join(fields[1:], " ")
+
+	if err := u.filterUsecase.AddFilter(ctx, rule); err != nil {
+		return "Ошибка в фильтре: " + err.Error()
+	}
+	return fmt.Sprintf("Фильтр #%d добавлен: %s", rule.ID, formatFilterRule(rule))
+}
+
+func (u *BotUsecase) listFilters(ctx context.Context, userID int64) string {
+	rules, err := u.filterUsecase.ListFilters(ctx, userID)
+	if err != nil {
+		return "Ошибка при получении фильтров: " + err.Error()
+	}
+	if len(rules) == 0 {
+		return "У вас нет фильтров."
+	}
+	lines := make([]string, len(rules))
+	for i, rule := range rules {
+		lines[i] = fmt.Sprintf("#%d %s", rule.ID, formatFilterRule(&rule))
+	}
+	return "Ваши фильтры:\n" + strings.Join(lines, "\n")
+}
+
+func formatFilterRule(rule *entities.FilterRule) string {
+	kind := "только"
+	if rule.Kind == entities.FilterExclude {
+		kind = "кроме"
+	}
+	scope := "все подписки"
+	if rule.Category != "" {
+		scope = rule.Category
+	}
+	return fmt.Sprintf("[%s] %s: %s", scope, kind, rule.Expression)
+}
+
+func isFilterKind(value string) bool {
+	value = strings.ToLower(value)
+	return value == entities.FilterInclude || value == entities.FilterExclude
+}
@@ -0,0 +1,287 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"tgbot/internal/entities"
+	"tgbot/internal/queue"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+// Kinds of the jobs the bot puts in the job queue.
+const (
+	JobFetchCategory = "fetch_category"
+	JobFetchSources  = "fetch_sources"
+	JobDeliverBatch  = "deliver_batch"
+)
+
+// deliverBatchSize caps the recipients of one deliver_batch job, which bounds
+// the messages resent if a worker dies halfway through a batch.
+const deliverBatchSize = 20
+
+type fetchCategoryPayload struct {
+	Category string `json:"category"`
+}
+
+// deliveryBatch is a set of fetched articles to send to their recipients.
+type deliveryBatch struct {
+	// Category is empty for followed sources.
+	Category string `json:"category"`
+	// NotifyEmpty tells recipients left without articles that there is no
+	// news.
+	NotifyEmpty bool `json:"notify_empty,omitempty"`
+	// Breaking is set for a breaking-news alert, whose recipients each get
+	// the story's lead article.
+	Breaking   *breakingStory `json:"breaking,omitempty"`
+	Recipients []recipient    `json:"recipients"`
+	// fresh is set if the articles come from GetNewArticles and still have
+	// to be claimed once picked.
+	fresh bool
+}
+
+type recipient struct {
+	UserID int64 `json:"user_id"`
+	// ThreadID is the forum topic the articles go to; 0 is General.
+	ThreadID int                `json:"thread_id,omitempty"`
+	Articles []entities.Article `json:"articles"`
+}
+
+// RegisterJobs registers the handlers of the bot's jobs with the worker.
+func (u *BotUsecase) RegisterJobs(w *queue.Worker) {
+	w.Handle(JobFetchCategory, func(ctx context.Context, payload json.RawMessage) error {
+		var p fetchCategoryPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return fmt.Errorf("decoding payload: %w", err)
+		}
+		return u.fetchCategory(ctx, p.Category)
+	})
+	w.Handle(JobFetchSources, func(ctx context.Context, payload json.RawMessage) error {
+		return u.fetchSources(ctx)
+	})
+	w.Handle(JobDeliverBatch, func(ctx context.Context, payload json.RawMessage) error {
+		var batch deliveryBatch
+		if err := json.Unmarshal(payload, &batch); err != nil {
+			return fmt.Errorf("decoding payload: %w", err)
+		}
+		// The articles are picked already, so only the preferences of how
+		// they are sent are needed, and only the recipients'.
+		userIDs := make([]int64, len(batch.Recipients))
+		for i, r := range batch.Recipients {
+			userIDs[i] = r.UserID
+		}
+		u.deliver(ctx, u.loadDeliveryPrefs(ctx, userIDs), batch)
+		return nil
+	})
+}
+
+func (u *BotUsecase) fetchCategory(ctx context.Context, category string) error {
+	return u.newsCycle(ctx, func(ctx context.Context, prefs func() recipientPrefs) error {
+		return u.sendCategories(ctx, category, prefs)
+	}, "category", category)
+}
+
+func (u *BotUsecase) fetchSources(ctx context.Context) error {
+	return u.newsCycle(ctx, u.sendFollowedSources, "sources", true)
+}
+
+// enqueueNewsCycle queues a fetch job for every category and the followed
+// sources.
+func (u *BotUsecase) enqueueNewsCycle(ctx context.Context) {
+	for _, category := range u.Categories(ctx) {
+		u.enqueueFetchCategory(ctx, category)
+	}
+	u.enqueueFetchSources(ctx)
+}
+
+// enqueueFetchCategory queues a fetch of the category unless one is already
+// queued or running, possibly by another replica.
+func (u *BotUsecase) enqueueFetchCategory(ctx context.Context, category string) {
+	if err := u.enqueue(ctx, JobFetchCategory, JobFetchCategory+":"+category, fetchCategoryPayload{Category: category}); err != nil {
+		u.log(ctx).Error("Error queuing category fetch", "category", category, "error", err)
+	}
+}
+
+func (u *BotUsecase) enqueueFetchSources(ctx context.Context) {
+	if u.sourceUsecase == nil {
+		return
+	}
+	if err := u.enqueue(ctx, JobFetchSources, JobFetchSources, struct{}{}); err != nil {
+		u.log(ctx).Error("Error queuing source fetch", "error", err)
+	}
+}
+
+func (u *BotUsecase) enqueue(ctx context.Context, kind, dedupeKey string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	queued, err := u.jobQueue.Enqueue(ctx, &entities.Job{Kind: kind, Payload: data, DedupeKey: dedupeKey})
+	if err != nil {
+		return err
+	}
+	if !queued {
+		u.log(ctx).Debug("Job already queued", "kind", kind, "dedupe_key", dedupeKey)
+	}
+	return nil
+}
+
+// dispatch picks the articles of each recipient, then sends the batch right
+// away, or queues it in deliver_batch jobs if there is a job queue. Fresh
+// articles are marked as sent in the same transaction that queues them.
+func (u *BotUsecase) dispatch(ctx context.Context, prefs func() recipientPrefs, batch deliveryBatch) {
+	batch, picked := u.pick(prefs(), batch)
+	switch {
+	case u.jobQueue != nil && batch.fresh:
+		err := u.jobQueue.EnqueueClaimed(ctx, picked, batch.Category, func(claimed []string) ([]*entities.Job, error) {
+			return deliveryJobs(keepClaimed(batch, claimed))
+		})
+		if err != nil {
+			// The articles stay unsent and are picked again next cycle.
+			u.log(ctx).Error("Error queuing delivery", "category", batch.Category, "error", err)
+		}
+	case u.jobQueue != nil:
+		jobs, err := deliveryJobs(batch)
+		if err != nil {
+			u.log(ctx).Error("Error encoding delivery", "category", batch.Category, "error", err)
+			return
+		}
+		for i, job := range jobs {
+			if _, err := u.jobQueue.Enqueue(ctx, job); err != nil {
+				u.log(ctx).Error("Error queuing delivery, sending directly", "category", batch.Category, "error", err)
+				u.deliver(ctx, prefs(), chunkAt(batch, i*deliverBatchSize))
+			}
+		}
+	case batch.fresh && len(picked) > 0:
+		claimed, err := u.newsUsecase.ClaimArticles(ctx, picked, batch.Category)
+		if err != nil {
+			u.log(ctx).Error("Error claiming articles", "category", batch.Category, "error", err)
+			return
+		}
+		urls := make([]string, len(claimed))
+		for i := range claimed {
+			urls[i] = claimed[i].URL
+		}
+		u.deliver(ctx, prefs(), keepClaimed(batch, urls))
+	default:
+		u.deliver(ctx, prefs(), batch)
+	}
+}
+
+// pick narrows each recipient's articles to those it gets: the ones that
+// pass its preferences, best ranked first, up to the per-category limit, or
+// for breaking news the lead article. It also returns every picked article
+// once, which are the only ones to mark as sent; the others stay candidates
+// for the next cycle.
+func (u *BotUsecase) pick(prefs recipientPrefs, batch deliveryBatch) (deliveryBatch, []entities.Article) {
+	limit := u.settings().articlesPerCategory
+	if batch.Breaking != nil {
+		limit = 1
+	}
+	recipients := make([]recipient, 0, len(batch.Recipients))
+	var picked []entities.Article
+	seen := make(map[string]bool)
+	for _, r := range batch.Recipients {
+		articles := prefs.articlesFor(r.UserID, batch.Category, r.Articles)
+		articles = prefs.rank(r.UserID, batch.Category, articles)
+		if len(articles) > limit {
+			articles = articles[:limit]
+		}
+		for _, article := range articles {
+			if !seen[article.URL] {
+				seen[article.URL] = true
+				picked = append(picked, article)
+			}
+		}
+		r.Articles = articles
+		recipients = append(recipients, r)
+	}
+	batch.Recipients = recipients
+	return batch, picked
+}
+
+// keepClaimed drops the articles this cycle didn't claim from the batch.
+// Recipients whose articles were all claimed by an overlapping cycle are
+// dropped too, while those who got none keep their no-news message.
+func keepClaimed(batch deliveryBatch, claimed []string) deliveryBatch {
+	ours := make(map[string]bool, len(claimed))
+	for _, url := range claimed {
+		ours[url] = true
+	}
+	recipients := make([]recipient, 0, len(batch.Recipients))
+	for _, r := range batch.Recipients {
+		if len(r.Articles) == 0 {
+			recipients = append(recipients, r)
+			continue
+		}
+		var articles []entities.Article
+		for _, article := range r.Articles {
+			if ours[article.URL] {
+				articles = append(articles, article)
+			}
+		}
+		if len(articles) > 0 {
+			r.Articles = articles
+			recipients = append(recipients, r)
+		}
+	}
+	batch.Recipients = recipients
+	return batch
+}
+
+// deliveryJobs splits the batch into deliver_batch jobs of up to
+// deliverBatchSize recipients.
+func deliveryJobs(batch deliveryBatch) ([]*entities.Job, error) {
+	var jobs []*entities.Job
+	for start := 0; start < len(batch.Recipients); start += deliverBatchSize {
+		data, err := json.Marshal(chunkAt(batch, start))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &entities.Job{Kind: JobDeliverBatch, Payload: data})
+	}
+	return jobs, nil
+}
+
+// chunkAt returns the batch with the deliverBatchSize recipients from start.
+func chunkAt(batch deliveryBatch, start int) deliveryBatch {
+	batch.Recipients = batch.Recipients[start:min(start+deliverBatchSize, len(batch.Recipients))]
+	return batch
+}
+
+// deliver sends each recipient its picked articles, with summaries for
+// recipients who asked for them.
+func (u *BotUsecase) deliver(ctx context.Context, prefs recipientPrefs, batch deliveryBatch) {
+	summaries := make(map[string]string)
+	for _, r := range batch.Recipients {
+		articles := r.Articles
+		if prefs.summaries[r.UserID] && len(articles) > 0 {
+			articles = u.withSummaries(ctx, articles, summaries)
+		}
+		if batch.Breaking != nil {
+			if len(articles) > 0 {
+				u.sendBreaking(ctx, r.UserID, r.ThreadID, batch.Category, &articles[0], batch.Breaking, prefs.track(r.UserID))
+			}
+			continue
+		}
+		if len(articles) == 0 {
+			if batch.NotifyEmpty {
+				u.sendNoNews(ctx, r.UserID, r.ThreadID, batch.Category)
+			}
+			continue
+		}
+		u.sendArticles(ctx, r.UserID, r.ThreadID, batch.Category, articles, prefs.track(r.UserID), prefs.mediaMode(r.UserID))
+	}
+}
+
+func (u *BotUsecase) sendNoNews(ctx context.Context, userID int64, threadID int, category string) {
+	msg := tgbotapi.NewMessage(userID, fmt.Sprintf("*Пока новых новостей нет* для категории %s.", category))
+	msg.ParseMode = "Markdown"
+	logger := u.log(ctx).With("chat_id", userID)
+	logger.Debug("Sending no-news message", u.textAttr(msg.Text))
+	if err := u.sendTo(msg, threadID); err != nil {
+		logger.Error("Error sending no-news message", "error", err)
+		u.dropChat(ctx, userID, err)
+	}
+}
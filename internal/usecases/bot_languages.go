@@ -0,0 +1,40 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+)
+
+const languagesUsage = "Использование: /languages ru,en — присылать новости только на этих языках, /languages all — на всех языках."
+
+func (u *BotUsecase) handleLanguages(ctx context.Context, userID int64, args string) string {
+	if u.languageUsecase == nil {
+		return unknownCommandText
+	}
+
+	args = strings.ToLower(strings.TrimSpace(args))
+	switch args {
+	case "":
+		languages, err := u.languageUsecase.GetLanguages(ctx, userID)
+		if err != nil {
+			return "Ошибка при получении настроек: " + err.Error()
+		}
+		status := "Вы получаете новости на всех языках."
+		if len(languages) > 0 {
+			status = "Вы получаете новости на языках: " + strings.Join(languages, ", ") + "."
+		}
+		return status + "\n\n" + languagesUsage
+	case "all":
+		if err := u.languageUsecase.SetLanguages(ctx, userID, nil); err != nil {
+			return "Ошибка при сохранении настроек: " + err.Error()
+		}
+		return "Готово: новости будут приходить на всех языках."
+	}
+
+	languages := strings.FieldsFunc(args, func(r rune) bool { return r == ',' || r == ' ' })
+	if err := u.languageUsecase.SetLanguages(ctx, userID, languages); err != nil {
+		return "Ошибка при сохранении настроек: " + err.Error()
+	}
+	return "Готово: новости будут приходить только на языках " + strings.Join(languages, ", ") +
+		". Статьи, язык которых определить не удалось, тоже будут приходить."
+}
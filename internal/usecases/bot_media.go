@@ -0,0 +1,194 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"tgbot/internal/entities"
+	"unicode/utf8"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+const (
+	// albumMax is the most photos Telegram puts in one album.
+	albumMax = 10
+	// captionLimit is Telegram's limit on photo captions in characters.
+	captionLimit = 1024
+)
+
+const mediaUsage = "Использование: /media preview — текст с большим превью, /media photo — фото с подписью (несколько новостей приходят альбомом), /media text — текст без превью."
+
+func (u *BotUsecase) handleMedia(ctx context.Context, userID int64, args string) string {
+	if u.mediaUsecase == nil {
+		return unknownCommandText
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(args))
+	if mode == "" {
+		current, err := u.mediaUsecase.GetMode(ctx, userID)
+		if err != nil {
+			return "Ошибка при получении настроек: " + err.Error()
+		}
+		return fmt.Sprintf("Сейчас новости приходят в режиме %s.\n\n%s", current, mediaUsage)
+	}
+	if err := u.mediaUsecase.SetMode(ctx, userID, mode); err != nil {
+		return "Ошибка при сохранении настроек: " + err.Error()
+	}
+	switch mode {
+	case entities.MediaPhoto:
+		return "Готово: новости будут приходить фотографиями с подписью. Если картинки нет или она не загрузилась, придёт обычный текст."
+	case entities.MediaText:
+		return "Готово: новости будут приходить текстом без превью."
+	}
+	return "Готово: новости будут приходить текстом с большим превью."
+}
+
+// sendArticle sends one article in the given media mode. Photos that
+// Telegram can't fetch fall back to text.
+func (u *BotUsecase) sendArticle(ctx context.Context, userID int64, threadID int, article *entities.Article, link, mode string) error {
+	logger := u.log(ctx).With("chat_id", userID)
+	keyboard := u.articleKeyboard(article)
+
+	if mode == entities.MediaPhoto && article.ImageURL != "" {
+		photo := tgbotapi.NewPhoto(userID, tgbotapi.FileURL(article.ImageURL))
+		photo.Caption = articleCaption(article, link)
+		photo.ParseMode = "Markdown"
+		if keyboard != nil {
+			photo.ReplyMarkup = keyboard
+		}
+		logger.Debug("Sending article photo", "url", article.URL, u.textAttr(photo.Caption))
+		err := u.sendTo(photo, threadID)
+		if err == nil || chatGone(err) {
+			return err
+		}
+		logger.Warn("Error sending article photo, sending text", "image_url", article.ImageURL, "error", err)
+	}
+
+	msg := tgbotapi.NewMessage(userID, formatArticle(article, link))
+	msg.ParseMode = "Markdown"
+	switch {
+	case mode == entities.MediaText:
+		msg.DisableWebPagePreview = true
+	case mode == entities.MediaPreview && previewable(article.ImageURL):
+		// Telegram previews the first link, and shows images large. The
+		// bot API version the client library speaks has no preview options.
+		msg.Text = "[\u200b](" + markdownURL.Replace(article.ImageURL) + ")" + msg.Text
+	}
+	if keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+	logger.Debug("Sending article", "url", article.URL, u.textAttr(msg.Text))
+	return u.sendTo(msg, threadID)
+}
+
+// sendAlbum sends the articles that have images as one album and reports
+// which it sent. Albums can't carry buttons, so the buttons of all its
+// articles follow in one message. Nothing is sent if fewer than two
+// articles have images, or if Telegram rejects the album, whose error is
+// returned.
+func (u *BotUsecase) sendAlbum(ctx context.Context, userID int64, threadID int, articles []entities.Article, links []string) (map[int]bool, error) {
+	var indexes []int
+	for i := range articles {
+		if articles[i].ImageURL != "" && len(indexes) < albumMax {
+			indexes = append(indexes, i)
+		}
+	}
+	if len(indexes) < 2 {
+		return nil, nil
+	}
+
+	logger := u.log(ctx).With("chat_id", userID)
+	media := make([]any, len(indexes))
+	var rows [][]tgbotapi.InlineKeyboardButton
+	for n, i := range indexes {
+		photo := tgbotapi.NewInputMediaPhoto(tgbotapi.FileURL(articles[i].ImageURL))
+		photo.Caption = fmt.Sprintf("%d. %s", n+1, articleCaption(&articles[i], links[i]))
+		photo.ParseMode = "Markdown"
+		media[n] = photo
+		if row := u.albumButtons(&articles[i], n+1); len(row) > 0 {
+			rows = append(rows, row)
+		}
+	}
+	logger.Debug("Sending article album", "photos", len(media))
+	if err := u.sendGroup(tgbotapi.NewMediaGroup(userID, media), threadID); err != nil {
+		logger.Warn("Error sending article album, sending articles one by one", "error", err)
+		return nil, err
+	}
+
+	if len(rows) > 0 {
+		msg := tgbotapi.NewMessage(userID, "Действия с новостями из альбома:")
+		keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+		msg.ReplyMarkup = &keyboard
+		if err := u.sendTo(msg, threadID); err != nil {
+			logger.Error("Error sending album buttons", "error", err)
+		}
+	}
+	sent := make(map[int]bool, len(indexes))
+	for _, i := range indexes {
+		sent[i] = true
+	}
+	return sent, nil
+}
+
+// albumButtons returns the buttons of an album article in one row, labelled
+// with its number in the album.
+func (u *BotUsecase) albumButtons(article *entities.Article, n int) []tgbotapi.InlineKeyboardButton {
+	if article.ID == 0 {
+		return nil
+	}
+	id := fmt.Sprint(article.ID)
+	var row []tgbotapi.InlineKeyboardButton
+	if u.feedbackUsecase != nil {
+		row = append(row,
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d 👍", n), callbackVoteUp+id),
+			tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d 👎", n), callbackVoteDown+id),
+		)
+	}
+	if u.bookmarkUsecase != nil {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d 🔖", n), callbackSave+id))
+	}
+	if u.readerUsecase != nil {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(fmt.Sprintf("%d 📄", n), callbackRead+id))
+	}
+	return row
+}
+
+// articleCaption formats the article for a photo caption, shortening the
+// description to fit Telegram's limit.
+func articleCaption(article *entities.Article, link string) string {
+	text := formatArticle(article, link)
+	// Leave room for the album number.
+	over := utf8.RuneCountInString(text) - (captionLimit - 5)
+	if over <= 0 {
+		return text
+	}
+	short := *article
+	description := []rune(short.Description)
+	short.Description = string(description[:max(0, len(description)-over-1)]) + "…"
+	return formatArticle(&short, link)
+}
+
+// previewable reports whether an image URL can go in a Markdown link.
+func previewable(imageURL string) bool {
+	return strings.HasPrefix(imageURL, "http") && !strings.ContainsAny(imageURL, " \n")
+}
+
+// markdownURL percent-encodes the characters that would end a Markdown link
+// or start an entity inside it; the encoded URL names the same resource.
+var markdownURL = strings.NewReplacer("(", "%28", ")", "%29", "_", "%5F", "*", "%2A", "`", "%60", "[", "%5B", "]", "%5D")
+
+// sendGroup sends an album, into the forum topic threadID like sendTo. Its
+// result is a list of messages, which Send can't decode.
+func (u *BotUsecase) sendGroup(group tgbotapi.MediaGroupConfig, threadID int) error {
+	if threadID != 0 {
+		_, err := u.bot.SendToThread(group, threadID)
+		u.metrics.MessageSent(sendResult(err))
+		if !threadGone(err) {
+			return err
+		}
+	}
+	_, err := u.bot.Request(group)
+	u.metrics.MessageSent(sendResult(err))
+	return err
+}
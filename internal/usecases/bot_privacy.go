@@ -0,0 +1,38 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+)
+
+const privacyUsage = "Использование: /privacy on — присылать обычные ссылки без учёта переходов, /privacy off — снова учитывать переходы."
+
+func (u *BotUsecase) handlePrivacy(ctx context.Context, userID int64, args string) string {
+	if u.clickTracker == nil {
+		return "Бот не отслеживает переходы по ссылкам."
+	}
+
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+		optedOut, err := u.clickTracker.IsOptedOut(ctx, userID)
+		if err != nil {
+			return "Ошибка при получении настроек: " + err.Error()
+		}
+		status := "Переходы по ссылкам в новостях учитываются: бот знает, какую статью и когда вы открыли. Эти данные используются только для общей статистики."
+		if optedOut {
+			status = "Переходы по ссылкам не учитываются: вы получаете прямые ссылки на статьи."
+		}
+		return status + "\n\n" + privacyUsage
+	case "on":
+		if err := u.clickTracker.SetOptOut(ctx, userID, true); err != nil {
+			return "Ошибка при сохранении настроек: " + err.Error()
+		}
+		return "Готово: новости будут приходить с прямыми ссылками, переходы не учитываются."
+	case "off":
+		if err := u.clickTracker.SetOptOut(ctx, userID, false); err != nil {
+			return "Ошибка при сохранении настроек: " + err.Error()
+		}
+		return "Готово: переходы по ссылкам снова учитываются в общей статистике."
+	}
+	return privacyUsage
+}
@@ -0,0 +1,146 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+)
+
+// Callback data of the reader buttons: the article ID, followed by the page
+// when turning pages of an open article.
+const callbackRead = "rd:"
+
+// readerPageSize is the length of a page of article text in runes, leaving
+// room for the title and page number under Telegram's 4096 limit.
+const readerPageSize = 3500
+
+// handleReadCallback sends the article's text, or turns the page of a
+// message sent earlier, and returns the text of the callback answer.
+func (u *BotUsecase) handleReadCallback(ctx context.Context, query *tgbotapi.CallbackQuery) string {
+	if u.readerUsecase == nil {
+		return ""
+	}
+	idText, pageText, turning := strings.Cut(strings.TrimPrefix(query.Data, callbackRead), ":")
+	articleID, err := strconv.ParseInt(idText, 10, 64)
+	if err != nil {
+		return ""
+	}
+	page := 1
+	if turning {
+		if page, err = strconv.Atoi(pageText); err != nil {
+			return ""
+		}
+	}
+
+	article, paragraphs, err := u.readerUsecase.Read(ctx, articleID)
+	if err != nil {
+		u.log(ctx).Warn("Error reading article", "article_id", articleID, "error", err)
+		return "Не удалось загрузить статью, попробуйте позже."
+	}
+	if article == nil {
+		return "Статья больше недоступна."
+	}
+	pages := readerPages(paragraphs)
+	if len(pages) == 0 {
+		return "Не удалось выделить текст статьи — откройте её по ссылке."
+	}
+	page = max(1, min(page, len(pages)))
+
+	text := fmt.Sprintf("%s\n\n%s", article.Title, pages[page-1])
+	if len(pages) > 1 {
+		text += fmt.Sprintf("\n\n— страница %d из %d", page, len(pages))
+	}
+	keyboard := u.readerKeyboard(articleID, page, len(pages))
+
+	// Article text is sent without formatting, as it may contain anything.
+	if turning && query.Message != nil {
+		edit := tgbotapi.NewEditMessageText(query.Message.Chat.ID, query.Message.MessageID, text)
+		edit.ReplyMarkup = keyboard
+		if _, err := u.send(edit); err != nil {
+			u.log(ctx).Error("Error turning article page", "error", err)
+		}
+		return ""
+	}
+	msg := tgbotapi.NewMessage(callbackChatID(query), text)
+	msg.DisableWebPagePreview = true
+	if query.Message != nil && isGroup(query.Message.Chat) {
+		msg.ReplyToMessageID = query.Message.MessageID
+		msg.AllowSendingWithoutReply = true
+	}
+	if keyboard != nil {
+		msg.ReplyMarkup = keyboard
+	}
+	if _, err := u.send(msg); err != nil {
+		u.log(ctx).Error("Error sending article text", "error", err)
+	}
+	return ""
+}
+
+// readerKeyboard returns the page buttons of an article's text and a link
+// to its reader page, or nil if there are none.
+func (u *BotUsecase) readerKeyboard(articleID int64, page, pages int) *tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+	var navRow []tgbotapi.InlineKeyboardButton
+	if page > 1 {
+		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData("◀️ Назад", fmt.Sprintf("%s%d:%d", callbackRead, articleID, page-1)))
+	}
+	if page < pages {
+		navRow = append(navRow, tgbotapi.NewInlineKeyboardButtonData("Вперёд ▶️", fmt.Sprintf("%s%d:%d", callbackRead, articleID, page+1)))
+	}
+	if len(navRow) > 0 {
+		rows = append(rows, navRow)
+	}
+	if link := u.readerUsecase.PageURL(articleID); link != "" {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonURL("🌐 Открыть страницей", link)))
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
+
+// readerPages splits the paragraphs into pages of at most readerPageSize
+// runes. Pages break between paragraphs; longer paragraphs break between
+// words.
+func readerPages(paragraphs []string) []string {
+	var pages []string
+	var page strings.Builder
+	size := 0
+	flush := func() {
+		if size > 0 {
+			pages = append(pages, page.String())
+			page.Reset()
+			size = 0
+		}
+	}
+	add := func(text string, sep string) {
+		n := utf8.RuneCountInString(text)
+		if size > 0 && size+len(sep)+n > readerPageSize {
+			flush()
+		}
+		if size > 0 {
+			page.WriteString(sep)
+			size += len(sep)
+		}
+		page.WriteString(text)
+		size += n
+	}
+	for _, paragraph := range paragraphs {
+		if utf8.RuneCountInString(paragraph) <= readerPageSize {
+			add(paragraph, "\n\n")
+			continue
+		}
+		flush()
+		for _, word := range strings.Fields(paragraph) {
+			add(word, " ")
+		}
+		flush()
+	}
+	flush()
+	return pages
+}
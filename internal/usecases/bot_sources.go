@@ -0,0 +1,143 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"tgbot/internal/entities"
+)
+
+const (
+	// newsAPIMaxSources is how many sources NewsAPI accepts in one request.
+	newsAPIMaxSources = 20
+	sourcesListLimit  = 50
+)
+
+func (u *BotUsecase) handleSources(ctx context.Context, args string) string {
+	if u.sourceUsecase == nil {
+		return unknownCommandText
+	}
+	sources, err := u.sourceUsecase.ListSources(ctx)
+	if err != nil {
+		return newsErrorText(err)
+	}
+
+	filter := strings.ToLower(strings.TrimSpace(args))
+	var lines []string
+	for _, s := range sources {
+		if filter != "" && s.Category != filter && s.Country != filter && s.Language != filter {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("%s — %s (%s, %s)", s.ID, s.Name, s.Category, s.Country))
+	}
+	if len(lines) == 0 {
+		return "Источники не найдены."
+	}
+
+	text := "Источники (используйте /follow_source <id> или /mute_source <id>):\n"
+	if len(lines) > sourcesListLimit {
+		text += strings.Join(lines[:sourcesListLimit], "\n")
+		text += fmt.Sprintf("\n…и ещё %d. Уточните запрос, например /sources technology или /sources us.", len(lines)-sourcesListLimit)
+		return text
+	}
+	return text + strings.Join(lines, "\n")
+}
+
+func (u *BotUsecase) handleFollowSource(ctx context.Context, userID int64, threadID int, args string) string {
+	if u.sourceUsecase == nil {
+		return unknownCommandText
+	}
+	sourceID := strings.TrimSpace(args)
+	if sourceID == "" {
+		return "Пожалуйста, укажите источник (например, /follow_source bbc-news). Список: /sources"
+	}
+	err := u.sourceUsecase.FollowSource(ctx, userID, sourceID, threadID)
+	if errors.Is(err, ErrUnknownSource) {
+		return fmt.Sprintf("Источник '%s' не найден. Список доступных источников: /sources", sourceID)
+	}
+	if err != nil {
+		return "Ошибка при подписке на источник: " + err.Error()
+	}
+	return fmt.Sprintf("Вы подписались на источник '%s'!", sourceID)
+}
+
+func (u *BotUsecase) handleMuteSource(ctx context.Context, userID int64, args string) string {
+	if u.sourceUsecase == nil {
+		return unknownCommandText
+	}
+	source := strings.TrimSpace(args)
+	if source == "" {
+		return "Пожалуйста, укажите источник (например, /mute_source bbc-news или /mute_source Daily Mail)."
+	}
+	if err := u.sourceUsecase.MuteSource(ctx, userID, source); err != nil {
+		return "Ошибка при отключении источника: " + err.Error()
+	}
+	return fmt.Sprintf("Новости источника '%s' больше не будут приходить.", source)
+}
+
+func (u *BotUsecase) followedSources(ctx context.Context, userID int64) []string {
+	if u.sourceUsecase == nil {
+		return nil
+	}
+	follows, err := u.sourceUsecase.GetFollowsByUser(ctx, userID)
+	if err != nil {
+		u.log(ctx).Error("Error getting followed sources", "user_id", userID, "error", err)
+		return nil
+	}
+	return follows
+}
+
+// sendFollowedSources delivers new articles of followed sources, fetching up
+// to newsAPIMaxSources sources per request.
+func (u *BotUsecase) sendFollowedSources(ctx context.Context, prefs func() recipientPrefs) error {
+	if u.sourceUsecase == nil {
+		return nil
+	}
+	follows, err := u.sourceUsecase.GetAllFollows(ctx)
+	if err != nil {
+		u.log(ctx).Error("Error getting source follows", "error", err)
+		return err
+	}
+
+	sourceFollows := make(map[string][]entities.SourceFollow)
+	var sourceIDs []string
+	for _, follow := range follows {
+		if _, ok := sourceFollows[follow.SourceID]; !ok {
+			sourceIDs = append(sourceIDs, follow.SourceID)
+		}
+		sourceFollows[follow.SourceID] = append(sourceFollows[follow.SourceID], follow)
+	}
+
+	limit := u.candidateLimit()
+	for start := 0; start < len(sourceIDs); start += newsAPIMaxSources {
+		end := min(start+newsAPIMaxSources, len(sourceIDs))
+		chunk := sourceIDs[start:end]
+
+		query := entities.NewsQuery{Sources: strings.Join(chunk, ",")}
+		articles, err := u.newsUsecase.GetNewArticles(ctx, query, limit*len(chunk))
+		if err != nil {
+			u.log(ctx).Error("Error getting news for sources", "sources", query.Sources, "error", err)
+			continue
+		}
+
+		// A chat may follow sources from different forum topics.
+		type topic struct {
+			userID   int64
+			threadID int
+		}
+		topicArticles := make(map[topic][]entities.Article)
+		for _, article := range articles {
+			for _, follow := range sourceFollows[article.SourceID] {
+				t := topic{follow.UserID, follow.ThreadID}
+				topicArticles[t] = append(topicArticles[t], article)
+			}
+		}
+		batch := deliveryBatch{fresh: true}
+		for t, articles := range topicArticles {
+			batch.Recipients = append(batch.Recipients, recipient{UserID: t.userID, ThreadID: t.threadID, Articles: articles})
+		}
+		u.dispatch(ctx, prefs, batch)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"tgbot/internal/entities"
+)
+
+const summaryUsage = "Использование: /summary on|off — заменять описание новости кратким пересказом статьи из 2–3 предложений."
+
+func (u *BotUsecase) handleSummary(ctx context.Context, userID int64, args string) string {
+	if u.summaryUsecase == nil {
+		return unknownCommandText
+	}
+
+	switch strings.ToLower(strings.TrimSpace(args)) {
+	case "":
+		enabled, err := u.summaryUsecase.IsEnabled(ctx, userID)
+		if err != nil {
+			return "Ошибка при получении настроек: " + err.Error()
+		}
+		status := "Краткий пересказ выключен: показывается описание от новостного сервиса."
+		if enabled {
+			status = "Краткий пересказ включён."
+		}
+		return status + "\n\n" + summaryUsage
+	case "on", "off":
+		enabled := strings.EqualFold(strings.TrimSpace(args), "on")
+		if err := u.summaryUsecase.SetEnabled(ctx, userID, enabled); err != nil {
+			return "Ошибка при сохранении настроек: " + err.Error()
+		}
+		if enabled {
+			return "Краткий пересказ включён: вместо описания новости будут приходить главные предложения статьи."
+		}
+		return "Краткий пересказ выключен."
+	}
+	return summaryUsage
+}
+
+// withSummaries returns the articles with their descriptions replaced by
+// summaries where one could be made. summaries caches the summaries of one
+// delivery by URL, as many recipients share articles.
+func (u *BotUsecase) withSummaries(ctx context.Context, articles []entities.Article, summaries map[string]string) []entities.Article {
+	result := make([]entities.Article, len(articles))
+	for i, article := range articles {
+		text, ok := summaries[article.URL]
+		if !ok {
+			var err error
+			text, err = u.summaryUsecase.Summarize(ctx, &article)
+			if err != nil {
+				u.log(ctx).Warn("Error summarizing article", "url", article.URL, "error", err)
+			}
+			summaries[article.URL] = text
+		}
+		if text != "" {
+			article.Description = text
+		}
+		result[i] = article
+	}
+	return result
+}
@@ -2,39 +2,293 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"tgbot/internal/entities"
+	"tgbot/internal/logging"
+	"tgbot/internal/metrics"
+	"tgbot/internal/scheduler"
+	"tgbot/internal/service"
 	"time"
 
 	tgbotapi "github.com/skinass/telegram-bot-api/v5"
 )
 
+const unknownCommandText = "Неизвестная команда. Используйте /help для списка команд."
+
+// knownCommands are the commands HandleCommand answers.
+var knownCommands = map[string]bool{
+	"start": true, "add": true, "news": true, "mysubs": true, "sources": true,
+	"follow_source": true, "mute_source": true, "filter": true, "breaking": true,
+	"summary": true, "languages": true, "media": true, "privacy": true, "saved": true,
+	"categories": true, "category": true, "stats": true, "broadcast": true, "ban": true,
+	"unban": true, "help": true, "keys": true,
+}
+
+const (
+	defaultNewsInterval        = 5 * time.Minute
+	defaultArticlesPerCategory = 5
+	// scheduleRefresh is how often the job list is rebuilt from the
+	// category catalogue and the settings.
+	scheduleRefresh = time.Minute
+)
+
 type BotUsecase struct {
 	bot                 BotAPIInterface
 	subscriptionUsecase SubscriptionUsecaseInterface
 	newsUsecase         NewsUsecaseInterface
+	keyStatus           KeyStatusProviderInterface
+	sourceUsecase       SourceUsecaseInterface
+	filterUsecase       FilterUsecaseInterface
+	adminUsecase        AdminUsecaseInterface
+	deliveryRepo        DeliveryRepositoryInterface
+	categoryUsecase     CategoryUsecaseInterface
+	bookmarkUsecase     BookmarkUsecaseInterface
+	feedbackUsecase     FeedbackUsecaseInterface
+	clickTracker        ClickTrackerInterface
+	breakingUsecase     BreakingUsecaseInterface
+	summaryUsecase      SummaryUsecaseInterface
+	readerUsecase       ReaderUsecaseInterface
+	languageUsecase     LanguageUsecaseInterface
+	mediaUsecase        MediaUsecaseInterface
+	metrics             metrics.Recorder
+	logger              *slog.Logger
+	logBodies           bool
+	scheduleStore       scheduler.LastRunStore
+	jobQueue            JobQueueInterface
+	scheduleJitter      time.Duration
+
+	// settingsMu guards the settings that can be reloaded at runtime.
+	settingsMu          sync.RWMutex
 	categories          []string
+	admins              map[int64]bool
+	newsInterval        time.Duration
+	articlesPerCategory int
+
+	// cycleMu keeps news cycles from overlapping.
+	cycleMu sync.Mutex
+	// lastCycle is the Unix time in nanoseconds at which the last news cycle
+	// completed.
+	lastCycle atomic.Int64
+}
+
+type BotOption func(*BotUsecase)
+
+// WithAdmins grants the given Telegram users access to admin commands.
+func WithAdmins(ids []int64) BotOption {
+	return func(u *BotUsecase) {
+		for _, id := range ids {
+			u.admins[id] = true
+		}
+	}
+}
+
+// WithKeyStatusProvider enables the admin /keys command.
+func WithKeyStatusProvider(provider KeyStatusProviderInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.keyStatus = provider
+	}
+}
+
+// WithSourceUsecase enables source follows and mutes.
+func WithSourceUsecase(sourceUsecase SourceUsecaseInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.sourceUsecase = sourceUsecase
+	}
+}
+
+// WithFilterUsecase enables per-user keyword filters.
+func WithFilterUsecase(filterUsecase FilterUsecaseInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.filterUsecase = filterUsecase
+	}
+}
+
+// WithAdminUsecase enables user tracking, bans and the admin commands
+// /stats, /broadcast, /ban and /unban.
+func WithAdminUsecase(adminUsecase AdminUsecaseInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.adminUsecase = adminUsecase
+	}
+}
+
+// WithDeliveryRecorder records every article delivered to a user.
+func WithDeliveryRecorder(repo DeliveryRepositoryInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.deliveryRepo = repo
+	}
+}
+
+// WithBotMetrics records commands, sent messages and news cycles.
+func WithBotMetrics(recorder metrics.Recorder) BotOption {
+	return func(u *BotUsecase) {
+		u.metrics = recorder
+	}
+}
+
+// WithLogger sets the base logger. Every update and news cycle gets a child
+// logger carrying its correlation attributes.
+func WithLogger(logger *slog.Logger) BotOption {
+	return func(u *BotUsecase) {
+		u.logger = logger
+	}
 }
 
-func NewBotUsecase(bot BotAPIInterface, subUsecase SubscriptionUsecaseInterface, newsUsecase NewsUsecaseInterface, categories []string) *BotUsecase {
-	return &BotUsecase{
+// WithMessageBodyLogging logs the text of sent messages instead of only
+// their length.
+func WithMessageBodyLogging(enabled bool) BotOption {
+	return func(u *BotUsecase) {
+		u.logBodies = enabled
+	}
+}
+
+// WithCategoryUsecase takes the categories from the catalogue instead of the
+// static list and enables the admin /category command.
+func WithCategoryUsecase(categoryUsecase CategoryUsecaseInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.categoryUsecase = categoryUsecase
+	}
+}
+
+// WithNewsInterval sets how often subscribers are sent new articles.
+func WithNewsInterval(interval time.Duration) BotOption {
+	return func(u *BotUsecase) {
+		u.newsInterval = interval
+	}
+}
+
+// WithBookmarkUsecase adds a Save button to article messages and the
+// /saved command.
+func WithBookmarkUsecase(bookmarkUsecase BookmarkUsecaseInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.bookmarkUsecase = bookmarkUsecase
+	}
+}
+
+// WithFeedbackUsecase adds 👍/👎 buttons to article messages and ranks each
+// user's articles by their votes. More articles are fetched per cycle so the
+// ranking has candidates to choose from.
+func WithFeedbackUsecase(feedbackUsecase FeedbackUsecaseInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.feedbackUsecase = feedbackUsecase
+	}
+}
+
+// WithClickTracking sends article links through the click-tracking redirect,
+// except to users who opted out with /privacy, and adds click-through
+// reports to /stats.
+func WithClickTracking(clickTracker ClickTrackerInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.clickTracker = clickTracker
+	}
+}
+
+// WithBreakingUsecase looks for breaking news after every category fetch
+// and enables /breaking.
+func WithBreakingUsecase(breakingUsecase BreakingUsecaseInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.breakingUsecase = breakingUsecase
+	}
+}
+
+// WithSummaryUsecase enables /summary, which replaces article descriptions
+// with summaries of the article pages.
+func WithSummaryUsecase(summaryUsecase SummaryUsecaseInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.summaryUsecase = summaryUsecase
+	}
+}
+
+// WithReaderUsecase adds a button under articles that sends the article's
+// text to the chat.
+func WithReaderUsecase(readerUsecase ReaderUsecaseInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.readerUsecase = readerUsecase
+	}
+}
+
+// WithLanguageUsecase enables /languages, which limits the user's
+// deliveries to articles in the languages they read.
+func WithLanguageUsecase(languageUsecase LanguageUsecaseInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.languageUsecase = languageUsecase
+	}
+}
+
+// WithMediaUsecase enables /media, which lets users choose between link
+// previews, photos and plain text.
+func WithMediaUsecase(mediaUsecase MediaUsecaseInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.mediaUsecase = mediaUsecase
+	}
+}
+
+// WithScheduleStore persists when each polling job last ran.
+func WithScheduleStore(store scheduler.LastRunStore) BotOption {
+	return func(u *BotUsecase) {
+		u.scheduleStore = store
+	}
+}
+
+// WithJobQueue moves fetching and delivery into the durable job queue: the
+// scheduler enqueues fetch jobs, which enqueue delivery batches. The jobs
+// are run by a worker the handlers are registered with by RegisterJobs.
+func WithJobQueue(queue JobQueueInterface) BotOption {
+	return func(u *BotUsecase) {
+		u.jobQueue = queue
+	}
+}
+
+// WithScheduleJitter spreads polling runs by up to jitter.
+func WithScheduleJitter(jitter time.Duration) BotOption {
+	return func(u *BotUsecase) {
+		u.scheduleJitter = jitter
+	}
+}
+
+// WithArticlesPerCategory caps the articles sent per category and cycle.
+func WithArticlesPerCategory(limit int) BotOption {
+	return func(u *BotUsecase) {
+		u.articlesPerCategory = limit
+	}
+}
+
+func NewBotUsecase(bot BotAPIInterface, subUsecase SubscriptionUsecaseInterface, newsUsecase NewsUsecaseInterface, categories []string, opts ...BotOption) *BotUsecase {
+	u := &BotUsecase{
 		bot:                 bot,
 		subscriptionUsecase: subUsecase,
 		newsUsecase:         newsUsecase,
 		categories:          categories,
+		admins:              make(map[int64]bool),
+		newsInterval:        defaultNewsInterval,
+		articlesPerCategory: defaultArticlesPerCategory,
+		metrics:             metrics.Nop{},
+		logger:              slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(u)
 	}
+	return u
 }
 
 type BotAPIInterface interface {
 	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error)
 	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
 	Self() tgbotapi.User
+	// SendToThread sends a message, photo or album into a forum topic.
+	SendToThread(c tgbotapi.Chattable, threadID int) (*tgbotapi.APIResponse, error)
+	// MessageThreadID returns the forum topic a command was sent in, or 0.
+	MessageThreadID(chatID int64, messageID int) int
 }
 
 func (u *BotUsecase) StartBot(ctx context.Context) {
-	log.Printf("Бот %s запущен!", u.bot.Self().UserName)
+	u.logger.Info("Бот запущен", "username", u.bot.Self().UserName)
 
 	go u.StartNewsChecker(ctx)
 
@@ -43,148 +297,670 @@ func (u *BotUsecase) StartBot(ctx context.Context) {
 	})
 
 	for update := range updates {
-		if update.Message == nil || !update.Message.IsCommand() {
+		if update.CallbackQuery != nil {
+			u.HandleCallback(ctx, update)
+			continue
+		}
+		if update.Message != nil && update.Message.MigrateToChatID != 0 {
+			u.migrateChat(ctx, update.Message.Chat.ID, update.Message.MigrateToChatID)
+			continue
+		}
+		if message := commandMessage(update); message == nil || !message.IsCommand() {
 			continue
 		}
 		u.HandleCommand(ctx, update)
 	}
 }
 
+// StartNewsChecker polls every category on its own schedule, and the
+// followed sources on the news interval, until ctx is cancelled.
 func (u *BotUsecase) StartNewsChecker(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
+	sched := scheduler.New(
+		scheduler.WithStore(u.scheduleStore),
+		scheduler.WithJitter(u.scheduleJitter),
+		scheduler.WithLogger(u.logger),
+	)
+	sync := func() {
+		if err := sched.Sync(u.scheduleJobs(ctx)); err != nil {
+			u.logger.Error("Invalid polling schedule", "error", err)
+		}
+	}
+	sync()
+	go sched.Run(ctx)
 
+	// Rebuilding the jobs picks up catalogue changes and reloaded settings.
+	ticker := time.NewTicker(scheduleRefresh)
+	defer ticker.Stop()
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("News checker stopped")
+			u.logger.Info("News checker stopped")
 			return
 		case <-ticker.C:
-			u.CheckAndSendNews(ctx)
+			sync()
+		}
+	}
+}
+
+// scheduleJobs returns a polling job per enabled category and one for the
+// followed sources.
+func (u *BotUsecase) scheduleJobs(ctx context.Context) []scheduler.Job {
+	interval := "@every " + u.settings().newsInterval.String()
+	var jobs []scheduler.Job
+	for _, c := range u.scheduledCategories(ctx) {
+		spec := c.Schedule
+		if spec == "" {
+			spec = interval
+		}
+		slug := c.Slug
+		jobs = append(jobs, scheduler.Job{
+			Name: "category:" + slug,
+			Spec: spec,
+			Run: func(ctx context.Context) {
+				if u.jobQueue != nil {
+					u.enqueueFetchCategory(ctx, slug)
+					return
+				}
+				u.fetchCategory(ctx, slug)
+			},
+		})
+	}
+	if u.sourceUsecase != nil {
+		jobs = append(jobs, scheduler.Job{
+			Name: "sources",
+			Spec: interval,
+			Run: func(ctx context.Context) {
+				if u.jobQueue != nil {
+					u.enqueueFetchSources(ctx)
+					return
+				}
+				u.fetchSources(ctx)
+			},
+		})
+	}
+	return jobs
+}
+
+func (u *BotUsecase) scheduledCategories(ctx context.Context) []entities.Category {
+	if u.categoryUsecase != nil {
+		categories, err := u.categoryUsecase.ListCategories(ctx, false)
+		if err == nil {
+			return categories
+		}
+		u.log(ctx).Error("Error getting categories, using the configured list", "error", err)
+	}
+	var categories []entities.Category
+	for _, slug := range u.settings().categories {
+		categories = append(categories, entities.Category{Slug: slug})
+	}
+	return categories
+}
+
+// BotSettings are the bot parameters that can change while it runs.
+type BotSettings struct {
+	Categories          []string
+	AdminIDs            []int64
+	NewsInterval        time.Duration
+	ArticlesPerCategory int
+}
+
+// UpdateSettings replaces the reloadable settings.
+func (u *BotUsecase) UpdateSettings(settings BotSettings) {
+	admins := make(map[int64]bool, len(settings.AdminIDs))
+	for _, id := range settings.AdminIDs {
+		admins[id] = true
+	}
+
+	u.settingsMu.Lock()
+	defer u.settingsMu.Unlock()
+	u.categories = settings.Categories
+	u.admins = admins
+	u.newsInterval = settings.NewsInterval
+	u.articlesPerCategory = settings.ArticlesPerCategory
+}
+
+type botSettings struct {
+	categories          []string
+	newsInterval        time.Duration
+	articlesPerCategory int
+}
+
+func (u *BotUsecase) settings() botSettings {
+	u.settingsMu.RLock()
+	defer u.settingsMu.RUnlock()
+	return botSettings{
+		categories:          u.categories,
+		newsInterval:        u.newsInterval,
+		articlesPerCategory: u.articlesPerCategory,
+	}
+}
+
+// Categories returns the news categories users can subscribe to.
+func (u *BotUsecase) Categories(ctx context.Context) []string {
+	if u.categoryUsecase != nil {
+		categories, err := u.categoryUsecase.ListCategories(ctx, false)
+		if err == nil {
+			slugs := make([]string, len(categories))
+			for i, c := range categories {
+				slugs[i] = c.Slug
+			}
+			return slugs
 		}
+		u.log(ctx).Error("Error getting categories, using the configured list", "error", err)
+	}
+	return u.settings().categories
+}
+
+// TriggerNewsCycle starts a CheckAndSendNews cycle in the background. It
+// returns false if a cycle is already running.
+func (u *BotUsecase) TriggerNewsCycle(ctx context.Context) bool {
+	if !u.cycleMu.TryLock() {
+		return false
 	}
+	go func() {
+		defer u.cycleMu.Unlock()
+		u.CheckAndSendNews(ctx)
+	}()
+	return true
 }
 
+// CheckAndSendNews runs a full news cycle: every category and the followed
+// sources. With a job queue the cycle is queued instead.
 func (u *BotUsecase) CheckAndSendNews(ctx context.Context) {
-	log.Println("Checking for new news...")
+	if u.jobQueue != nil {
+		u.enqueueNewsCycle(ctx)
+		return
+	}
+	u.newsCycle(ctx, func(ctx context.Context, prefs func() recipientPrefs) error {
+		u.sendCategories(ctx, "", prefs)
+		u.sendFollowedSources(ctx, prefs)
+		return nil
+	})
+}
 
+// newsCycle runs deliver with a cycle logger and records the cycle. attrs
+// are added to the logger. The recipient preferences are loaded the first
+// time deliver asks for them.
+func (u *BotUsecase) newsCycle(ctx context.Context, deliver func(context.Context, func() recipientPrefs) error, attrs ...any) error {
+	ctx = logging.WithLogger(ctx, u.log(ctx).With("cycle_id", logging.NewID()).With(attrs...))
+	u.log(ctx).Info("Checking for new news")
+	start := time.Now()
+	defer func() { u.metrics.NewsCycle(time.Since(start)) }()
+
+	prefs := sync.OnceValue(func() recipientPrefs { return u.loadRecipientPrefs(ctx) })
+	if err := deliver(ctx, prefs); err != nil {
+		return err
+	}
+	u.lastCycle.Store(time.Now().UnixNano())
+	return nil
+}
+
+// sendCategories sends new articles to the subscribers of the category only,
+// or of every category if only is empty.
+func (u *BotUsecase) sendCategories(ctx context.Context, only string, prefs func() recipientPrefs) error {
 	subscriptions, err := u.subscriptionUsecase.GetAllSubscriptions(ctx)
 	if err != nil {
-		log.Printf("Error getting subscriptions: %v", err)
-		return
+		u.log(ctx).Error("Error getting subscriptions", "error", err)
+		return err
 	}
 
-	categoryUsers := make(map[string][]int64)
+	limit := u.candidateLimit()
+	// Subscriptions to disabled or removed categories are paused.
+	categories := u.Categories(ctx)
+
+	querySubs := make(map[entities.NewsQuery][]entities.Subscription)
 	for _, sub := range subscriptions {
-		categoryUsers[sub.Category] = append(categoryUsers[sub.Category], sub.UserID)
+		if !contains(categories, sub.Category) || (only != "" && sub.Category != only) {
+			continue
+		}
+		querySubs[sub.Query()] = append(querySubs[sub.Query()], sub)
 	}
 
-	for category, userIDs := range categoryUsers {
-		articles, err := u.newsUsecase.GetNewArticles(ctx, category, 5)
+	fetched := make(map[string]bool)
+	for query, subs := range querySubs {
+		category := query.Category
+		articles, err := u.newsUsecase.GetNewArticles(ctx, query, limit)
 		if err != nil {
-			log.Printf("Error getting news for category %s: %v", category, err)
+			u.log(ctx).Error("Error getting news", "category", category, "error", err)
 			continue
 		}
+		fetched[category] = true
+
+		batch := deliveryBatch{Category: category, NotifyEmpty: true, fresh: true}
+		for _, sub := range subs {
+			batch.Recipients = append(batch.Recipients, recipient{UserID: sub.UserID, ThreadID: sub.ThreadID, Articles: articles})
+		}
+		u.dispatch(ctx, prefs, batch)
+	}
+
+	if u.breakingUsecase != nil {
+		for category := range fetched {
+			u.checkBreaking(ctx, category, prefs)
+		}
+	}
+	return nil
+}
+
+// LastNewsCycle returns when the last news cycle completed, or the zero time
+// if none has yet.
+func (u *BotUsecase) LastNewsCycle() time.Time {
+	nanos := u.lastCycle.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// sendArticles sends the articles in the user's media mode: in photo mode
+// the articles with images go together in an album, the others each in
+// their own message. With track set, links go through the click-tracking
+// redirect.
+func (u *BotUsecase) sendArticles(ctx context.Context, userID int64, threadID int, category string, articles []entities.Article, track bool, mode string) {
+	logger := u.log(ctx).With("chat_id", userID)
+	links := make([]string, len(articles))
+	for i := range articles {
+		links[i] = articles[i].URL
+		if track && u.clickTracker != nil {
+			links[i] = u.clickTracker.Link(userID, &articles[i])
+		}
+	}
 
-		for _, userID := range userIDs {
-			if len(articles) == 0 {
-				msg := tgbotapi.NewMessage(userID, fmt.Sprintf("*Пока новых новостей нет* для категории %s.", category))
-				msg.ParseMode = "Markdown"
-				fmt.Printf("Sending no-news message to user %d: %s\n", userID, msg.Text)
-				if _, err := u.bot.Send(msg); err != nil {
-					log.Printf("Error sending no-news message to user %d: %v", userID, err)
+	var inAlbum map[int]bool
+	if mode == entities.MediaPhoto {
+		var err error
+		if inAlbum, err = u.sendAlbum(ctx, userID, threadID, articles, links); u.dropChat(ctx, userID, err) {
+			return
+		}
+	}
+	for i := range articles {
+		article := &articles[i]
+		if !inAlbum[i] {
+			if err := u.sendArticle(ctx, userID, threadID, article, links[i], mode); err != nil {
+				logger.Error("Error sending news", "error", err)
+				if u.dropChat(ctx, userID, err) {
+					return
 				}
 				continue
 			}
-
-			for _, article := range articles {
-				msg := tgbotapi.NewMessage(userID, u.FormatArticle(&article))
-				msg.ParseMode = "Markdown"
-				fmt.Printf("Sending article to user %d: %s\n", userID, msg.Text)
-				if _, err := u.bot.Send(msg); err != nil {
-					log.Printf("Error sending news to user %d: %v", userID, err)
-				}
+		}
+		if u.deliveryRepo != nil {
+			if err := u.deliveryRepo.RecordDelivery(ctx, userID, article, category); err != nil {
+				logger.Error("Error recording delivery", "error", err)
 			}
 		}
 	}
 }
 
+// articleKeyboard returns the buttons shown under an article message, or nil
+// if there are none. The buttons refer to the stored article by ID.
+func (u *BotUsecase) articleKeyboard(article *entities.Article) *tgbotapi.InlineKeyboardMarkup {
+	if article.ID == 0 {
+		return nil
+	}
+	id := strconv.FormatInt(article.ID, 10)
+	var row []tgbotapi.InlineKeyboardButton
+	if u.feedbackUsecase != nil {
+		row = append(row,
+			tgbotapi.NewInlineKeyboardButtonData("👍", callbackVoteUp+id),
+			tgbotapi.NewInlineKeyboardButtonData("👎", callbackVoteDown+id),
+		)
+	}
+	if u.bookmarkUsecase != nil {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData("🔖 Сохранить", callbackSave+id))
+	}
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	if u.readerUsecase != nil {
+		rows = append(rows, tgbotapi.NewInlineKeyboardRow(tgbotapi.NewInlineKeyboardButtonData("📄 Читать здесь", callbackRead+id)))
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	keyboard := tgbotapi.NewInlineKeyboardMarkup(rows...)
+	return &keyboard
+}
+
+func (u *BotUsecase) log(ctx context.Context) *slog.Logger {
+	return logging.FromContext(ctx, u.logger)
+}
+
+// textAttr describes a message body for the log. The text itself is only
+// logged if body logging is enabled.
+func (u *BotUsecase) textAttr(text string) slog.Attr {
+	if u.logBodies {
+		return slog.String("text", text)
+	}
+	return slog.Int("text_len", len(text))
+}
+
+// send sends c and records the result.
+func (u *BotUsecase) send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	msg, err := u.bot.Send(c)
+	u.metrics.MessageSent(sendResult(err))
+	return msg, err
+}
+
+// sendTo sends c into the forum topic threadID, or like send if it is 0.
+// If the topic was deleted, c goes to General.
+func (u *BotUsecase) sendTo(c tgbotapi.Chattable, threadID int) error {
+	if threadID != 0 {
+		_, err := u.bot.SendToThread(c, threadID)
+		u.metrics.MessageSent(sendResult(err))
+		if !threadGone(err) {
+			return err
+		}
+	}
+	_, err := u.send(c)
+	return err
+}
+
+// sendResult classifies a Send error for metrics.
+func sendResult(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	var tgErr *tgbotapi.Error
+	if !errors.As(err, &tgErr) {
+		return "network"
+	}
+	switch {
+	case tgErr.RetryAfter > 0:
+		return "rate_limited"
+	case tgErr.Code == 403:
+		return "forbidden"
+	case tgErr.Code == 400:
+		return "bad_request"
+	}
+	return "api_error"
+}
+
 func (u *BotUsecase) FormatArticle(article *entities.Article) string {
-	return fmt.Sprintf("*%s*\n%s\n[Read more](%s)", article.Title, article.Description, article.URL)
+	return formatArticle(article, article.URL)
+}
+
+func formatArticle(article *entities.Article, link string) string {
+	text := fmt.Sprintf("*%s*\n%s\n", article.Title, article.Description)
+	// Some feeds put profile links instead of names in the author field.
+	if article.Author != "" && !strings.HasPrefix(article.Author, "http") {
+		text += "✍️ " + tgbotapi.EscapeText(tgbotapi.ModeMarkdown, article.Author) + "\n"
+	}
+	return text + fmt.Sprintf("[Read more](%s)", link)
 }
 
+// HandleCommand handles a command sent in a private chat, a group or a
+// channel. Subscriptions and settings belong to the chat the command was
+// sent in, bookmarks and admin rights to the sender.
 func (u *BotUsecase) HandleCommand(ctx context.Context, update tgbotapi.Update) {
-	msg := tgbotapi.NewMessage(update.Message.Chat.ID, "")
-	command := update.Message.Command()
-	args := update.Message.CommandArguments()
+	message := commandMessage(update)
+	if !u.addressedToBot(message) {
+		return
+	}
+	chatID := message.Chat.ID
+	msg := tgbotapi.NewMessage(chatID, "")
+	if isGroup(message.Chat) {
+		// A reply stays in the forum topic the command was sent from.
+		msg.ReplyToMessageID = message.MessageID
+		msg.AllowSendingWithoutReply = true
+	}
+	command := message.Command()
+	args := message.CommandArguments()
+
+	ctx = logging.WithLogger(ctx, u.logger.With("update_id", update.UpdateID, "chat_id", chatID))
+	u.log(ctx).Debug("Handling command", "command", command)
+
+	admin, banned := u.touchUser(ctx, senderID(message))
+	if banned {
+		if !knownCommands[command] {
+			// Like unknown commands below, to keep cardinality bounded.
+			command = "unknown"
+		}
+		u.metrics.CommandHandled(command, "banned")
+		return
+	}
+	if chatSettingsCommands[command] && !u.canConfigureChat(ctx, message) {
+		msg.Text = "Подписки и настройки этого чата могут менять только его администраторы."
+		if _, err := u.send(msg); err != nil {
+			u.log(ctx).Error("Error sending reply", "error", err)
+		}
+		u.metrics.CommandHandled(command, "forbidden")
+		return
+	}
 
 	switch command {
 	case "start":
 		msg.Text = "Здравствуйте! Данный бот предназначен для получения новостей. Используйте /add для подписки, /news <category> для получения новостей, /mysubs для просмотра подписок, /help для справки."
 	case "add":
 		if args == "" {
-			msg.Text = "Пожалуйста, укажите категорию (например, /add technology)."
+			msg.Text = "Пожалуйста, укажите категорию (например, /add technology или /add technology ru)."
 			break
 		}
-		category := strings.ToLower(strings.TrimSpace(args))
-		if !contains(u.categories, category) {
-			msg.Text = fmt.Sprintf("Категория '%s' не поддерживается. Доступные категории: %s", category, strings.Join(u.categories, ", "))
+		query, err := parseNewsQuery(args)
+		if err != nil {
+			msg.Text = "Ошибка: " + err.Error() + ". Формат: /add <category> [country] [language]."
+			break
+		}
+		category := query.Category
+		categories := u.Categories(ctx)
+		if !contains(categories, category) {
+			msg.Text = fmt.Sprintf("Категория '%s' не поддерживается. Доступные категории: %s", category, strings.Join(categories, ", "))
 			break
 		}
-		user := &entities.User{ID: update.Message.From.ID}
-		subscription := &entities.Subscription{UserID: user.ID, Category: category}
+		user := &entities.User{ID: chatID, ChatType: message.Chat.Type}
+		subscription := &entities.Subscription{UserID: user.ID, Category: category, Country: query.Country, Language: query.Language, ThreadID: u.threadID(message)}
 		if err := u.subscriptionUsecase.SaveSubscription(ctx, user, subscription); err != nil {
 			msg.Text = "Ошибка при добавлении подписки: " + err.Error()
 			break
 		}
-		msg.Text = fmt.Sprintf("Вы успешно подписались на категорию '%s'!", category)
+		msg.Text = fmt.Sprintf("Вы успешно подписались на категорию '%s'!", subscription.Label())
 	case "news":
 		if args == "" {
 			msg.Text = "Пожалуйста, укажите категорию (например, /news technology)."
 			break
 		}
-		category := strings.ToLower(strings.TrimSpace(args))
-		articles, err := u.newsUsecase.GetNewsByCategory(ctx, category)
+		query, err := parseNewsQuery(args)
 		if err != nil {
-			msg.Text = "Ошибка при получении новостей: " + err.Error()
+			msg.Text = "Ошибка: " + err.Error() + ". Формат: /news <category> [country] [language]."
+			break
+		}
+		category := query.Category
+		articles, err := u.newsUsecase.GetNews(ctx, query)
+		stale := errors.Is(err, ErrStaleNews)
+		if err != nil && !stale {
+			msg.Text = newsErrorText(err)
 			break
 		}
 		if len(articles) == 0 {
+			if stale {
+				msg.Text = "Новостной сервис временно недоступен, попробуйте позже."
+				break
+			}
 			msg.Text = fmt.Sprintf("Нет новостей для категории '%s'.", category)
 			break
 		}
 		msg.Text = ""
-		limit := len(articles)
-		if limit > 5 {
-			limit = 5
+		if stale && query.Country != "" {
+			msg.Text = "_Новостной сервис временно недоступен, показаны сохранённые новости — они могут быть неактуальны и не отфильтрованы по стране._\n\n"
+		} else if stale {
+			msg.Text = "_Новостной сервис временно недоступен, показаны сохранённые новости — они могут быть неактуальны._\n\n"
 		}
+		limit := min(len(articles), u.settings().articlesPerCategory)
 		for _, article := range articles[:limit] {
 			msg.Text += u.FormatArticle(&article) + "\n\n"
 		}
 		msg.ParseMode = "Markdown"
 	case "mysubs":
-		subscriptions, err := u.subscriptionUsecase.GetSubscriptionsByUser(ctx, update.Message.From.ID)
+		subscriptions, err := u.subscriptionUsecase.GetSubscriptionsByUser(ctx, chatID)
 		if err != nil {
 			msg.Text = "Ошибка при получении подписок: " + err.Error()
 			break
 		}
-		if len(subscriptions) == 0 {
+		follows := u.followedSources(ctx, chatID)
+		if len(subscriptions) == 0 && len(follows) == 0 {
 			msg.Text = "У вас нет активных подписок."
 			break
 		}
-		msg.Text = "Ваши подписки:\n" + strings.Join(subscriptions, "\n")
+		var sections []string
+		if len(subscriptions) > 0 {
+			sections = append(sections, "Ваши подписки:\n"+strings.Join(subscriptions, "\n"))
+		}
+		if len(follows) > 0 {
+			sections = append(sections, "Источники:\n"+strings.Join(follows, "\n"))
+		}
+		msg.Text = strings.Join(sections, "\n\n")
+	case "sources":
+		msg.Text = u.handleSources(ctx, args)
+	case "follow_source":
+		msg.Text = u.handleFollowSource(ctx, chatID, u.threadID(message), args)
+	case "mute_source":
+		msg.Text = u.handleMuteSource(ctx, chatID, args)
+	case "filter":
+		msg.Text = u.handleFilter(ctx, chatID, args)
+	case "breaking":
+		msg.Text = u.handleBreaking(ctx, chatID, admin, args)
+	case "summary":
+		msg.Text = u.handleSummary(ctx, chatID, args)
+	case "languages":
+		msg.Text = u.handleLanguages(ctx, chatID, args)
+	case "media":
+		msg.Text = u.handleMedia(ctx, chatID, args)
+	case "privacy":
+		msg.Text = u.handlePrivacy(ctx, chatID, args)
+	case "saved":
+		if u.bookmarkUsecase == nil {
+			msg.Text = unknownCommandText
+			break
+		}
+		text, keyboard := u.handleSaved(ctx, senderID(message), args)
+		msg.Text, msg.ParseMode = text, "Markdown"
+		if keyboard != nil {
+			msg.ReplyMarkup = keyboard
+		}
+	case "categories":
+		msg.Text = u.handleCategories(ctx)
+	case "category":
+		if !admin || u.categoryUsecase == nil {
+			msg.Text = unknownCommandText
+			break
+		}
+		msg.Text = u.handleCategoryAdmin(ctx, args)
+	case "stats", "broadcast", "ban", "unban":
+		if !admin || u.adminUsecase == nil {
+			msg.Text = unknownCommandText
+			break
+		}
+		msg.Text = u.handleAdminCommand(ctx, chatID, command, args)
 	case "help":
-		msg.Text = "Доступные команды:\n/start - Начать работу\n/add <category> - Подписаться на категорию\n/news <category> - Получить новости\n/mysubs - Показать подписки\n/help - Справка"
+		msg.Text = "Доступные команды:\n/start - Начать работу\n/add <category> [country] [language] - Подписаться на категорию\n/news <category> [country] [language] - Получить новости\n/mysubs - Показать подписки\n/categories - Список категорий\n/sources [category|country|language] - Каталог источников\n/follow_source <id> - Подписаться на источник\n/mute_source <id|name> - Не присылать новости источника\n/filter add|list|remove - Фильтры по ключевым словам\n/saved [страница] - Сохранённые статьи\n/breaking on|off - Срочные новости\n/summary on|off - Краткий пересказ статей\n/languages ru,en|all - Языки новостей\n/media preview|photo|text - Вид новостей\n/privacy [on|off] - Не отслеживать переходы по ссылкам\n/help - Справка"
+	case "keys":
+		if !admin || u.keyStatus == nil {
+			msg.Text = unknownCommandText
+			break
+		}
+		msg.Text = formatKeyStatuses(u.keyStatus.KeyStatuses(ctx))
 	default:
-		msg.Text = "Неизвестная команда. Используйте /help для списка команд."
+		msg.Text = unknownCommandText
 	}
 
+	outcome := "ok"
+	if msg.Text == unknownCommandText {
+		// Unknown names are folded into one label to keep cardinality bounded.
+		command, outcome = "unknown", "unknown"
+	}
 	if msg.Text != "" {
-		fmt.Printf("Sending message to chat %d: %s\n", msg.ChatID, msg.Text)
-		if _, err := u.bot.Send(msg); err != nil {
-			log.Printf("Error sending message to chat %d: %v", msg.ChatID, err)
+		u.log(ctx).Debug("Sending reply", u.textAttr(msg.Text))
+		if _, err := u.send(msg); err != nil {
+			u.log(ctx).Error("Error sending reply", "error", err)
+			outcome = "send_error"
+		}
+	}
+	u.metrics.CommandHandled(command, outcome)
+}
+
+// HandleCallback handles a press of an inline button.
+func (u *BotUsecase) HandleCallback(ctx context.Context, update tgbotapi.Update) {
+	query := update.CallbackQuery
+	ctx = logging.WithLogger(ctx, u.logger.With("update_id", update.UpdateID, "user_id", query.From.ID))
+	u.log(ctx).Debug("Handling callback", "data", query.Data)
+
+	var answer string
+	if _, banned := u.touchUser(ctx, query.From.ID); !banned {
+		switch {
+		case strings.HasPrefix(query.Data, callbackBookmarks):
+			answer = u.handleBookmarkCallback(ctx, query)
+		case strings.HasPrefix(query.Data, callbackFeedback):
+			answer = u.handleFeedbackCallback(ctx, query)
+		case strings.HasPrefix(query.Data, callbackRead):
+			answer = u.handleReadCallback(ctx, query)
+		default:
+			u.log(ctx).Warn("Unknown callback data", "data", query.Data)
+		}
+	}
+
+	// Every callback has to be answered, or the button keeps spinning.
+	if _, err := u.bot.Request(tgbotapi.NewCallback(query.ID, answer)); err != nil {
+		u.log(ctx).Error("Error answering callback", "error", err)
+	}
+}
+
+// touchUser records that the user is active and reports whether they are
+// an admin and whether they are banned.
+func (u *BotUsecase) touchUser(ctx context.Context, userID int64) (admin, banned bool) {
+	admin = u.isAdmin(userID)
+	if u.adminUsecase == nil {
+		return admin, false
+	}
+	user, err := u.adminUsecase.TouchUser(ctx, userID)
+	if err != nil {
+		u.log(ctx).Error("Error updating user", "user_id", userID, "error", err)
+		return admin, false
+	}
+	return admin || user.Role == entities.RoleAdmin, user.Banned
+}
+
+func (u *BotUsecase) isAdmin(userID int64) bool {
+	u.settingsMu.RLock()
+	defer u.settingsMu.RUnlock()
+	return u.admins[userID]
+}
+
+func formatKeyStatuses(statuses []service.KeyStatus) string {
+	if len(statuses) == 0 {
+		return "Ключи NewsAPI не настроены."
+	}
+	var b strings.Builder
+	b.WriteString("Ключи NewsAPI:")
+	for _, status := range statuses {
+		state := "активен"
+		if !status.DisabledUntil.IsZero() {
+			state = "отключён до " + status.DisabledUntil.Format("02.01 15:04 MST")
+		}
+		fmt.Fprintf(&b, "\n%s — %s, запросов сегодня: %d", status.ID, state, status.Requests)
+		if status.Remaining >= 0 {
+			fmt.Fprintf(&b, ", осталось: %d", status.Remaining)
+		}
+		if status.LastError != "" {
+			fmt.Fprintf(&b, ", последняя ошибка: %s", status.LastError)
 		}
 	}
+	return b.String()
+}
+
+func newsErrorText(err error) string {
+	switch {
+	case errors.Is(err, ErrUnknownCategory):
+		return "Категория не поддерживается. Список категорий: /categories"
+	case errors.Is(err, service.ErrCircuitOpen), errors.Is(err, service.ErrQuotaExceeded):
+		return "Новостной сервис временно недоступен, попробуйте позже."
+	case errors.Is(err, service.ErrRateLimited):
+		return "Превышен лимит запросов к новостному сервису, попробуйте позже."
+	case errors.Is(err, service.ErrParameterInvalid):
+		return "Некорректный запрос новостей. Проверьте название категории."
+	case errors.Is(err, service.ErrAPIKeyInvalid):
+		return "Новостной сервис временно недоступен."
+	}
+	return "Ошибка при получении новостей: " + err.Error()
 }
 
 func contains(slice []string, item string) bool {
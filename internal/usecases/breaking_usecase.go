@@ -0,0 +1,115 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"tgbot/internal/entities"
+	"tgbot/internal/trending"
+	"time"
+)
+
+const (
+	// DefaultBreakingThreshold is the number of sources a story needs within
+	// breakingWindow to be breaking news, unless its category sets another.
+	DefaultBreakingThreshold = 3
+	// breakingWindow is how recently a story's articles must have appeared.
+	breakingWindow = 2 * time.Hour
+	// scoreHistoryPeriod is the period /breaking scores reports on.
+	scoreHistoryPeriod = 7 * 24 * time.Hour
+)
+
+type BreakingUsecase struct {
+	repo BreakingRepositoryInterface
+	now  func() time.Time
+}
+
+func NewBreakingUsecase(repo BreakingRepositoryInterface) *BreakingUsecase {
+	return &BreakingUsecase{repo: repo, now: time.Now}
+}
+
+// Detect clusters the category's recent articles and returns the stories
+// that reached the category's threshold and weren't sent before, marking
+// them as sent. The score of every story with more than one source is
+// recorded.
+func (u *BreakingUsecase) Detect(ctx context.Context, category string) ([]trending.Cluster, error) {
+	articles, err := u.repo.GetRecentArticles(ctx, category, u.now().Add(-breakingWindow))
+	if err != nil {
+		return nil, fmt.Errorf("getting recent articles: %w", err)
+	}
+	threshold, err := u.Threshold(ctx, category)
+	if err != nil {
+		return nil, fmt.Errorf("getting threshold: %w", err)
+	}
+
+	var scores []entities.TrendScore
+	var candidates []trending.Cluster
+	for _, cluster := range trending.Detect(articles) {
+		if cluster.Score() < 2 {
+			break
+		}
+		scores = append(scores, entities.TrendScore{Category: category, ArticleID: cluster.Lead().ID, Score: cluster.Score()})
+		if cluster.Score() >= threshold {
+			candidates = append(candidates, cluster)
+		}
+	}
+	if len(scores) > 0 {
+		if err := u.repo.RecordScores(ctx, scores); err != nil {
+			return nil, fmt.Errorf("recording scores: %w", err)
+		}
+	}
+
+	var stories []trending.Cluster
+	for _, cluster := range candidates {
+		ids := make([]int64, len(cluster.Articles))
+		for i, article := range cluster.Articles {
+			ids[i] = article.ID
+		}
+		fresh, err := u.repo.MarkAlerted(ctx, ids)
+		if err != nil {
+			return stories, fmt.Errorf("marking story: %w", err)
+		}
+		if fresh {
+			stories = append(stories, cluster)
+		}
+	}
+	return stories, nil
+}
+
+// Threshold returns the number of sources that makes a story of the
+// category breaking news.
+func (u *BreakingUsecase) Threshold(ctx context.Context, category string) (int, error) {
+	threshold, err := u.repo.GetThreshold(ctx, category)
+	if err != nil || threshold == 0 {
+		return DefaultBreakingThreshold, err
+	}
+	return threshold, nil
+}
+
+// SetThreshold sets the category's threshold; 0 restores the default.
+func (u *BreakingUsecase) SetThreshold(ctx context.Context, category string, threshold int) error {
+	if threshold != 0 && threshold < 2 {
+		return errors.New("порог должен быть не меньше 2 источников")
+	}
+	return u.repo.SetThreshold(ctx, category, threshold)
+}
+
+// ScoreCounts counts the category's multi-source stories of the last week
+// by peak score.
+func (u *BreakingUsecase) ScoreCounts(ctx context.Context, category string) (map[int]int, error) {
+	return u.repo.GetScoreCounts(ctx, category, u.now().Add(-scoreHistoryPeriod))
+}
+
+func (u *BreakingUsecase) SetAlerts(ctx context.Context, userID int64, enabled bool) error {
+	return u.repo.SetBreakingAlerts(ctx, userID, enabled)
+}
+
+func (u *BreakingUsecase) HasAlerts(ctx context.Context, userID int64) (bool, error) {
+	return u.repo.HasBreakingAlerts(ctx, userID)
+}
+
+// Recipients returns the subscriptions to alert about breaking news of the
+// category.
+func (u *BreakingUsecase) Recipients(ctx context.Context, category string) ([]entities.Subscription, error) {
+	return u.repo.GetBreakingRecipients(ctx, category)
+}
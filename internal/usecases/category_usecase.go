@@ -0,0 +1,178 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sync"
+	"tgbot/internal/entities"
+	"tgbot/internal/scheduler"
+	"time"
+)
+
+// ErrUnknownCategory is returned when a slug is not an enabled category.
+var ErrUnknownCategory = errors.New("unknown category")
+
+// categoriesTTL bounds how long other replicas take to see catalogue
+// changes. Changes made through this usecase are visible immediately.
+const categoriesTTL = 30 * time.Second
+
+// minCategoryInterval is the shortest allowed gap between two polls of a
+// category.
+const minCategoryInterval = time.Minute
+
+var categorySlug = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+type CategoryUsecase struct {
+	repo CategoryRepositoryInterface
+
+	mu       sync.Mutex
+	cached   []entities.Category
+	loadedAt time.Time
+}
+
+func NewCategoryUsecase(repo CategoryRepositoryInterface) *CategoryUsecase {
+	return &CategoryUsecase{repo: repo}
+}
+
+// ListCategories returns the catalogue in display order.
+func (u *CategoryUsecase) ListCategories(ctx context.Context, includeDisabled bool) ([]entities.Category, error) {
+	all, err := u.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if includeDisabled {
+		return all, nil
+	}
+	var enabled []entities.Category
+	for _, c := range all {
+		if c.Enabled {
+			enabled = append(enabled, c)
+		}
+	}
+	return enabled, nil
+}
+
+// GetCategory returns the enabled category with the given slug or
+// ErrUnknownCategory.
+func (u *CategoryUsecase) GetCategory(ctx context.Context, slug string) (*entities.Category, error) {
+	all, err := u.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range all {
+		if c.Slug == slug && c.Enabled {
+			return &c, nil
+		}
+	}
+	return nil, ErrUnknownCategory
+}
+
+func (u *CategoryUsecase) SaveCategory(ctx context.Context, category *entities.Category) error {
+	if err := validateCategory(category); err != nil {
+		return err
+	}
+	defer u.invalidate()
+	return u.repo.SaveCategory(ctx, category)
+}
+
+// Seed adds NewsAPI-backed categories for slugs missing from the catalogue.
+func (u *CategoryUsecase) Seed(ctx context.Context, slugs []string) error {
+	defer u.invalidate()
+	return u.repo.SeedCategories(ctx, slugs)
+}
+
+func (u *CategoryUsecase) SetEnabled(ctx context.Context, slug string, enabled bool) (bool, error) {
+	defer u.invalidate()
+	return u.repo.SetEnabled(ctx, slug, enabled)
+}
+
+func (u *CategoryUsecase) SetName(ctx context.Context, slug, lang, name string) (bool, error) {
+	defer u.invalidate()
+	return u.repo.SetName(ctx, slug, lang, name)
+}
+
+func (u *CategoryUsecase) SetSortOrder(ctx context.Context, slug string, order int) (bool, error) {
+	defer u.invalidate()
+	return u.repo.SetSortOrder(ctx, slug, order)
+}
+
+// SetSchedule sets how often the category is polled; an empty schedule
+// restores the default news interval.
+func (u *CategoryUsecase) SetSchedule(ctx context.Context, slug, schedule string) (bool, error) {
+	if err := validateSchedule(schedule); err != nil {
+		return false, err
+	}
+	defer u.invalidate()
+	return u.repo.SetSchedule(ctx, slug, schedule)
+}
+
+func (u *CategoryUsecase) load(ctx context.Context) ([]entities.Category, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.cached != nil && time.Since(u.loadedAt) < categoriesTTL {
+		return u.cached, nil
+	}
+	categories, err := u.repo.GetCategories(ctx)
+	if err != nil {
+		if u.cached != nil {
+			return u.cached, nil
+		}
+		return nil, err
+	}
+	if categories == nil {
+		categories = []entities.Category{}
+	}
+	u.cached, u.loadedAt = categories, time.Now()
+	return categories, nil
+}
+
+func (u *CategoryUsecase) invalidate() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.cached = nil
+}
+
+func validateCategory(c *entities.Category) error {
+	if !categorySlug.MatchString(c.Slug) {
+		return fmt.Errorf("некорректный идентификатор категории %q: допустимы строчные латинские буквы, цифры, _ и -", c.Slug)
+	}
+	switch c.Provider {
+	case entities.ProviderNewsAPI:
+		if c.NewsAPICategory == "" {
+			return errors.New("не указана категория NewsAPI")
+		}
+	case entities.ProviderKeyword:
+		if c.Keywords == "" {
+			return errors.New("не указан поисковый запрос")
+		}
+	case entities.ProviderRSS:
+		if len(c.Feeds) == 0 {
+			return errors.New("не указаны RSS-ленты")
+		}
+		for _, feed := range c.Feeds {
+			if parsed, err := url.Parse(feed); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				return fmt.Errorf("некорректный адрес ленты %q", feed)
+			}
+		}
+	default:
+		return fmt.Errorf("неизвестный источник категории %q: ожидается newsapi, keyword или rss", c.Provider)
+	}
+	return validateSchedule(c.Schedule)
+}
+
+func validateSchedule(spec string) error {
+	if spec == "" {
+		return nil
+	}
+	schedule, err := scheduler.Parse(spec)
+	if err != nil {
+		return fmt.Errorf("некорректное расписание %q: %w", spec, err)
+	}
+	if scheduler.MinInterval(schedule, time.Now()) < minCategoryInterval {
+		return fmt.Errorf("расписание %q опрашивает категорию чаще раза в минуту", spec)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"tgbot/internal/entities"
+	"tgbot/internal/tracking"
+)
+
+type ClickUsecase struct {
+	repo       ClickRepositoryInterface
+	signer     *tracking.Signer
+	linkPrefix string
+}
+
+// NewClickUsecase makes tracked links by appending a signed token to
+// linkPrefix, the public URL of the redirect endpoint.
+func NewClickUsecase(repo ClickRepositoryInterface, signer *tracking.Signer, linkPrefix string) *ClickUsecase {
+	return &ClickUsecase{repo: repo, signer: signer, linkPrefix: linkPrefix}
+}
+
+// Link returns the tracked link to the article for the user, or the
+// article's own URL if it isn't stored.
+func (u *ClickUsecase) Link(userID int64, article *entities.Article) string {
+	if article.ID == 0 {
+		return article.URL
+	}
+	return u.linkPrefix + u.signer.Sign(userID, article.ID)
+}
+
+// Open records the click of a tracked link and returns the article's URL. It
+// reports false if the token is invalid or the article doesn't exist.
+func (u *ClickUsecase) Open(ctx context.Context, token string) (string, bool, error) {
+	userID, articleID, err := u.signer.Verify(token)
+	if errors.Is(err, tracking.ErrInvalidToken) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return u.repo.RecordClick(ctx, userID, articleID)
+}
+
+func (u *ClickUsecase) SetOptOut(ctx context.Context, userID int64, optOut bool) error {
+	return u.repo.SetTrackingOptOut(ctx, userID, optOut)
+}
+
+func (u *ClickUsecase) IsOptedOut(ctx context.Context, userID int64) (bool, error) {
+	return u.repo.IsTrackingOptOut(ctx, userID)
+}
+
+// GetOptOuts returns the users who get plain links, of userIDs only unless
+// it is nil.
+func (u *ClickUsecase) GetOptOuts(ctx context.Context, userIDs []int64) (map[int64]bool, error) {
+	return u.repo.GetTrackingOptOuts(ctx, userIDs)
+}
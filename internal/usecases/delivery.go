@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"context"
+	"tgbot/internal/entities"
+	"tgbot/internal/filter"
+	"tgbot/internal/ranking"
+)
+
+// recipientPrefs holds the per-user preferences applied during one
+// CheckAndSendNews cycle.
+type recipientPrefs struct {
+	muted   map[int64]map[string]bool
+	filters map[int64]*filter.RuleSet
+	models  map[int64]*ranking.Model
+	// tracking is set if links are tracked for every user but those in
+	// untracked, who opted out.
+	tracking  bool
+	untracked map[int64]bool
+	// summaries holds the users who get summaries instead of descriptions.
+	summaries map[int64]bool
+	// languages holds the accepted languages of the users who restricted
+	// them.
+	languages map[int64]map[string]bool
+	// media holds the media modes of the users who changed theirs.
+	media map[int64]string
+}
+
+func (u *BotUsecase) loadRecipientPrefs(ctx context.Context) recipientPrefs {
+	prefs := u.loadDeliveryPrefs(ctx, nil)
+	if u.sourceUsecase != nil {
+		muted, err := u.sourceUsecase.GetMutedSources(ctx)
+		if err != nil {
+			u.log(ctx).Error("Error getting muted sources", "error", err)
+		}
+		prefs.muted = muted
+	}
+	if u.filterUsecase != nil {
+		filters, err := u.filterUsecase.GetRuleSets(ctx)
+		if err != nil {
+			u.log(ctx).Error("Error getting filter rules", "error", err)
+		}
+		prefs.filters = filters
+	}
+	if u.feedbackUsecase != nil {
+		models, err := u.feedbackUsecase.GetModels(ctx)
+		if err != nil {
+			u.log(ctx).Error("Error getting ranking models", "error", err)
+		}
+		prefs.models = models
+	}
+	if u.languageUsecase != nil {
+		languages, err := u.languageUsecase.GetAccepted(ctx)
+		if err != nil {
+			u.log(ctx).Error("Error getting language settings", "error", err)
+		}
+		prefs.languages = languages
+	}
+	return prefs
+}
+
+// loadDeliveryPrefs loads only the preferences deliver applies to picked
+// articles, of userIDs only unless it is nil.
+func (u *BotUsecase) loadDeliveryPrefs(ctx context.Context, userIDs []int64) recipientPrefs {
+	var prefs recipientPrefs
+	if u.mediaUsecase != nil {
+		media, err := u.mediaUsecase.GetModes(ctx, userIDs)
+		if err != nil {
+			u.log(ctx).Error("Error getting media settings", "error", err)
+		}
+		prefs.media = media
+	}
+	if u.summaryUsecase != nil {
+		summaries, err := u.summaryUsecase.GetEnabledUsers(ctx, userIDs)
+		if err != nil {
+			u.log(ctx).Error("Error getting summary settings", "error", err)
+		}
+		prefs.summaries = summaries
+	}
+	if u.clickTracker != nil {
+		untracked, err := u.clickTracker.GetOptOuts(ctx, userIDs)
+		if err != nil {
+			// Without the opt-outs nobody's links are tracked.
+			u.log(ctx).Error("Error getting tracking opt-outs", "error", err)
+		}
+		prefs.tracking, prefs.untracked = err == nil, untracked
+	}
+	return prefs
+}
+
+// articlesFor returns the articles the user should receive for the category.
+func (p recipientPrefs) articlesFor(userID int64, category string, articles []entities.Article) []entities.Article {
+	muted := p.muted[userID]
+	rules := p.filters[userID]
+	languages := p.languages[userID]
+	if len(muted) == 0 && rules == nil && len(languages) == 0 {
+		return articles
+	}
+
+	filtered := make([]entities.Article, 0, len(articles))
+	for _, article := range articles {
+		if IsMuted(&article, muted) || !rules.Allow(category, &article) {
+			continue
+		}
+		// Articles of unknown language reach everyone rather than nobody.
+		if len(languages) > 0 && article.Language != "" && !languages[article.Language] {
+			continue
+		}
+		filtered = append(filtered, article)
+	}
+	return filtered
+}
+
+// rank orders the articles by the user's ranking model. Users without
+// feedback keep the given order.
+func (p recipientPrefs) rank(userID int64, category string, articles []entities.Article) []entities.Article {
+	model := p.models[userID]
+	if model == nil {
+		return articles
+	}
+	return model.Rank(category, articles)
+}
+
+// track reports whether the user's article links go through the
+// click-tracking redirect.
+func (p recipientPrefs) track(userID int64) bool {
+	return p.tracking && !p.untracked[userID]
+}
+
+// mediaMode returns how articles are shown to the user.
+func (p recipientPrefs) mediaMode(userID int64) string {
+	if mode := p.media[userID]; mode != "" {
+		return mode
+	}
+	return entities.MediaPreview
+}
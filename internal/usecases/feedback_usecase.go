@@ -0,0 +1,46 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"tgbot/internal/entities"
+	"tgbot/internal/ranking"
+)
+
+// feedbackPerUser bounds the votes a ranking model is trained on, so it
+// follows changing interests.
+const feedbackPerUser = 200
+
+type FeedbackUsecase struct {
+	repo FeedbackRepositoryInterface
+}
+
+func NewFeedbackUsecase(repo FeedbackRepositoryInterface) *FeedbackUsecase {
+	return &FeedbackUsecase{repo: repo}
+}
+
+// Vote records a 👍 (entities.VoteUp) or 👎 (entities.VoteDown) on a stored
+// article. It reports false if the article is unknown.
+func (u *FeedbackUsecase) Vote(ctx context.Context, userID, articleID int64, vote int) (bool, error) {
+	if vote != entities.VoteUp && vote != entities.VoteDown {
+		return false, fmt.Errorf("invalid vote %d", vote)
+	}
+	return u.repo.SaveFeedback(ctx, userID, articleID, vote)
+}
+
+// GetModels trains the ranking model of every user who voted.
+func (u *FeedbackUsecase) GetModels(ctx context.Context) (map[int64]*ranking.Model, error) {
+	feedback, err := u.repo.GetRecentFeedback(ctx, feedbackPerUser)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[int64][]entities.Feedback)
+	for _, f := range feedback {
+		byUser[f.UserID] = append(byUser[f.UserID], f)
+	}
+	models := make(map[int64]*ranking.Model, len(byUser))
+	for userID, userFeedback := range byUser {
+		models[userID] = ranking.NewModel(userFeedback)
+	}
+	return models, nil
+}
@@ -0,0 +1,56 @@
+package usecases
+
+import (
+	"context"
+	"tgbot/internal/entities"
+	"tgbot/internal/filter"
+	"tgbot/internal/repository"
+)
+
+type FilterUsecase struct {
+	userRepo   repository.UserRepository
+	filterRepo repository.FilterRepository
+}
+
+func NewFilterUsecase(userRepo repository.UserRepository, filterRepo repository.FilterRepository) *FilterUsecase {
+	return &FilterUsecase{
+		userRepo:   userRepo,
+		filterRepo: filterRepo,
+	}
+}
+
+// AddFilter validates the rule's expression and saves it.
+func (u *FilterUsecase) AddFilter(ctx context.Context, rule *entities.FilterRule) error {
+	if _, err := filter.Parse(rule.Expression); err != nil {
+		return err
+	}
+	if err := u.userRepo.SaveUser(ctx, &entities.User{ID: rule.UserID}); err != nil {
+		return err
+	}
+	return u.filterRepo.SaveFilter(ctx, rule)
+}
+
+func (u *FilterUsecase) ListFilters(ctx context.Context, userID int64) ([]entities.FilterRule, error) {
+	return u.filterRepo.GetFiltersByUser(ctx, userID)
+}
+
+func (u *FilterUsecase) RemoveFilter(ctx context.Context, userID int64, id int) (bool, error) {
+	return u.filterRepo.DeleteFilter(ctx, userID, id)
+}
+
+// GetRuleSets compiles the rules of every user.
+func (u *FilterUsecase) GetRuleSets(ctx context.Context) (map[int64]*filter.RuleSet, error) {
+	rules, err := u.filterRepo.GetAllFilters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[int64][]entities.FilterRule)
+	for _, rule := range rules {
+		byUser[rule.UserID] = append(byUser[rule.UserID], rule)
+	}
+	sets := make(map[int64]*filter.RuleSet, len(byUser))
+	for userID, userRules := range byUser {
+		sets[userID] = filter.NewRuleSet(userRules)
+	}
+	return sets, nil
+}
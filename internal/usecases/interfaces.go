@@ -3,24 +3,225 @@ package usecases
 import (
 	"context"
 	"tgbot/internal/entities"
+	"tgbot/internal/filter"
+	"tgbot/internal/ranking"
+	"tgbot/internal/service"
+	"tgbot/internal/trending"
+	"time"
 )
 
 type SubscriptionUsecaseInterface interface {
 	SaveSubscription(ctx context.Context, user *entities.User, subscription *entities.Subscription) error
 	GetSubscriptionsByUser(ctx context.Context, userID int64) ([]string, error)
 	GetAllSubscriptions(ctx context.Context) ([]entities.Subscription, error)
+	SetUnreachable(ctx context.Context, chatID int64) error
+	MigrateChat(ctx context.Context, fromID, toID int64) error
 }
 
 type NewsUsecaseInterface interface {
-	GetNewsByCategory(ctx context.Context, category string) ([]entities.Article, error)
-	GetNewArticles(ctx context.Context, category string, maxArticles int) ([]entities.Article, error)
+	GetNews(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error)
+	GetNewArticles(ctx context.Context, query entities.NewsQuery, maxArticles int) ([]entities.Article, error)
+	ClaimArticles(ctx context.Context, articles []entities.Article, category string) ([]entities.Article, error)
 }
 
 type NewsServiceInterface interface {
-	GetNewsByCategory(ctx context.Context, category string) ([]entities.Article, error)
+	GetNews(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error)
 }
 
 type SentArticlesRepositoryInterface interface {
 	IsArticleSent(ctx context.Context, url string) (bool, error)
-	SaveSentArticle(ctx context.Context, article *entities.Article, category string) error
+	ClaimArticles(ctx context.Context, articles []entities.Article, category string) ([]string, error)
+}
+
+type ArticleRepositoryInterface interface {
+	SaveArticles(ctx context.Context, category string, articles []entities.Article) error
+	GetLatestArticles(ctx context.Context, category, language string, limit int) ([]entities.Article, error)
+}
+
+type KeyStatusProviderInterface interface {
+	KeyStatuses(ctx context.Context) []service.KeyStatus
+}
+
+type SourceUsecaseInterface interface {
+	ListSources(ctx context.Context) ([]entities.Source, error)
+	FollowSource(ctx context.Context, userID int64, sourceID string, threadID int) error
+	MuteSource(ctx context.Context, userID int64, source string) error
+	GetFollowsByUser(ctx context.Context, userID int64) ([]string, error)
+	GetAllFollows(ctx context.Context) ([]entities.SourceFollow, error)
+	GetMutedSources(ctx context.Context) (map[int64]map[string]bool, error)
+}
+
+type SourceCatalogueInterface interface {
+	GetSources(ctx context.Context) ([]entities.Source, error)
+}
+
+type FilterUsecaseInterface interface {
+	AddFilter(ctx context.Context, rule *entities.FilterRule) error
+	ListFilters(ctx context.Context, userID int64) ([]entities.FilterRule, error)
+	RemoveFilter(ctx context.Context, userID int64, id int) (bool, error)
+	GetRuleSets(ctx context.Context) (map[int64]*filter.RuleSet, error)
+}
+
+type AdminUsecaseInterface interface {
+	TouchUser(ctx context.Context, userID int64) (*entities.User, error)
+	GetStats(ctx context.Context) (*entities.Stats, error)
+	SetBanned(ctx context.Context, userID int64, banned bool) error
+	GetBroadcastRecipients(ctx context.Context) ([]int64, error)
+}
+
+type StatsRepositoryInterface interface {
+	GetStats(ctx context.Context) (*entities.Stats, error)
+}
+
+type DeliveryRepositoryInterface interface {
+	RecordDelivery(ctx context.Context, userID int64, article *entities.Article, category string) error
+}
+
+type DeliveryHistoryInterface interface {
+	GetDeliveries(ctx context.Context, userID int64, limit int) ([]entities.Delivery, error)
+}
+
+type CategoryRepositoryInterface interface {
+	GetCategories(ctx context.Context) ([]entities.Category, error)
+	SaveCategory(ctx context.Context, category *entities.Category) error
+	SeedCategories(ctx context.Context, slugs []string) error
+	SetEnabled(ctx context.Context, slug string, enabled bool) (bool, error)
+	SetName(ctx context.Context, slug, lang, name string) (bool, error)
+	SetSortOrder(ctx context.Context, slug string, order int) (bool, error)
+	SetSchedule(ctx context.Context, slug, schedule string) (bool, error)
+}
+
+type CategoryCatalogueInterface interface {
+	GetCategory(ctx context.Context, slug string) (*entities.Category, error)
+}
+
+type CategoryUsecaseInterface interface {
+	CategoryCatalogueInterface
+	ListCategories(ctx context.Context, includeDisabled bool) ([]entities.Category, error)
+	SaveCategory(ctx context.Context, category *entities.Category) error
+	SetEnabled(ctx context.Context, slug string, enabled bool) (bool, error)
+	SetName(ctx context.Context, slug, lang, name string) (bool, error)
+	SetSortOrder(ctx context.Context, slug string, order int) (bool, error)
+	SetSchedule(ctx context.Context, slug, schedule string) (bool, error)
+}
+
+type FeedServiceInterface interface {
+	GetFeeds(ctx context.Context, urls []string) ([]entities.Article, error)
+}
+
+type JobQueueInterface interface {
+	Enqueue(ctx context.Context, job *entities.Job) (bool, error)
+	EnqueueClaimed(ctx context.Context, articles []entities.Article, category string, build func(claimed []string) ([]*entities.Job, error)) error
+}
+
+type BookmarkRepositoryInterface interface {
+	AddBookmark(ctx context.Context, userID, articleID int64) (bool, error)
+	RemoveBookmark(ctx context.Context, userID, articleID int64) (bool, error)
+	GetBookmarks(ctx context.Context, userID int64, limit, offset int) ([]entities.Bookmark, error)
+	CountBookmarks(ctx context.Context, userID int64) (int, error)
+}
+
+type BookmarkUsecaseInterface interface {
+	Save(ctx context.Context, userID, articleID int64) (bool, error)
+	Remove(ctx context.Context, userID, articleID int64) (bool, error)
+	Page(ctx context.Context, userID int64, page int) (*BookmarkPage, error)
+}
+
+type FeedbackRepositoryInterface interface {
+	SaveFeedback(ctx context.Context, userID, articleID int64, vote int) (bool, error)
+	GetRecentFeedback(ctx context.Context, perUser int) ([]entities.Feedback, error)
+}
+
+type FeedbackUsecaseInterface interface {
+	Vote(ctx context.Context, userID, articleID int64, vote int) (bool, error)
+	GetModels(ctx context.Context) (map[int64]*ranking.Model, error)
+}
+
+type ClickRepositoryInterface interface {
+	RecordClick(ctx context.Context, userID, articleID int64) (string, bool, error)
+	SetTrackingOptOut(ctx context.Context, userID int64, optOut bool) error
+	IsTrackingOptOut(ctx context.Context, userID int64) (bool, error)
+	GetTrackingOptOuts(ctx context.Context, userIDs []int64) (map[int64]bool, error)
+}
+
+type ClickTrackerInterface interface {
+	Link(userID int64, article *entities.Article) string
+	SetOptOut(ctx context.Context, userID int64, optOut bool) error
+	IsOptedOut(ctx context.Context, userID int64) (bool, error)
+	GetOptOuts(ctx context.Context, userIDs []int64) (map[int64]bool, error)
+}
+
+type BreakingRepositoryInterface interface {
+	GetRecentArticles(ctx context.Context, category string, since time.Time) ([]entities.Article, error)
+	RecordScores(ctx context.Context, scores []entities.TrendScore) error
+	GetScoreCounts(ctx context.Context, category string, since time.Time) (map[int]int, error)
+	MarkAlerted(ctx context.Context, articleIDs []int64) (bool, error)
+	GetThreshold(ctx context.Context, category string) (int, error)
+	SetThreshold(ctx context.Context, category string, threshold int) error
+	SetBreakingAlerts(ctx context.Context, userID int64, enabled bool) error
+	HasBreakingAlerts(ctx context.Context, userID int64) (bool, error)
+	GetBreakingRecipients(ctx context.Context, category string) ([]entities.Subscription, error)
+}
+
+type BreakingUsecaseInterface interface {
+	Detect(ctx context.Context, category string) ([]trending.Cluster, error)
+	Threshold(ctx context.Context, category string) (int, error)
+	SetThreshold(ctx context.Context, category string, threshold int) error
+	ScoreCounts(ctx context.Context, category string) (map[int]int, error)
+	SetAlerts(ctx context.Context, userID int64, enabled bool) error
+	HasAlerts(ctx context.Context, userID int64) (bool, error)
+	Recipients(ctx context.Context, category string) ([]entities.Subscription, error)
+}
+
+type PageFetcherInterface interface {
+	Fetch(ctx context.Context, url string) (string, error)
+}
+
+type SummaryRepositoryInterface interface {
+	GetSummary(ctx context.Context, articleID int64) (string, bool, error)
+	SaveSummary(ctx context.Context, articleID int64, summary string) error
+	SetSummaries(ctx context.Context, userID int64, enabled bool) error
+	HasSummaries(ctx context.Context, userID int64) (bool, error)
+	GetSummaryUsers(ctx context.Context, userIDs []int64) (map[int64]bool, error)
+}
+
+type SummaryUsecaseInterface interface {
+	Summarize(ctx context.Context, article *entities.Article) (string, error)
+	SetEnabled(ctx context.Context, userID int64, enabled bool) error
+	IsEnabled(ctx context.Context, userID int64) (bool, error)
+	GetEnabledUsers(ctx context.Context, userIDs []int64) (map[int64]bool, error)
+}
+
+type ReaderRepositoryInterface interface {
+	GetArticle(ctx context.Context, articleID int64) (*entities.Article, string, bool, error)
+	SaveContent(ctx context.Context, articleID int64, content string) error
+}
+
+type ReaderUsecaseInterface interface {
+	Read(ctx context.Context, articleID int64) (*entities.Article, []string, error)
+	PageURL(articleID int64) string
+}
+
+type LanguageRepositoryInterface interface {
+	SetLanguages(ctx context.Context, userID int64, languages []string) error
+	GetLanguages(ctx context.Context, userID int64) ([]string, error)
+	GetAllLanguages(ctx context.Context) (map[int64][]string, error)
+}
+
+type LanguageUsecaseInterface interface {
+	SetLanguages(ctx context.Context, userID int64, languages []string) error
+	GetLanguages(ctx context.Context, userID int64) ([]string, error)
+	GetAccepted(ctx context.Context) (map[int64]map[string]bool, error)
+}
+
+type MediaRepositoryInterface interface {
+	SetMediaMode(ctx context.Context, userID int64, mode string) error
+	GetMediaMode(ctx context.Context, userID int64) (string, error)
+	GetMediaModes(ctx context.Context, userIDs []int64) (map[int64]string, error)
+}
+
+type MediaUsecaseInterface interface {
+	SetMode(ctx context.Context, userID int64, mode string) error
+	GetMode(ctx context.Context, userID int64) (string, error)
+	GetModes(ctx context.Context, userIDs []int64) (map[int64]string, error)
 }
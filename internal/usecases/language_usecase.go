@@ -0,0 +1,54 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"tgbot/internal/langdetect"
+)
+
+type LanguageUsecase struct {
+	repo LanguageRepositoryInterface
+}
+
+func NewLanguageUsecase(repo LanguageRepositoryInterface) *LanguageUsecase {
+	return &LanguageUsecase{repo: repo}
+}
+
+// SetLanguages sets the languages of the articles the user receives. No
+// languages lifts the restriction.
+func (u *LanguageUsecase) SetLanguages(ctx context.Context, userID int64, languages []string) error {
+	var normalized []string
+	for _, language := range languages {
+		language = strings.ToLower(strings.TrimSpace(language))
+		if !slices.Contains(langdetect.Languages, language) {
+			return fmt.Errorf("неизвестный язык %q, доступны: %s", language, strings.Join(langdetect.Languages, ", "))
+		}
+		if !slices.Contains(normalized, language) {
+			normalized = append(normalized, language)
+		}
+	}
+	return u.repo.SetLanguages(ctx, userID, normalized)
+}
+
+func (u *LanguageUsecase) GetLanguages(ctx context.Context, userID int64) ([]string, error) {
+	return u.repo.GetLanguages(ctx, userID)
+}
+
+// GetAccepted returns the accepted languages of the users who restricted
+// them.
+func (u *LanguageUsecase) GetAccepted(ctx context.Context) (map[int64]map[string]bool, error) {
+	all, err := u.repo.GetAllLanguages(ctx)
+	if err != nil {
+		return nil, err
+	}
+	accepted := make(map[int64]map[string]bool, len(all))
+	for userID, languages := range all {
+		accepted[userID] = make(map[string]bool, len(languages))
+		for _, language := range languages {
+			accepted[userID][language] = true
+		}
+	}
+	return accepted, nil
+}
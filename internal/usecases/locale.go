@@ -0,0 +1,45 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+	"tgbot/internal/entities"
+)
+
+// parseNewsQuery parses "<category> [country] [language]". A single code is
+// used as the country and/or the language, whichever it is valid for, so
+// "/add technology ru" asks for Russian headlines from Russia.
+func parseNewsQuery(args string) (entities.NewsQuery, error) {
+	fields := strings.Fields(strings.ToLower(args))
+	if len(fields) == 0 {
+		return entities.NewsQuery{}, fmt.Errorf("не указана категория")
+	}
+	if len(fields) > 3 {
+		return entities.NewsQuery{}, fmt.Errorf("слишком много параметров")
+	}
+
+	query := entities.NewsQuery{Category: fields[0]}
+	switch len(fields) {
+	case 2:
+		code := fields[1]
+		if !entities.IsNewsCountry(code) && !entities.IsNewsLanguage(code) {
+			return entities.NewsQuery{}, fmt.Errorf("неизвестный код страны или языка '%s'", code)
+		}
+		if entities.IsNewsCountry(code) {
+			query.Country = code
+		}
+		if entities.IsNewsLanguage(code) {
+			query.Language = code
+		}
+	case 3:
+		if !entities.IsNewsCountry(fields[1]) {
+			return entities.NewsQuery{}, fmt.Errorf("неизвестный код страны '%s'", fields[1])
+		}
+		if !entities.IsNewsLanguage(fields[2]) {
+			return entities.NewsQuery{}, fmt.Errorf("неизвестный код языка '%s'", fields[2])
+		}
+		query.Country = fields[1]
+		query.Language = fields[2]
+	}
+	return query, nil
+}
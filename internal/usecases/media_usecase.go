@@ -0,0 +1,35 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"tgbot/internal/entities"
+)
+
+type MediaUsecase struct {
+	repo MediaRepositoryInterface
+}
+
+func NewMediaUsecase(repo MediaRepositoryInterface) *MediaUsecase {
+	return &MediaUsecase{repo: repo}
+}
+
+// SetMode sets how articles are shown to the user; see entities.MediaModes.
+func (u *MediaUsecase) SetMode(ctx context.Context, userID int64, mode string) error {
+	if !slices.Contains(entities.MediaModes, mode) {
+		return fmt.Errorf("неизвестный режим %q, доступны: %s", mode, strings.Join(entities.MediaModes, ", "))
+	}
+	return u.repo.SetMediaMode(ctx, userID, mode)
+}
+
+func (u *MediaUsecase) GetMode(ctx context.Context, userID int64) (string, error) {
+	return u.repo.GetMediaMode(ctx, userID)
+}
+
+// GetModes returns the modes of the users who don't use the default, of
+// userIDs only unless it is nil.
+func (u *MediaUsecase) GetModes(ctx context.Context, userIDs []int64) (map[int64]string, error) {
+	return u.repo.GetMediaModes(ctx, userIDs)
+}
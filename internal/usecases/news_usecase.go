@@ -2,31 +2,107 @@ package usecases
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
 	"sort"
 	"tgbot/internal/entities"
+	"tgbot/internal/langdetect"
+	"tgbot/internal/logging"
+	"tgbot/internal/metrics"
+	"tgbot/internal/service"
 )
 
+// ErrStaleNews is returned together with stored articles when the news
+// provider can't be queried right now and the result may be out of date.
+var ErrStaleNews = errors.New("news provider unavailable, showing stored articles")
+
+const staleArticlesLimit = 20
+
 type NewsUsecase struct {
 	newsService NewsServiceInterface
 	sentRepo    SentArticlesRepositoryInterface
+	articleRepo ArticleRepositoryInterface
+	metrics     metrics.Recorder
+	logger      *slog.Logger
+	categories  CategoryCatalogueInterface
+	feeds       FeedServiceInterface
+}
+
+type NewsOption func(*NewsUsecase)
+
+// WithArticleStore keeps every fetched article so it can be served when the
+// provider is unavailable.
+func WithArticleStore(repo ArticleRepositoryInterface) NewsOption {
+	return func(u *NewsUsecase) {
+		u.articleRepo = repo
+	}
+}
+
+// WithNewsMetrics counts fetched and new articles per category.
+func WithNewsMetrics(recorder metrics.Recorder) NewsOption {
+	return func(u *NewsUsecase) {
+		u.metrics = recorder
+	}
+}
+
+// WithNewsLogger sets the logger used when the context carries none.
+func WithNewsLogger(logger *slog.Logger) NewsOption {
+	return func(u *NewsUsecase) {
+		u.logger = logger
+	}
+}
+
+// WithCategoryCatalogue resolves categories through the catalogue, so a
+// category can be served by a NewsAPI category, a keyword query or feeds.
+func WithCategoryCatalogue(categories CategoryCatalogueInterface, feeds FeedServiceInterface) NewsOption {
+	return func(u *NewsUsecase) {
+		u.categories = categories
+		u.feeds = feeds
+	}
 }
 
-func NewNewsUsecase(newsService NewsServiceInterface, sentRepo SentArticlesRepositoryInterface) *NewsUsecase {
-	return &NewsUsecase{
+func NewNewsUsecase(newsService NewsServiceInterface, sentRepo SentArticlesRepositoryInterface, opts ...NewsOption) *NewsUsecase {
+	u := &NewsUsecase{
 		newsService: newsService,
 		sentRepo:    sentRepo,
+		metrics:     metrics.Nop{},
+		logger:      slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(u)
 	}
+	return u
 }
-func (u *NewsUsecase) GetNewsByCategory(ctx context.Context, category string) ([]entities.Article, error) {
-	articles, err := u.newsService.GetNewsByCategory(ctx, category)
-	if err != nil {
+
+// GetNews returns the current headlines for the query. If the provider is
+// rate limited or its circuit breaker is open, it falls back to stored
+// articles of the category in the query's language and returns them along
+// with ErrStaleNews. Stored articles have no country, so the fallback
+// ignores it.
+func (u *NewsUsecase) GetNews(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+	articles, err := u.fetch(ctx, query)
+	if err == nil {
+		return articles, nil
+	}
+	if u.articleRepo == nil || !providerUnavailable(err) {
 		return nil, err
 	}
-	return articles, nil
+
+	stored, storeErr := u.articleRepo.GetLatestArticles(ctx, query.Category, query.Language, staleArticlesLimit)
+	if storeErr != nil {
+		logging.FromContext(ctx, u.logger).Error("Error loading stored articles", "category", query.Category, "error", storeErr)
+		return nil, err
+	}
+	return stored, ErrStaleNews
 }
 
-func (u *NewsUsecase) GetNewArticles(ctx context.Context, category string, maxArticles int) ([]entities.Article, error) {
-	articles, err := u.newsService.GetNewsByCategory(ctx, category)
+// GetNewArticles returns up to maxArticles of the query's articles that
+// were not sent yet, newest first. They stay unsent until ClaimArticles
+// claims them.
+func (u *NewsUsecase) GetNewArticles(ctx context.Context, query entities.NewsQuery, maxArticles int) ([]entities.Article, error) {
+	articles, err := u.fetch(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -46,15 +122,100 @@ func (u *NewsUsecase) GetNewArticles(ctx context.Context, category string, maxAr
 		return newArticles[i].PublishedAt > newArticles[j].PublishedAt
 	})
 
+	u.metrics.ArticlesFetched(query.Category, len(articles), len(newArticles))
 	if len(newArticles) > maxArticles {
 		newArticles = newArticles[:maxArticles]
 	}
+	return newArticles, nil
+}
 
-	for _, article := range newArticles {
-		if err := u.sentRepo.SaveSentArticle(ctx, &article, category); err != nil {
-			continue
+// ClaimArticles marks the articles picked for delivery as sent and returns
+// those this call claimed. Overlapping cycles picking the same articles each
+// get only the ones they claimed, so no article is sent twice.
+func (u *NewsUsecase) ClaimArticles(ctx context.Context, articles []entities.Article, category string) ([]entities.Article, error) {
+	claimed, err := u.sentRepo.ClaimArticles(ctx, articles, category)
+	if err != nil {
+		return nil, err
+	}
+	return keepURLs(articles, claimed), nil
+}
+
+// keepURLs returns the articles whose URL is in urls, in order and once
+// each.
+func keepURLs(articles []entities.Article, urls []string) []entities.Article {
+	keep := make(map[string]bool, len(urls))
+	for _, url := range urls {
+		keep[url] = true
+	}
+	var kept []entities.Article
+	for _, article := range articles {
+		if keep[article.URL] {
+			kept = append(kept, article)
+			delete(keep, article.URL)
 		}
 	}
+	return kept
+}
 
-	return newArticles, nil
+func (u *NewsUsecase) fetch(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+	articles, err := u.fetchFromProvider(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	// Languages and stored IDs are set on a copy: the provider's slice may be
+	// cached or shared with concurrent callers.
+	articles = slices.Clone(articles)
+	detectLanguages(articles, query.Language)
+	if u.articleRepo != nil && len(articles) > 0 {
+		if err := u.articleRepo.SaveArticles(ctx, query.Category, articles); err != nil {
+			logging.FromContext(ctx, u.logger).Error("Error storing articles", "category", query.Category, "error", err)
+		}
+	}
+	return articles, nil
+}
+
+// fetchFromProvider asks the provider that backs the query's category.
+func (u *NewsUsecase) fetchFromProvider(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+	if u.categories == nil || query.Category == "" {
+		return u.newsService.GetNews(ctx, query)
+	}
+	category, err := u.categories.GetCategory(ctx, query.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	providerQuery := query
+	switch category.Provider {
+	case entities.ProviderKeyword:
+		providerQuery.Category = ""
+		providerQuery.Keywords = category.Keywords
+	case entities.ProviderRSS:
+		if u.feeds == nil {
+			return nil, fmt.Errorf("category %s: feeds are not supported", category.Slug)
+		}
+		return u.feeds.GetFeeds(ctx, category.Feeds)
+	default:
+		providerQuery.Category = category.NewsAPICategory
+	}
+	return u.newsService.GetNews(ctx, providerQuery)
+}
+
+// detectLanguages sets the language of the articles from their title and
+// description. Articles too short to tell get the language the query asked
+// for, if any.
+func detectLanguages(articles []entities.Article, fallback string) {
+	for i := range articles {
+		article := &articles[i]
+		if language := langdetect.Detect(article.Title + ". " + article.Description); language != "" {
+			article.Language = language
+		} else if article.Language == "" {
+			article.Language = fallback
+		}
+	}
+}
+
+func providerUnavailable(err error) bool {
+	return errors.Is(err, service.ErrCircuitOpen) ||
+		errors.Is(err, service.ErrRateLimited) ||
+		errors.Is(err, service.ErrQuotaExceeded)
 }
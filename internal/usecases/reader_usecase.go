@@ -0,0 +1,127 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"tgbot/internal/content"
+	"tgbot/internal/entities"
+	"tgbot/internal/tracking"
+	"time"
+)
+
+// failedFetchTTL is how long a page that couldn't be fetched isn't tried
+// again, so repeated requests for it don't each hit the article's site.
+const failedFetchTTL = 5 * time.Minute
+
+type failedFetch struct {
+	err error
+	at  time.Time
+}
+
+type ReaderUsecase struct {
+	repo    ReaderRepositoryInterface
+	fetcher PageFetcherInterface
+	signer  *tracking.Signer
+	// pagePrefix is prepended to signed article tokens to make reader page
+	// links. Without it there are no reader pages.
+	pagePrefix string
+
+	mu     sync.Mutex
+	failed map[int64]failedFetch
+}
+
+func NewReaderUsecase(repo ReaderRepositoryInterface, fetcher PageFetcherInterface, signer *tracking.Signer, pagePrefix string) *ReaderUsecase {
+	return &ReaderUsecase{
+		repo:       repo,
+		fetcher:    fetcher,
+		signer:     signer,
+		pagePrefix: pagePrefix,
+		failed:     make(map[int64]failedFetch),
+	}
+}
+
+// Read returns the stored article and the paragraphs of its main text. The
+// article is nil if it doesn't exist, and there are no paragraphs if the
+// page had no usable text. The text is extracted once and then cached;
+// failed fetches are remembered for failedFetchTTL only.
+func (u *ReaderUsecase) Read(ctx context.Context, articleID int64) (*entities.Article, []string, error) {
+	article, text, extracted, err := u.repo.GetArticle(ctx, articleID)
+	if err != nil || article == nil {
+		return nil, nil, err
+	}
+	if extracted {
+		return article, splitParagraphs(text), nil
+	}
+	if err := u.recentFailure(articleID); err != nil {
+		return nil, nil, err
+	}
+
+	page, err := u.fetcher.Fetch(ctx, article.URL)
+	if err != nil {
+		if ctx.Err() == nil {
+			u.fetchFailed(articleID, err)
+		}
+		return nil, nil, err
+	}
+	readable := content.Extract(page)
+	if article.Title == "" {
+		article.Title = readable.Title
+	}
+	if err := u.repo.SaveContent(ctx, articleID, strings.Join(readable.Paragraphs, "\n\n")); err != nil {
+		return nil, nil, err
+	}
+	return article, readable.Paragraphs, nil
+}
+
+// ReadPage is Read for the token of a reader page link. The article is nil
+// if the token is invalid.
+func (u *ReaderUsecase) ReadPage(ctx context.Context, token string) (*entities.Article, []string, error) {
+	articleID, err := u.signer.VerifyArticle(token)
+	if errors.Is(err, tracking.ErrInvalidToken) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return u.Read(ctx, articleID)
+}
+
+func (u *ReaderUsecase) recentFailure(articleID int64) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	failure, ok := u.failed[articleID]
+	if !ok || time.Since(failure.at) > failedFetchTTL {
+		return nil
+	}
+	return failure.err
+}
+
+func (u *ReaderUsecase) fetchFailed(articleID int64, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	now := time.Now()
+	for id, failure := range u.failed {
+		if now.Sub(failure.at) > failedFetchTTL {
+			delete(u.failed, id)
+		}
+	}
+	u.failed[articleID] = failedFetch{err: err, at: now}
+}
+
+// PageURL returns the link to the article's reader page, or an empty string
+// if reader pages are off.
+func (u *ReaderUsecase) PageURL(articleID int64) string {
+	if u.pagePrefix == "" {
+		return ""
+	}
+	return u.pagePrefix + u.signer.SignArticle(articleID)
+}
+
+func splitParagraphs(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n\n")
+}
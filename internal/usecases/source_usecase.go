@@ -0,0 +1,128 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"tgbot/internal/entities"
+	"tgbot/internal/logging"
+	"tgbot/internal/repository"
+	"time"
+)
+
+// ErrUnknownSource is returned when a source ID is not in the catalogue.
+var ErrUnknownSource = errors.New("unknown source")
+
+const sourcesCatalogueTTL = 24 * time.Hour
+
+type SourceUsecase struct {
+	userRepo   repository.UserRepository
+	sourceRepo repository.SourceRepository
+	catalogue  SourceCatalogueInterface
+}
+
+func NewSourceUsecase(userRepo repository.UserRepository, sourceRepo repository.SourceRepository, catalogue SourceCatalogueInterface) *SourceUsecase {
+	return &SourceUsecase{
+		userRepo:   userRepo,
+		sourceRepo: sourceRepo,
+		catalogue:  catalogue,
+	}
+}
+
+// ListSources returns the locally cached catalogue, refreshing it from the
+// provider once a day. A stale catalogue is served if the refresh fails.
+func (u *SourceUsecase) ListSources(ctx context.Context) ([]entities.Source, error) {
+	sources, fetchedAt, err := u.sourceRepo.GetSources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(sources) > 0 && time.Since(fetchedAt) < sourcesCatalogueTTL {
+		return sources, nil
+	}
+
+	fresh, err := u.catalogue.GetSources(ctx)
+	if err != nil {
+		if len(sources) > 0 {
+			logging.FromContext(ctx, slog.Default()).Warn("Error refreshing sources catalogue, serving cached copy", "error", err)
+			return sources, nil
+		}
+		return nil, err
+	}
+	if err := u.sourceRepo.ReplaceSources(ctx, fresh); err != nil {
+		logging.FromContext(ctx, slog.Default()).Error("Error caching sources catalogue", "error", err)
+	}
+	return fresh, nil
+}
+
+// FollowSource follows a catalogue source; its articles go to the forum
+// topic threadID, or to General if it is 0.
+func (u *SourceUsecase) FollowSource(ctx context.Context, userID int64, sourceID string, threadID int) error {
+	sources, err := u.ListSources(ctx)
+	if err != nil {
+		return err
+	}
+	sourceID = strings.ToLower(strings.TrimSpace(sourceID))
+	found := false
+	for _, s := range sources {
+		if s.ID == sourceID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ErrUnknownSource
+	}
+
+	if err := u.userRepo.SaveUser(ctx, &entities.User{ID: userID}); err != nil {
+		return err
+	}
+	return u.sourceRepo.FollowSource(ctx, &entities.SourceFollow{UserID: userID, SourceID: sourceID, ThreadID: threadID})
+}
+
+// MuteSource hides a source from the user's deliveries. The source may be a
+// catalogue ID or an outlet name, because many articles come from outlets
+// that are not in the catalogue.
+func (u *SourceUsecase) MuteSource(ctx context.Context, userID int64, source string) error {
+	if err := u.userRepo.SaveUser(ctx, &entities.User{ID: userID}); err != nil {
+		return err
+	}
+	return u.sourceRepo.MuteSource(ctx, userID, normalizeSource(source))
+}
+
+func (u *SourceUsecase) GetFollowsByUser(ctx context.Context, userID int64) ([]string, error) {
+	return u.sourceRepo.GetFollowsByUser(ctx, userID)
+}
+
+func (u *SourceUsecase) GetAllFollows(ctx context.Context) ([]entities.SourceFollow, error) {
+	return u.sourceRepo.GetAllFollows(ctx)
+}
+
+// GetMutedSources returns the muted source IDs and names of every user.
+func (u *SourceUsecase) GetMutedSources(ctx context.Context) (map[int64]map[string]bool, error) {
+	mutes, err := u.sourceRepo.GetAllMutes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	muted := make(map[int64]map[string]bool, len(mutes))
+	for userID, sources := range mutes {
+		muted[userID] = make(map[string]bool, len(sources))
+		for _, source := range sources {
+			muted[userID][source] = true
+		}
+	}
+	return muted, nil
+}
+
+// IsMuted reports whether the article comes from one of the muted sources.
+func IsMuted(article *entities.Article, muted map[string]bool) bool {
+	if len(muted) == 0 {
+		return false
+	}
+	return (article.SourceID != "" && muted[normalizeSource(article.SourceID)]) ||
+		(article.SourceName != "" && muted[normalizeSource(article.SourceName)])
+}
+
+func normalizeSource(source string) string {
+	return strings.ToLower(strings.TrimSpace(source))
+}
@@ -30,13 +30,34 @@ func (u *SubscriptionUsecase) GetSubscriptionsByUser(ctx context.Context, userID
 	if err != nil {
 		return nil, err
 	}
-	categories := make([]string, len(subscriptions))
+	labels := make([]string, len(subscriptions))
 	for i, sub := range subscriptions {
-		categories[i] = sub.Category
+		labels[i] = sub.Label()
 	}
-	return categories, nil
+	return labels, nil
 }
 
 func (u *SubscriptionUsecase) GetAllSubscriptions(ctx context.Context) ([]entities.Subscription, error) {
 	return u.subRepo.GetAllSubscriptions(ctx)
 }
+
+// GetUserSubscriptions returns the user's subscriptions with their filters.
+func (u *SubscriptionUsecase) GetUserSubscriptions(ctx context.Context, userID int64) ([]entities.Subscription, error) {
+	return u.subRepo.GetSubscriptionsByUser(ctx, userID)
+}
+
+func (u *SubscriptionUsecase) DeleteSubscription(ctx context.Context, userID int64, category string) (bool, error) {
+	return u.subRepo.DeleteSubscription(ctx, userID, category)
+}
+
+// SetUnreachable stops deliveries to a chat until a command is sent from it
+// again.
+func (u *SubscriptionUsecase) SetUnreachable(ctx context.Context, chatID int64) error {
+	return u.userRepo.SetUnreachable(ctx, chatID)
+}
+
+// MigrateChat moves a group's subscriptions and settings to the supergroup
+// it was upgraded to.
+func (u *SubscriptionUsecase) MigrateChat(ctx context.Context, fromID, toID int64) error {
+	return u.userRepo.MigrateChat(ctx, fromID, toID)
+}
@@ -0,0 +1,61 @@
+package usecases
+
+import (
+	"context"
+	"tgbot/internal/content"
+	"tgbot/internal/entities"
+	"tgbot/internal/summary"
+)
+
+// summarySentences is the length of a summary.
+const summarySentences = 3
+
+type SummaryUsecase struct {
+	repo    SummaryRepositoryInterface
+	fetcher PageFetcherInterface
+}
+
+func NewSummaryUsecase(repo SummaryRepositoryInterface, fetcher PageFetcherInterface) *SummaryUsecase {
+	return &SummaryUsecase{repo: repo, fetcher: fetcher}
+}
+
+// Summarize returns a summary of the article's page, or an empty string if
+// the page has no usable text. Summaries of stored articles are cached;
+// failed fetches aren't, so they are retried next time.
+func (u *SummaryUsecase) Summarize(ctx context.Context, article *entities.Article) (string, error) {
+	if article.ID != 0 {
+		cached, ok, err := u.repo.GetSummary(ctx, article.ID)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return cached, nil
+		}
+	}
+
+	page, err := u.fetcher.Fetch(ctx, article.URL)
+	if err != nil {
+		return "", err
+	}
+	result := summary.Summarize(article.Title, content.Paragraphs(page), summarySentences)
+	if article.ID != 0 {
+		if err := u.repo.SaveSummary(ctx, article.ID, result); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+func (u *SummaryUsecase) SetEnabled(ctx context.Context, userID int64, enabled bool) error {
+	return u.repo.SetSummaries(ctx, userID, enabled)
+}
+
+func (u *SummaryUsecase) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	return u.repo.HasSummaries(ctx, userID)
+}
+
+// GetEnabledUsers returns the users who get summaries, of userIDs only
+// unless it is nil.
+func (u *SummaryUsecase) GetEnabledUsers(ctx context.Context, userIDs []int64) (map[int64]bool, error) {
+	return u.repo.GetSummaryUsers(ctx, userIDs)
+}
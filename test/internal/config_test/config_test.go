@@ -0,0 +1,138 @@
+package config_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"tgbot/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_FileWithEnvOverrides(t *testing.T) {
+	path := writeConfig(t, `
+bot:
+  token: file-token
+  auth_keys: [key1, key2]
+  categories: [technology, sports]
+  news_interval: 10m
+storage:
+  max_conns: 7
+log:
+  level: debug
+`)
+	t.Setenv("BOT_TOKEN", "env-token")
+	t.Setenv("BOT_ARTICLES_PER_CATEGORY", "3")
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, "env-token", cfg.Bot.Token)
+	assert.Equal(t, []string{"key1", "key2"}, cfg.Bot.AuthKeys)
+	assert.Equal(t, []string{"technology", "sports"}, cfg.Bot.Categories)
+	assert.Equal(t, 10*time.Minute, cfg.Bot.NewsInterval)
+	assert.Equal(t, 3, cfg.Bot.ArticlesPerCategory)
+	assert.Equal(t, 7, cfg.Storage.MaxConns)
+	assert.Equal(t, slog.LevelDebug, cfg.Log.Level)
+	assert.Equal(t, "localhost", cfg.Storage.Host, "defaults are kept")
+}
+
+func TestLoad_EmptyEnvKeepsFileValues(t *testing.T) {
+	path := writeConfig(t, `
+bot:
+  token: file-token
+  auth_keys: [key1]
+  admin_ids: [42]
+`)
+	// docker-compose passes the variables it lists even when they are unset.
+	t.Setenv("BOT_TOKEN", "")
+	t.Setenv("BOT_AUTH_KEY", "")
+	t.Setenv("BOT_ADMIN_IDS", "")
+	t.Setenv("BOT_ARTICLES_PER_CATEGORY", "")
+
+	cfg, err := config.Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "file-token", cfg.Bot.Token)
+	assert.Equal(t, []string{"key1"}, cfg.Bot.AuthKeys)
+	assert.Equal(t, []int64{42}, cfg.Bot.AdminIDs)
+}
+
+func TestLoad_EnvOnly(t *testing.T) {
+	t.Setenv("BOT_TOKEN", "token")
+	t.Setenv("BOT_AUTH_KEY", "legacy-key")
+
+	cfg, err := config.Load("")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"legacy-key"}, cfg.Bot.AuthKeys)
+	assert.Equal(t, 5*time.Minute, cfg.Bot.NewsInterval)
+}
+
+func TestLoad_NamesEveryInvalidField(t *testing.T) {
+	path := writeConfig(t, `
+bot:
+  categories: []
+  articles_per_category: 0
+cache:
+  backend: redis
+`)
+	t.Setenv("STORAGE_PORT", "abc")
+
+	_, err := config.Load(path)
+	require.Error(t, err)
+	for _, field := range []string{"STORAGE_PORT", "bot.token", "bot.auth_keys", "bot.categories", "bot.articles_per_category", "cache.backend"} {
+		assert.Contains(t, err.Error(), field)
+	}
+}
+
+func TestLoad_PublicURLNeedsSecret(t *testing.T) {
+	t.Setenv("BOT_TOKEN", "token")
+	t.Setenv("BOT_AUTH_KEY", "key")
+	t.Setenv("HTTP_PUBLIC_URL", "https://bot.example.com")
+
+	// Reader page links are signed.
+	_, err := config.Load("")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "http.tracking_secret")
+
+	t.Setenv("CLICK_TRACKING_SECRET", "0123456789abcdef")
+	_, err = config.Load("")
+	assert.NoError(t, err)
+}
+
+func TestLoad_RejectsUnknownFields(t *testing.T) {
+	path := writeConfig(t, `
+bot:
+  tokn: typo
+`)
+
+	_, err := config.Load(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tokn")
+}
+
+func TestConfig_Reloadable(t *testing.T) {
+	t.Setenv("BOT_TOKEN", "token")
+	t.Setenv("BOT_AUTH_KEYS", "key")
+	current, err := config.Load("")
+	require.NoError(t, err)
+
+	next := *current
+	next.Bot.Token = "other-token"
+	next.Bot.Categories = []string{"science"}
+	next.Bot.AdminIDs = []int64{42}
+
+	merged := current.Reloadable(&next)
+	assert.Equal(t, "token", merged.Bot.Token, "token needs a restart")
+	assert.Equal(t, []string{"science"}, merged.Bot.Categories)
+	assert.Equal(t, []int64{42}, merged.Bot.AdminIDs)
+}
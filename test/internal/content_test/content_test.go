@@ -0,0 +1,120 @@
+package content_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tgbot/internal/content"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParagraphs_SkipsPageFurniture(t *testing.T) {
+	page := `<html><head><style>p { color: red }</style></head><body>
+		<nav><p>Home | World | Sport</p></nav>
+		<article>
+			<p>The first   paragraph <b>of the</b> story.</p>
+			<script>var p = "<p>not text</p>";</script>
+			<p>The second paragraph.<button>Share</button></p>
+			<p>   </p>
+		</article>
+		<footer><p>© Example News</p></footer>
+	</body></html>`
+
+	assert.Equal(t, []string{"The first paragraph of the story.", "The second paragraph."}, content.Paragraphs(page))
+}
+
+func TestFetcher(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/big":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte("<p>" + strings.Repeat("a", 1000) + "</p>"))
+		case "/cp1251":
+			w.Header().Set("Content-Type", "text/html; charset=windows-1251")
+			w.Write([]byte{0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2}) // "Привет"
+		case "/pdf":
+			w.Header().Set("Content-Type", "application/pdf")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	fetcher := content.NewFetcher(srv.Client(), 100)
+	ctx := context.Background()
+
+	page, err := fetcher.Fetch(ctx, srv.URL+"/big")
+	require.NoError(t, err)
+	assert.Len(t, page, 100, "pages are cut at the size limit")
+
+	page, err = fetcher.Fetch(ctx, srv.URL+"/cp1251")
+	require.NoError(t, err)
+	assert.Equal(t, "Привет", page)
+
+	_, err = fetcher.Fetch(ctx, srv.URL+"/pdf")
+	assert.ErrorIs(t, err, content.ErrNotHTML)
+
+	_, err = fetcher.Fetch(ctx, srv.URL+"/missing")
+	assert.Error(t, err)
+}
+
+func TestNewClient_RefusesNonPublicAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<p>internal</p>"))
+	}))
+	defer srv.Close()
+	fetcher := content.NewFetcher(content.NewClient(time.Second), 100)
+
+	// Article links must not reach the server's own network.
+	for _, url := range []string{srv.URL, "http://localhost:1/", "http://169.254.169.254/latest/meta-data/", "http://[::1]:1/", "http://10.0.0.1:1/"} {
+		_, err := fetcher.Fetch(context.Background(), url)
+		assert.ErrorIs(t, err, content.ErrNonPublicAddress, url)
+	}
+}
+
+func TestExtract_PicksMainText(t *testing.T) {
+	page := `<html><head>
+		<title>Tram line approved | City News</title>
+		<meta property="og:title" content="Tram line approved">
+	</head><body>
+		<div class="menu"><p>Home, World, Sport, Culture, Weather, and everything else we cover</p></div>
+		<div class="layout">
+			<div class="article-body">
+				<p>The city council approved the new tram line on Monday, after a long public debate.</p>
+				<h2>What happens next</h2>
+				<p>Construction will start next spring, and the line should open in three years.</p>
+				<ul><li>Twelve new stops</li><li>Trams every six minutes</li></ul>
+				<div class="share"><p>Share this story on Facebook, Twitter, or by email to your friends</p></div>
+				<p style="display: none">Hidden text that readers never see on the page.</p>
+			</div>
+			<div class="sidebar related">
+				<p><a href="/1">Another story about the city council and its many decisions</a></p>
+			</div>
+		</div>
+		<div class="comments"><p>Great news, I have waited for this tram line for years, finally!</p></div>
+	</body></html>`
+
+	readable := content.Extract(page)
+
+	assert.Equal(t, "Tram line approved", readable.Title)
+	assert.Equal(t, []string{
+		"The city council approved the new tram line on Monday, after a long public debate.",
+		"What happens next",
+		"Construction will start next spring, and the line should open in three years.",
+		"• Twelve new stops",
+		"• Trams every six minutes",
+	}, readable.Paragraphs)
+}
+
+func TestExtract_FallsBackToParagraphs(t *testing.T) {
+	readable := content.Extract(`<html><head><title>Short</title></head><body><p>Too short.</p></body></html>`)
+
+	assert.Equal(t, "Short", readable.Title)
+	assert.Equal(t, []string{"Too short."}, readable.Paragraphs)
+}
@@ -0,0 +1,74 @@
+package filter_test
+
+import (
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/filter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_Match(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		text       string
+		expected   bool
+	}{
+		{name: "Single term", expression: "AI", text: "New AI model released", expected: true},
+		{name: "Whole words only", expression: "AI", text: "She said the email was sent", expected: false},
+		{name: "Case insensitive", expression: "crypto", text: "CRYPTO markets fall", expected: true},
+		{name: "Implicit AND", expression: "apple iphone", text: "Apple unveils the new iPhone", expected: true},
+		{name: "Implicit AND fails", expression: "apple iphone", text: "Apple earnings beat forecasts", expected: false},
+		{name: "OR", expression: "crypto OR celebrity", text: "Celebrity wedding of the year", expected: true},
+		{name: "Pipe shorthand", expression: "crypto | celebrity", text: "Crypto exchange hacked", expected: true},
+		{name: "NOT", expression: "crypto NOT bitcoin", text: "Bitcoin and crypto rally", expected: false},
+		{name: "Minus shorthand", expression: "crypto -bitcoin", text: "Crypto regulation passes", expected: true},
+		{name: "Phrase", expression: `"machine learning"`, text: "Advances in machine learning", expected: true},
+		{name: "Phrase order", expression: `"machine learning"`, text: "Learning about the machine", expected: false},
+		{name: "Prefix", expression: "crypto*", text: "Cryptocurrency news", expected: true},
+		{name: "Parentheses", expression: "(AI OR robots) AND jobs", text: "Robots will change jobs", expected: true},
+		{name: "Cyrillic", expression: "выборы", text: "Итоги: Выборы в парламент", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := filter.Parse(tt.expression)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, expr.Match(filter.Words(tt.text)))
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, expression := range []string{"", `"open phrase`, "(AI OR", "AI OR", "NOT", ")"} {
+		_, err := filter.Parse(expression)
+		assert.Error(t, err, expression)
+	}
+}
+
+func TestRuleSet_Allow(t *testing.T) {
+	rules := filter.NewRuleSet([]entities.FilterRule{
+		{Kind: entities.FilterExclude, Expression: "crypto OR celebrity"},
+		{Kind: entities.FilterInclude, Category: "technology", Expression: "AI"},
+	})
+
+	tests := []struct {
+		name     string
+		category string
+		article  entities.Article
+		expected bool
+	}{
+		{name: "Excluded everywhere", category: "business", article: entities.Article{Title: "Crypto funds"}, expected: false},
+		{name: "Other category unaffected by include", category: "business", article: entities.Article{Title: "Markets rally"}, expected: true},
+		{name: "Include required", category: "technology", article: entities.Article{Title: "New phone"}, expected: false},
+		{name: "Include matched in description", category: "technology", article: entities.Article{Title: "New phone", Description: "With on-device AI"}, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rules.Allow(tt.category, &tt.article))
+		})
+	}
+}
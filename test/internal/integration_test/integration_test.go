@@ -2,13 +2,18 @@ package integration_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"tgbot/internal/config"
 	"tgbot/internal/entities"
+	"tgbot/internal/queue"
 	"tgbot/internal/repository"
 	"tgbot/internal/usecases"
 
@@ -24,7 +29,7 @@ var (
 
 type mockNewsService struct{}
 
-func (m *mockNewsService) GetNewsByCategory(ctx context.Context, category string) ([]entities.Article, error) {
+func (m *mockNewsService) GetNews(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
 	return []entities.Article{
 		{Title: "Title 1", URL: "http://example.com/1", PublishedAt: "2025-01-01T00:00:00Z"},
 	}, nil
@@ -54,19 +59,55 @@ func TestMain(m *testing.M) {
 	}
 
 	_, err = pool.Exec(ctx, `
-        CREATE TABLE users (id BIGINT PRIMARY KEY);
+        CREATE TABLE users (
+            id BIGINT PRIMARY KEY,
+            role VARCHAR(10) NOT NULL DEFAULT 'user',
+            banned BOOLEAN NOT NULL DEFAULT FALSE,
+            tracking_opt_out BOOLEAN NOT NULL DEFAULT FALSE,
+            breaking_alerts BOOLEAN NOT NULL DEFAULT FALSE,
+            summaries BOOLEAN NOT NULL DEFAULT FALSE,
+            languages TEXT[] NOT NULL DEFAULT '{}',
+            media VARCHAR(10) NOT NULL DEFAULT 'preview',
+            chat_type VARCHAR(20) NOT NULL DEFAULT 'private',
+            unreachable BOOLEAN NOT NULL DEFAULT FALSE,
+            last_active_at TIMESTAMP WITH TIME ZONE
+        );
         CREATE TABLE subscriptions (
             id SERIAL PRIMARY KEY,
             user_id BIGINT REFERENCES users(id),
             category VARCHAR(50) NOT NULL,
+            country VARCHAR(2) NOT NULL DEFAULT '',
+            language VARCHAR(2) NOT NULL DEFAULT '',
+            thread_id BIGINT NOT NULL DEFAULT 0,
             UNIQUE(user_id, category)
         );
+        CREATE TABLE source_follows (user_id BIGINT REFERENCES users(id), source_id VARCHAR(100) NOT NULL, thread_id BIGINT NOT NULL DEFAULT 0, PRIMARY KEY (user_id, source_id));
+        CREATE TABLE source_mutes (user_id BIGINT REFERENCES users(id), source VARCHAR(255) NOT NULL, PRIMARY KEY (user_id, source));
+        CREATE TABLE filter_rules (id SERIAL PRIMARY KEY, user_id BIGINT REFERENCES users(id), category VARCHAR(50) NOT NULL DEFAULT '', kind VARCHAR(10) NOT NULL, expression TEXT NOT NULL);
+        CREATE TABLE deliveries (id BIGSERIAL PRIMARY KEY, user_id BIGINT NOT NULL, article_url TEXT NOT NULL, category VARCHAR(50) NOT NULL);
+        CREATE TABLE bookmarks (user_id BIGINT NOT NULL, article_id BIGINT NOT NULL, PRIMARY KEY (user_id, article_id));
+        CREATE TABLE article_feedback (user_id BIGINT NOT NULL, article_id BIGINT NOT NULL, vote SMALLINT NOT NULL, PRIMARY KEY (user_id, article_id));
+        CREATE TABLE clicks (id BIGSERIAL PRIMARY KEY, user_id BIGINT NOT NULL, article_id BIGINT NOT NULL);
         CREATE TABLE sent_articles (
             id SERIAL PRIMARY KEY,
-            url VARCHAR(255) NOT NULL UNIQUE,
+            url TEXT NOT NULL UNIQUE,
             category VARCHAR(50) NOT NULL,
             sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
         );
+        CREATE TABLE jobs (
+            id BIGSERIAL PRIMARY KEY,
+            kind VARCHAR(50) NOT NULL,
+            payload JSONB NOT NULL DEFAULT '{}',
+            dedupe_key VARCHAR(200),
+            run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+            attempts INT NOT NULL DEFAULT 0,
+            locked_by VARCHAR(100),
+            locked_until TIMESTAMP WITH TIME ZONE,
+            last_error TEXT,
+            dead_at TIMESTAMP WITH TIME ZONE,
+            created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+        );
+        CREATE UNIQUE INDEX jobs_dedupe_key_idx ON jobs (dedupe_key) WHERE dedupe_key IS NOT NULL AND dead_at IS NULL;
     `)
 	if err != nil {
 		log.Fatalf("failed to create tables: %v", err)
@@ -87,14 +128,83 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
+// baselineSchema is the schema the first release created; Migrate must bring
+// such a database up to date.
+const baselineSchema = `
+    CREATE TABLE users (id BIGINT PRIMARY KEY);
+    CREATE TABLE subscriptions (
+        id SERIAL PRIMARY KEY,
+        user_id BIGINT REFERENCES users(id),
+        category VARCHAR(50) NOT NULL,
+        UNIQUE(user_id, category)
+    );
+    CREATE TABLE sent_articles (
+        id SERIAL PRIMARY KEY,
+        url VARCHAR(255) NOT NULL UNIQUE,
+        category VARCHAR(50) NOT NULL,
+        sent_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+    );
+    INSERT INTO users (id) VALUES (1);
+    INSERT INTO subscriptions (user_id, category) VALUES (1, 'technology');
+`
+
+func TestPostgresRepository_MigrateUpgradesBaselineSchema(t *testing.T) {
+	ctx := context.Background()
+
+	dbName := testDBName + "_migrate"
+	if _, err := pool.Exec(ctx, fmt.Sprintf("CREATE DATABASE %s", dbName)); err != nil {
+		t.Fatalf("failed to create db: %v", err)
+	}
+	defer pool.Exec(ctx, fmt.Sprintf("DROP DATABASE %s", dbName))
+
+	postgresRepo, err := repository.NewPostgresRepository(ctx, config.StorageConfig{
+		Username: "username1", Password: "password1", Host: "localhost", Port: 5432, Database: dbName, MaxConns: 2,
+	}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer postgresRepo.Conn().Close()
+
+	if _, err := postgresRepo.Conn().Exec(ctx, baselineSchema); err != nil {
+		t.Fatalf("failed to create baseline schema: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := postgresRepo.Migrate(ctx); err != nil {
+			t.Fatalf("Migrate run %d failed: %v", i+1, err)
+		}
+	}
+
+	var country, language string
+	err = postgresRepo.Conn().QueryRow(ctx, "SELECT country, language FROM subscriptions WHERE user_id = 1 AND category = 'technology'").Scan(&country, &language)
+	if err != nil {
+		t.Fatalf("expected the existing subscription to get country and language: %v", err)
+	}
+	if country != "" || language != "" {
+		t.Fatalf("expected empty filters on an existing subscription, got %q/%q", country, language)
+	}
+
+	long := entities.Article{URL: "https://example.com/" + strings.Repeat("a", 300)}
+	if _, err := repository.NewSentArticlesRepository(postgresRepo.Conn()).ClaimArticles(ctx, []entities.Article{long}, "tech"); err != nil {
+		t.Fatalf("expected sent_articles to take long URLs: %v", err)
+	}
+
+	subs, err := repository.NewSubscriptionRepository(postgresRepo.Conn()).GetSubscriptionsByUser(ctx, 1)
+	if err != nil || len(subs) != 1 || subs[0].ThreadID != 0 {
+		t.Fatalf("GetSubscriptionsByUser after migration = %v, %v", subs, err)
+	}
+	ids, err := repository.NewUserRepository(postgresRepo.Conn()).GetActiveUserIDs(ctx)
+	if err != nil || len(ids) != 1 || ids[0] != 1 {
+		t.Fatalf("GetActiveUserIDs after migration = %v, %v", ids, err)
+	}
+}
+
 func TestSaveAndCheckSentArticle(t *testing.T) {
 	ctx := context.Background()
 
 	article := &entities.Article{URL: "https://example.com/news1"}
 
-	err := sentRepo.SaveSentArticle(ctx, article, "tech")
-	if err != nil {
-		t.Fatalf("SaveSentArticle failed: %v", err)
+	if _, err := sentRepo.ClaimArticles(ctx, []entities.Article{*article}, "tech"); err != nil {
+		t.Fatalf("ClaimArticles failed: %v", err)
 	}
 
 	sent, err := sentRepo.IsArticleSent(ctx, article.URL)
@@ -105,3 +215,255 @@ func TestSaveAndCheckSentArticle(t *testing.T) {
 		t.Fatalf("expected article to be marked as sent")
 	}
 }
+
+func TestClaimArticlesOnce(t *testing.T) {
+	ctx := context.Background()
+
+	articles := []entities.Article{{URL: "https://example.com/claim1"}, {URL: "https://example.com/claim2"}}
+	claimed, err := sentRepo.ClaimArticles(ctx, articles, "tech")
+	if err != nil {
+		t.Fatalf("ClaimArticles failed: %v", err)
+	}
+	if len(claimed) != 2 {
+		t.Fatalf("expected both articles to be claimed, got %v", claimed)
+	}
+
+	claimed, err = sentRepo.ClaimArticles(ctx, articles[:1], "tech")
+	if err != nil {
+		t.Fatalf("ClaimArticles failed: %v", err)
+	}
+	if len(claimed) != 0 {
+		t.Fatalf("expected a claimed article not to be claimed again, got %v", claimed)
+	}
+}
+
+func TestClaimArticles_LongURLDoesNotBlockBatch(t *testing.T) {
+	ctx := context.Background()
+
+	long := entities.Article{URL: "https://example.com/" + strings.Repeat("a", 300)}
+	articles := []entities.Article{long, {URL: "https://example.com/short"}}
+	claimed, err := sentRepo.ClaimArticles(ctx, articles, "tech")
+	if err != nil {
+		t.Fatalf("ClaimArticles failed: %v", err)
+	}
+	if len(claimed) != 2 {
+		t.Fatalf("expected both articles to be claimed, got %v", claimed)
+	}
+}
+
+func TestJobRepository_ClaimSkipsLockedJobs(t *testing.T) {
+	ctx := context.Background()
+	jobs := repository.NewJobRepository(pool)
+
+	for _, key := range []string{"fetch_category:a", "fetch_category:b", "fetch_category:a"} {
+		_, err := jobs.Enqueue(ctx, &entities.Job{Kind: "fetch_category", Payload: []byte(`{}`), DedupeKey: key})
+		if err != nil {
+			t.Fatalf("Enqueue failed: %v", err)
+		}
+	}
+
+	first, err := jobs.Claim(ctx, "worker-1", time.Minute)
+	if err != nil || first == nil {
+		t.Fatalf("first Claim = %v, %v", first, err)
+	}
+	second, err := jobs.Claim(ctx, "worker-2", time.Minute)
+	if err != nil || second == nil {
+		t.Fatalf("second Claim = %v, %v", second, err)
+	}
+	if first.ID == second.ID {
+		t.Fatalf("both workers claimed job %d", first.ID)
+	}
+	// The duplicate key was not queued and both jobs are locked.
+	if third, err := jobs.Claim(ctx, "worker-3", time.Minute); err != nil || third != nil {
+		t.Fatalf("third Claim = %v, %v, want no job", third, err)
+	}
+
+	if err := jobs.Retry(ctx, first.ID, "worker-1", time.Now(), "boom"); err != nil {
+		t.Fatalf("Retry failed: %v", err)
+	}
+	retried, err := jobs.Claim(ctx, "worker-3", time.Minute)
+	if err != nil || retried == nil || retried.ID != first.ID || retried.Attempts != 2 {
+		t.Fatalf("Claim after Retry = %+v, %v", retried, err)
+	}
+	// Only the worker holding a job may finish it.
+	if err := jobs.Complete(ctx, second.ID, "worker-1"); !errors.Is(err, queue.ErrLeaseLost) {
+		t.Fatalf("Complete by another worker = %v, want ErrLeaseLost", err)
+	}
+	if err := jobs.Extend(ctx, retried.ID, "worker-3", time.Minute); err != nil {
+		t.Fatalf("Extend failed: %v", err)
+	}
+	if err := jobs.Complete(ctx, retried.ID, "worker-3"); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if err := jobs.Complete(ctx, second.ID, "worker-2"); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+}
+
+func TestJobRepository_EnqueueClaimedRollsBack(t *testing.T) {
+	ctx := context.Background()
+	jobs := repository.NewJobRepository(pool)
+	articles := []entities.Article{{URL: "http://example.com/queued"}}
+
+	// If the jobs can't be queued, the article stays unsent.
+	err := jobs.EnqueueClaimed(ctx, articles, "tech", func(claimed []string) ([]*entities.Job, error) {
+		return nil, errors.New("encoding failed")
+	})
+	if err == nil {
+		t.Fatal("expected EnqueueClaimed to fail")
+	}
+	if sent, err := sentRepo.IsArticleSent(ctx, articles[0].URL); err != nil || sent {
+		t.Fatalf("IsArticleSent after rollback = %v, %v", sent, err)
+	}
+
+	err = jobs.EnqueueClaimed(ctx, articles, "tech", func(claimed []string) ([]*entities.Job, error) {
+		if len(claimed) != 1 {
+			t.Errorf("claimed = %v, want the article", claimed)
+		}
+		return []*entities.Job{{Kind: "deliver_batch", Payload: []byte(`{}`)}}, nil
+	})
+	if err != nil {
+		t.Fatalf("EnqueueClaimed failed: %v", err)
+	}
+	if sent, err := sentRepo.IsArticleSent(ctx, articles[0].URL); err != nil || !sent {
+		t.Fatalf("IsArticleSent after commit = %v, %v", sent, err)
+	}
+	job, err := jobs.Claim(ctx, "worker-1", time.Minute)
+	if err != nil || job == nil || job.Kind != "deliver_batch" {
+		t.Fatalf("Claim = %+v, %v", job, err)
+	}
+	if err := jobs.Complete(ctx, job.ID, "worker-1"); err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+}
+
+func TestJobRepository_EnqueueClaimedLongURL(t *testing.T) {
+	ctx := context.Background()
+	jobs := repository.NewJobRepository(pool)
+	articles := []entities.Article{
+		{URL: "http://example.com/queued-" + strings.Repeat("b", 300)},
+		{URL: "http://example.com/queued-short"},
+	}
+
+	var claimed []string
+	err := jobs.EnqueueClaimed(ctx, articles, "tech", func(urls []string) ([]*entities.Job, error) {
+		claimed = urls
+		return nil, nil
+	})
+	if err != nil {
+		t.Fatalf("EnqueueClaimed failed: %v", err)
+	}
+	if len(claimed) != 2 {
+		t.Fatalf("expected both articles to be claimed, got %v", claimed)
+	}
+}
+
+func TestUserRepository_MigrateChat(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewUserRepository(pool)
+	subs := repository.NewSubscriptionRepository(pool)
+	const groupID, supergroupID = int64(-501), int64(-100501)
+
+	if err := users.SaveUser(ctx, &entities.User{ID: groupID, ChatType: entities.ChatGroup}); err != nil {
+		t.Fatalf("SaveUser failed: %v", err)
+	}
+	for _, category := range []string{"tech", "sports"} {
+		if err := subs.SaveSubscription(ctx, &entities.Subscription{UserID: groupID, Category: category}); err != nil {
+			t.Fatalf("SaveSubscription failed: %v", err)
+		}
+	}
+	if _, err := pool.Exec(ctx, "INSERT INTO bookmarks (user_id, article_id) VALUES ($1, 1)", groupID); err != nil {
+		t.Fatalf("insert bookmark: %v", err)
+	}
+
+	// A command from the supergroup may have arrived before the migration.
+	if err := users.SaveUser(ctx, &entities.User{ID: supergroupID, ChatType: entities.ChatSuperGroup}); err != nil {
+		t.Fatalf("SaveUser failed: %v", err)
+	}
+	if err := subs.SaveSubscription(ctx, &entities.Subscription{UserID: supergroupID, Category: "tech", Language: "en"}); err != nil {
+		t.Fatalf("SaveSubscription failed: %v", err)
+	}
+
+	for range 2 {
+		if err := users.MigrateChat(ctx, groupID, supergroupID); err != nil {
+			t.Fatalf("MigrateChat failed: %v", err)
+		}
+	}
+
+	if got, err := subs.GetSubscriptionsByUser(ctx, groupID); err != nil || len(got) != 0 {
+		t.Fatalf("group subscriptions = %v, %v", got, err)
+	}
+	got, err := subs.GetSubscriptionsByUser(ctx, supergroupID)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("supergroup subscriptions = %v, %v", got, err)
+	}
+	for _, sub := range got {
+		if sub.Category == "tech" && sub.Language != "en" {
+			t.Errorf("supergroup's own tech subscription was replaced: %+v", sub)
+		}
+	}
+	var bookmarks int
+	if err := pool.QueryRow(ctx, "SELECT COUNT(*) FROM bookmarks WHERE user_id = $1", supergroupID).Scan(&bookmarks); err != nil || bookmarks != 1 {
+		t.Fatalf("supergroup bookmarks = %d, %v", bookmarks, err)
+	}
+}
+
+func TestUserRepository_UnreachableChatsGetNoDeliveries(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewUserRepository(pool)
+	subs := repository.NewSubscriptionRepository(pool)
+	const chatID = int64(502)
+
+	if err := users.SaveUser(ctx, &entities.User{ID: chatID}); err != nil {
+		t.Fatalf("SaveUser failed: %v", err)
+	}
+	if err := subs.SaveSubscription(ctx, &entities.Subscription{UserID: chatID, Category: "unreachable"}); err != nil {
+		t.Fatalf("SaveSubscription failed: %v", err)
+	}
+	subscribed := func() bool {
+		all, err := subs.GetAllSubscriptions(ctx)
+		if err != nil {
+			t.Fatalf("GetAllSubscriptions failed: %v", err)
+		}
+		for _, sub := range all {
+			if sub.UserID == chatID {
+				return true
+			}
+		}
+		return false
+	}
+
+	if err := users.SetUnreachable(ctx, chatID); err != nil {
+		t.Fatalf("SetUnreachable failed: %v", err)
+	}
+	if subscribed() {
+		t.Fatal("unreachable chat still gets deliveries")
+	}
+	if _, err := users.TouchUser(ctx, chatID); err != nil {
+		t.Fatalf("TouchUser failed: %v", err)
+	}
+	if !subscribed() {
+		t.Fatal("chat that sent a command still gets no deliveries")
+	}
+}
+
+func TestSubscriptionRepository_KeepsForumTopic(t *testing.T) {
+	ctx := context.Background()
+	users := repository.NewUserRepository(pool)
+	subs := repository.NewSubscriptionRepository(pool)
+	const chatID = int64(-100503)
+
+	if err := users.SaveUser(ctx, &entities.User{ID: chatID, ChatType: entities.ChatSuperGroup}); err != nil {
+		t.Fatalf("SaveUser failed: %v", err)
+	}
+	// Subscribing again from another topic moves the deliveries there.
+	for _, threadID := range []int{7, 9} {
+		if err := subs.SaveSubscription(ctx, &entities.Subscription{UserID: chatID, Category: "tech", ThreadID: threadID}); err != nil {
+			t.Fatalf("SaveSubscription failed: %v", err)
+		}
+	}
+	got, err := subs.GetSubscriptionsByUser(ctx, chatID)
+	if err != nil || len(got) != 1 || got[0].ThreadID != 9 {
+		t.Fatalf("GetSubscriptionsByUser = %+v, %v", got, err)
+	}
+}
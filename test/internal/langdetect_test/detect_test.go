@@ -0,0 +1,40 @@
+package langdetect_test
+
+import (
+	"testing"
+
+	"tgbot/internal/langdetect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"Stocks rally as Fed signals pause in rate hikes", "en"},
+		{"Scholz kündigt neue Maßnahmen gegen die Inflation an", "de"},
+		{"Le Sénat adopte la réforme des retraites après un long débat", "fr"},
+		{"La selección española gana el Mundial femenino", "es"},
+		{"Il governo approva la manovra economica per il prossimo anno", "it"},
+		{"Lula anuncia novo programa de investimentos para o Nordeste", "pt"},
+		{"Kabinet wil meer geld voor de zorg en het onderwijs", "nl"},
+		{"Regeringen vill sänka skatten för pensionärer", "sv"},
+		{"Regjeringen vil kutte skatten for pensjonister", "no"},
+		{"В Москве открылась новая станция метро", "ru"},
+		{"У Києві відкрили нову станцію метро", "uk"},
+		{"Σεισμός στην Κρήτη", "el"},
+		{"東京で新しい駅が開業", "ja"},
+		{"北京发布新的经济政策", "zh"},
+		// Names and numbers alone say nothing about the language.
+		{"OpenAI", ""},
+		{"Tesla Q3 2024", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.expected, langdetect.Detect(tt.text))
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"tgbot/internal/metrics"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func scrape(t *testing.T, r *metrics.Registry) string {
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	return rec.Body.String()
+}
+
+func TestRegistry_Counters(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.CommandHandled("news", "ok")
+	r.CommandHandled("news", "ok")
+	r.CommandHandled("add", "send_error")
+	r.ArticlesFetched("technology", 20, 3)
+	r.MessageSent("forbidden")
+
+	body := scrape(t, r)
+	assert.Contains(t, body, "# TYPE tgbot_commands_total counter\n")
+	assert.Contains(t, body, `tgbot_commands_total{command="news",outcome="ok"} 2`)
+	assert.Contains(t, body, `tgbot_commands_total{command="add",outcome="send_error"} 1`)
+	assert.Contains(t, body, `tgbot_articles_fetched_total{category="technology"} 20`)
+	assert.Contains(t, body, `tgbot_articles_new_total{category="technology"} 3`)
+	assert.Contains(t, body, `tgbot_messages_total{result="forbidden"} 1`)
+}
+
+func TestRegistry_Histograms(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.NewsAPIRequest("200", 300*time.Millisecond)
+	r.NewsAPIRequest("200", 3*time.Second)
+	r.NewsCycle(45 * time.Second)
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `tgbot_newsapi_request_duration_seconds_bucket{status="200",le="0.25"} 0`)
+	assert.Contains(t, body, `tgbot_newsapi_request_duration_seconds_bucket{status="200",le="0.5"} 1`)
+	assert.Contains(t, body, `tgbot_newsapi_request_duration_seconds_bucket{status="200",le="5"} 2`)
+	assert.Contains(t, body, `tgbot_newsapi_request_duration_seconds_bucket{status="200",le="+Inf"} 2`)
+	assert.Contains(t, body, `tgbot_newsapi_request_duration_seconds_sum{status="200"} 3.3`)
+	assert.Contains(t, body, `tgbot_newsapi_request_duration_seconds_count{status="200"} 2`)
+	assert.Contains(t, body, `tgbot_news_cycle_duration_seconds_bucket{le="60"} 1`)
+	assert.Contains(t, body, "tgbot_news_cycle_duration_seconds_count 1")
+}
+
+func TestRegistry_EscapesLabelsAndCollects(t *testing.T) {
+	r := metrics.NewRegistry()
+	r.CommandHandled(`we"ird`, "ok")
+	r.AddCollector(func() []metrics.Sample {
+		return []metrics.Sample{{Name: "tgbot_db_pool_idle_conns", Help: "Idle connections.", Type: "gauge", Value: 2}}
+	})
+
+	body := scrape(t, r)
+	assert.Contains(t, body, `command="we\"ird"`)
+	assert.True(t, strings.Contains(body, "# TYPE tgbot_db_pool_idle_conns gauge\ntgbot_db_pool_idle_conns 2\n"))
+}
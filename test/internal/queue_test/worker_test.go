@@ -0,0 +1,247 @@
+package queue_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/queue"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore is a single-process stand-in for the jobs table.
+type memoryStore struct {
+	mu     sync.Mutex
+	nextID int64
+	jobs   map[int64]*storedJob
+}
+
+type storedJob struct {
+	job       entities.Job
+	lockedBy  string
+	extends   int
+	dead      bool
+	lastError string
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{jobs: make(map[int64]*storedJob)}
+}
+
+func (s *memoryStore) add(kind string, payload string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.jobs[s.nextID] = &storedJob{job: entities.Job{ID: s.nextID, Kind: kind, Payload: json.RawMessage(payload), RunAt: time.Now()}}
+	return s.nextID
+}
+
+func (s *memoryStore) Claim(ctx context.Context, workerID string, lease time.Duration) (*entities.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := int64(1); id <= s.nextID; id++ {
+		j, ok := s.jobs[id]
+		if !ok || j.lockedBy != "" || j.dead || j.job.RunAt.After(time.Now()) {
+			continue
+		}
+		j.lockedBy = workerID
+		j.job.Attempts++
+		job := j.job
+		return &job, nil
+	}
+	return nil, nil
+}
+
+// held returns the job if workerID holds it.
+func (s *memoryStore) held(id int64, workerID string) (*storedJob, error) {
+	j, ok := s.jobs[id]
+	if !ok || j.lockedBy != workerID {
+		return nil, queue.ErrLeaseLost
+	}
+	return j, nil
+}
+
+func (s *memoryStore) Extend(ctx context.Context, id int64, workerID string, lease time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.held(id, workerID)
+	if err != nil {
+		return err
+	}
+	j.extends++
+	return nil
+}
+
+func (s *memoryStore) Complete(ctx context.Context, id int64, workerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.held(id, workerID); err != nil {
+		return err
+	}
+	delete(s.jobs, id)
+	return nil
+}
+
+func (s *memoryStore) Retry(ctx context.Context, id int64, workerID string, runAt time.Time, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.held(id, workerID)
+	if err != nil {
+		return err
+	}
+	j.lockedBy, j.job.RunAt, j.lastError = "", runAt, lastError
+	return nil
+}
+
+func (s *memoryStore) Bury(ctx context.Context, id int64, workerID string, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, err := s.held(id, workerID)
+	if err != nil {
+		return err
+	}
+	j.lockedBy, j.dead, j.lastError = "", true, lastError
+	return nil
+}
+
+func (s *memoryStore) get(id int64) *storedJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.jobs[id]
+}
+
+func TestWorker_RunsAndCompletesJobs(t *testing.T) {
+	store := newMemoryStore()
+	id := store.add("greet", `{"name":"Ann"}`)
+	w := queue.NewWorker(store)
+
+	var got string
+	w.Handle("greet", func(ctx context.Context, payload json.RawMessage) error {
+		var p struct{ Name string }
+		require.NoError(t, json.Unmarshal(payload, &p))
+		got = p.Name
+		return nil
+	})
+
+	assert.True(t, w.RunOnce(context.Background()))
+	assert.Equal(t, "Ann", got)
+	assert.Nil(t, store.get(id))
+	assert.False(t, w.RunOnce(context.Background()))
+}
+
+func TestWorker_RetriesWithBackoffThenBuries(t *testing.T) {
+	store := newMemoryStore()
+	id := store.add("flaky", `{}`)
+	w := queue.NewWorker(store, queue.WithMaxAttempts(2))
+	w.Handle("flaky", func(ctx context.Context, payload json.RawMessage) error {
+		return errors.New("upstream down")
+	})
+
+	assert.True(t, w.RunOnce(context.Background()))
+	job := store.get(id)
+	require.NotNil(t, job)
+	assert.False(t, job.dead)
+	assert.Equal(t, "upstream down", job.lastError)
+	assert.True(t, job.job.RunAt.After(time.Now()), "retry must be delayed")
+
+	// Make the retry due now.
+	store.mu.Lock()
+	job.job.RunAt = time.Now()
+	store.mu.Unlock()
+
+	assert.True(t, w.RunOnce(context.Background()))
+	assert.True(t, store.get(id).dead)
+	assert.False(t, w.RunOnce(context.Background()))
+}
+
+func TestWorker_UnknownKindAndPanicsFailTheJob(t *testing.T) {
+	store := newMemoryStore()
+	unknown := store.add("mystery", `{}`)
+	panicking := store.add("explode", `{}`)
+	w := queue.NewWorker(store, queue.WithMaxAttempts(1))
+	w.Handle("explode", func(ctx context.Context, payload json.RawMessage) error {
+		panic("kaboom")
+	})
+
+	assert.True(t, w.RunOnce(context.Background()))
+	assert.True(t, w.RunOnce(context.Background()))
+	assert.Contains(t, store.get(unknown).lastError, "no handler")
+	assert.Contains(t, store.get(panicking).lastError, "kaboom")
+}
+
+func TestWorker_RunProcessesQueueConcurrently(t *testing.T) {
+	store := newMemoryStore()
+	for range 10 {
+		store.add("count", `{}`)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	done := 0
+	w := queue.NewWorker(store, queue.WithConcurrency(3), queue.WithPollInterval(5*time.Millisecond))
+	w.Handle("count", func(ctx context.Context, payload json.RawMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		return nil
+	})
+	go w.Run(ctx)
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return done == 10
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWorker_ExtendsLeaseWhileRunning(t *testing.T) {
+	store := newMemoryStore()
+	id := store.add("slow", `{}`)
+	w := queue.NewWorker(store, queue.WithLease(30*time.Millisecond))
+
+	var extends int
+	w.Handle("slow", func(ctx context.Context, payload json.RawMessage) error {
+		time.Sleep(100 * time.Millisecond)
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		extends = store.jobs[id].extends
+		return nil
+	})
+
+	assert.True(t, w.RunOnce(context.Background()))
+	assert.Greater(t, extends, 0)
+	assert.Nil(t, store.get(id))
+}
+
+func TestWorker_LostLeaseCancelsJob(t *testing.T) {
+	store := newMemoryStore()
+	id := store.add("slow", `{}`)
+	w := queue.NewWorker(store, queue.WithLease(30*time.Millisecond))
+
+	w.Handle("slow", func(ctx context.Context, payload json.RawMessage) error {
+		// Another worker claims the job as if this one had stalled.
+		store.mu.Lock()
+		store.jobs[id].lockedBy = "other"
+		store.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second):
+			return errors.New("job not cancelled")
+		}
+	})
+
+	assert.True(t, w.RunOnce(context.Background()))
+	// The job is left to the worker that holds it now.
+	job := store.get(id)
+	require.NotNil(t, job)
+	assert.Equal(t, "other", job.lockedBy)
+	assert.Empty(t, job.lastError)
+}
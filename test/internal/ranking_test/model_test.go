@@ -0,0 +1,61 @@
+package ranking_test
+
+import (
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/ranking"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func urls(articles []entities.Article) []string {
+	var result []string
+	for _, a := range articles {
+		result = append(result, a.URL)
+	}
+	return result
+}
+
+func TestModel_RanksLikedSourcesAndTermsFirst(t *testing.T) {
+	model := ranking.NewModel([]entities.Feedback{
+		{Vote: entities.VoteUp, Category: "technology", Article: entities.Article{SourceID: "wired", Title: "Quantum computing breakthrough"}},
+		{Vote: entities.VoteUp, Category: "technology", Article: entities.Article{SourceID: "wired", Title: "Quantum sensors explained"}},
+		{Vote: entities.VoteDown, Category: "technology", Article: entities.Article{SourceID: "gossip", Title: "Celebrity gadgets"}},
+	})
+
+	articles := []entities.Article{
+		{URL: "1", SourceID: "gossip", Title: "Celebrity phones"},
+		{URL: "2", SourceID: "other", Title: "Battery chemistry"},
+		{URL: "3", SourceID: "other", Title: "Quantum networking"},
+		{URL: "4", SourceID: "wired", Title: "Battery chemistry"},
+	}
+	ranked := model.Rank("technology", articles)
+
+	// A liked term scores as much as a liked source; ties keep their order.
+	assert.Equal(t, []string{"3", "4", "2", "1"}, urls(ranked))
+	assert.Equal(t, "1", articles[0].URL, "input must not be reordered")
+}
+
+func TestModel_WithoutFeedbackKeepsOrder(t *testing.T) {
+	articles := []entities.Article{{URL: "1", Title: "First"}, {URL: "2", Title: "Second"}}
+
+	var nilModel *ranking.Model
+	assert.Equal(t, articles, nilModel.Rank("technology", articles))
+	assert.Equal(t, articles, ranking.NewModel(nil).Rank("technology", articles))
+	assert.Zero(t, nilModel.Score("technology", &articles[0]))
+}
+
+func TestModel_SmoothingDampsSingleVotes(t *testing.T) {
+	model := ranking.NewModel([]entities.Feedback{
+		{Vote: entities.VoteUp, Article: entities.Article{SourceID: "once"}},
+		{Vote: entities.VoteUp, Article: entities.Article{SourceID: "often"}},
+		{Vote: entities.VoteUp, Article: entities.Article{SourceID: "often"}},
+		{Vote: entities.VoteUp, Article: entities.Article{SourceID: "often"}},
+	})
+
+	once := model.Score("", &entities.Article{SourceID: "once"})
+	often := model.Score("", &entities.Article{SourceID: "often"})
+	assert.InDelta(t, 1.0/3, once, 1e-9)
+	assert.Greater(t, often, once)
+}
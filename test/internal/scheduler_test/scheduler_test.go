@@ -0,0 +1,161 @@
+package scheduler_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tgbot/internal/scheduler"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_Cron(t *testing.T) {
+	from := time.Date(2024, 3, 15, 10, 7, 30, 0, time.UTC) // Friday
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 3, 15, 10, 8, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 3, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 3, 15, 13, 0, 0, 0, time.UTC)},
+		{"30 6 * * 1,3", time.Date(2024, 3, 18, 6, 30, 0, 0, time.UTC)},
+		{"0 0 1 * *", time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2024, 3, 17, 12, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 3, 16, 0, 0, 0, 0, time.UTC)},
+		{"@every 90s", from.Add(90 * time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := scheduler.Parse(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(from))
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "5-1 * * * *", "*/0 * * * *", "0 0 31 2 *", "@every -1m", "@every soon"} {
+		_, err := scheduler.Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestMinInterval(t *testing.T) {
+	from := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+	for spec, want := range map[string]time.Duration{
+		"*/5 * * * *":  5 * time.Minute,
+		"0,1 * * * *":  time.Minute,
+		"@every 2h":    2 * time.Hour,
+		"0 9,18 * * *": 9 * time.Hour,
+	} {
+		schedule, err := scheduler.Parse(spec)
+		require.NoError(t, err)
+		assert.Equal(t, want, scheduler.MinInterval(schedule, from), spec)
+	}
+}
+
+type memoryStore struct {
+	mu   sync.Mutex
+	runs map[string]time.Time
+}
+
+func (s *memoryStore) GetLastRuns(ctx context.Context) (map[string]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := make(map[string]time.Time, len(s.runs))
+	for name, at := range s.runs {
+		runs[name] = at
+	}
+	return runs, nil
+}
+
+func (s *memoryStore) SetLastRun(ctx context.Context, name string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.runs[name] = at
+	return nil
+}
+
+func TestScheduler_RunsJobsAndPersistsLastRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &memoryStore{runs: map[string]time.Time{}}
+
+	var runs atomic.Int32
+	s := scheduler.New(scheduler.WithStore(store))
+	require.NoError(t, s.Sync([]scheduler.Job{{
+		Name: "fast",
+		Spec: "@every 20ms",
+		Run:  func(ctx context.Context) { runs.Add(1) },
+	}}))
+	go s.Run(ctx)
+
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+	lastRuns, _ := store.GetLastRuns(ctx)
+	assert.WithinDuration(t, time.Now(), lastRuns["fast"], time.Second)
+}
+
+func TestScheduler_SkipsOverlappingRuns(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var running, maxRunning, runs atomic.Int32
+	s := scheduler.New()
+	require.NoError(t, s.Sync([]scheduler.Job{{
+		Name: "slow",
+		Spec: "@every 10ms",
+		Run: func(ctx context.Context) {
+			n := running.Add(1)
+			if n > maxRunning.Load() {
+				maxRunning.Store(n)
+			}
+			runs.Add(1)
+			time.Sleep(50 * time.Millisecond)
+			running.Add(-1)
+		},
+	}}))
+	go s.Run(ctx)
+
+	assert.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(1), maxRunning.Load())
+}
+
+func TestScheduler_ResumesFromLastRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	store := &memoryStore{runs: map[string]time.Time{
+		// Ran just now: the restart must not run it again right away.
+		"recent": time.Now(),
+		// Overdue: runs once on startup.
+		"overdue": time.Now().Add(-2 * time.Hour),
+	}}
+
+	var recent, overdue atomic.Int32
+	s := scheduler.New(scheduler.WithStore(store))
+	require.NoError(t, s.Sync([]scheduler.Job{
+		{Name: "recent", Spec: "@every 1h", Run: func(ctx context.Context) { recent.Add(1) }},
+		{Name: "overdue", Spec: "@every 1h", Run: func(ctx context.Context) { overdue.Add(1) }},
+	}))
+	go s.Run(ctx)
+
+	assert.Eventually(t, func() bool { return overdue.Load() == 1 }, time.Second, 5*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), recent.Load())
+	assert.Equal(t, int32(1), overdue.Load())
+}
+
+func TestScheduler_SyncReportsInvalidSpecs(t *testing.T) {
+	s := scheduler.New()
+	err := s.Sync([]scheduler.Job{
+		{Name: "good", Spec: "@hourly", Run: func(ctx context.Context) {}},
+		{Name: "bad", Spec: "every hour", Run: func(ctx context.Context) {}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "job bad")
+}
@@ -0,0 +1,149 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeAdmin struct {
+	users  []entities.User
+	search string
+	banned map[int64]bool
+}
+
+func (f *fakeAdmin) ListUsers(ctx context.Context, search string, limit, offset int) ([]entities.User, error) {
+	f.search = search
+	return f.users, nil
+}
+
+func (f *fakeAdmin) SetBanned(ctx context.Context, userID int64, banned bool) error {
+	f.banned[userID] = banned
+	return nil
+}
+
+func (f *fakeAdmin) GetDeliveries(ctx context.Context, userID int64, limit int) ([]entities.Delivery, error) {
+	return nil, nil
+}
+
+type fakeSubscriptions struct {
+	saved []entities.Subscription
+}
+
+func (f *fakeSubscriptions) SaveSubscription(ctx context.Context, user *entities.User, subscription *entities.Subscription) error {
+	f.saved = append(f.saved, *subscription)
+	return nil
+}
+
+func (f *fakeSubscriptions) GetUserSubscriptions(ctx context.Context, userID int64) ([]entities.Subscription, error) {
+	return f.saved, nil
+}
+
+func (f *fakeSubscriptions) DeleteSubscription(ctx context.Context, userID int64, category string) (bool, error) {
+	return false, nil
+}
+
+type fakeCycle struct {
+	running bool
+}
+
+func (f *fakeCycle) TriggerNewsCycle(ctx context.Context) bool {
+	if f.running {
+		return false
+	}
+	f.running = true
+	return true
+}
+
+func (f *fakeCycle) Categories(ctx context.Context) []string {
+	return []string{"technology", "science"}
+}
+
+func newHandler() (*server.AdminHandler, *fakeAdmin, *fakeSubscriptions) {
+	admin := &fakeAdmin{users: []entities.User{{ID: 1, Role: entities.RoleUser}}, banned: map[int64]bool{}}
+	subs := &fakeSubscriptions{}
+	cycle := &fakeCycle{}
+	return server.NewAdminHandler(context.Background(), "secret", admin, subs, cycle, cycle), admin, subs
+}
+
+func do(h http.Handler, method, target, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminHandler_RequiresToken(t *testing.T) {
+	h, _, _ := newHandler()
+
+	assert.Equal(t, http.StatusUnauthorized, do(h, "GET", "/admin/users", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(h, "GET", "/admin/users", "wrong", "").Code)
+	assert.Equal(t, http.StatusOK, do(h, "GET", "/admin/users", "secret", "").Code)
+}
+
+func TestAdminHandler_ListUsers(t *testing.T) {
+	h, admin, _ := newHandler()
+
+	rec := do(h, "GET", "/admin/users?q=42&limit=10", "secret", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"id":1,"role":"user"`)
+
+	var users []entities.User
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&users))
+	assert.Len(t, users, 1)
+	assert.Equal(t, "42", admin.search)
+
+	assert.Equal(t, http.StatusBadRequest, do(h, "GET", "/admin/users?limit=-1", "secret", "").Code)
+}
+
+func TestAdminHandler_Subscriptions(t *testing.T) {
+	h, _, subs := newHandler()
+
+	rec := do(h, "POST", "/admin/users/7/subscriptions", "secret", `{"category":"Science","country":"us"}`)
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Len(t, subs.saved, 1)
+	assert.Equal(t, entities.Subscription{UserID: 7, Category: "science", Country: "us"}, subs.saved[0])
+
+	assert.JSONEq(t, `{"id":0,"user_id":7,"category":"science","country":"us","language":""}`, rec.Body.String())
+
+	rec = do(h, "POST", "/admin/users/7/subscriptions", "secret", `{"category":"sports"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = do(h, "POST", "/admin/users/7/subscriptions", "secret", `{"category":"science","country":"usa"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	rec = do(h, "POST", "/admin/users/7/subscriptions", "secret", `{"category":"science","language":"xx"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Len(t, subs.saved, 1)
+
+	rec = do(h, "DELETE", "/admin/users/7/subscriptions/health", "secret", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestAdminHandler_Ban(t *testing.T) {
+	h, admin, _ := newHandler()
+
+	assert.Equal(t, http.StatusOK, do(h, "POST", "/admin/users/5/ban", "secret", "").Code)
+	assert.True(t, admin.banned[5])
+	assert.Equal(t, http.StatusOK, do(h, "POST", "/admin/users/5/unban", "secret", "").Code)
+	assert.False(t, admin.banned[5])
+	assert.Equal(t, http.StatusBadRequest, do(h, "POST", "/admin/users/abc/ban", "secret", "").Code)
+}
+
+func TestAdminHandler_NewsCycle(t *testing.T) {
+	h, _, _ := newHandler()
+
+	assert.Equal(t, http.StatusAccepted, do(h, "POST", "/admin/news-cycle", "secret", "").Code)
+	assert.Equal(t, http.StatusConflict, do(h, "POST", "/admin/news-cycle", "secret", "").Code)
+}
@@ -0,0 +1,99 @@
+package server_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"tgbot/internal/server"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ok(ctx context.Context) error { return nil }
+
+func failing(ctx context.Context) error { return errors.New("boom") }
+
+type readiness struct {
+	Status string `json:"status"`
+	Checks map[string]struct {
+		Status string `json:"status"`
+		Error  string `json:"error"`
+	} `json:"checks"`
+}
+
+func readyz(t *testing.T, h *server.HealthHandler) (int, readiness) {
+	rec := httptest.NewRecorder()
+	h.Readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	var report readiness
+	require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+	return rec.Code, report
+}
+
+func TestHealthHandler_Healthz(t *testing.T) {
+	h := server.NewHealthHandler(server.HealthCheck{Name: "database", Critical: true, Check: failing})
+
+	rec := httptest.NewRecorder()
+	h.Healthz(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestHealthHandler_Readyz(t *testing.T) {
+	tests := []struct {
+		name           string
+		checks         []server.HealthCheck
+		expectedCode   int
+		expectedStatus string
+	}{
+		{
+			name:           "All ok",
+			checks:         []server.HealthCheck{{Name: "database", Critical: true, Check: ok}, {Name: "newsapi", Check: ok}},
+			expectedCode:   http.StatusOK,
+			expectedStatus: "ok",
+		},
+		{
+			name:           "Non-critical failure",
+			checks:         []server.HealthCheck{{Name: "database", Critical: true, Check: ok}, {Name: "newsapi", Check: failing}},
+			expectedCode:   http.StatusOK,
+			expectedStatus: "degraded",
+		},
+		{
+			name:           "Critical failure",
+			checks:         []server.HealthCheck{{Name: "database", Critical: true, Check: failing}, {Name: "newsapi", Check: failing}},
+			expectedCode:   http.StatusServiceUnavailable,
+			expectedStatus: "unavailable",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, report := readyz(t, server.NewHealthHandler(tt.checks...))
+			assert.Equal(t, tt.expectedCode, code)
+			assert.Equal(t, tt.expectedStatus, report.Status)
+			assert.Len(t, report.Checks, len(tt.checks))
+		})
+	}
+
+	t.Run("Failure details", func(t *testing.T) {
+		_, report := readyz(t, server.NewHealthHandler(server.HealthCheck{Name: "newsapi", Check: failing}))
+		assert.Equal(t, "failing", report.Checks["newsapi"].Status)
+		assert.Equal(t, "boom", report.Checks["newsapi"].Error)
+	})
+}
+
+func TestRecencyCheck(t *testing.T) {
+	never := func() time.Time { return time.Time{} }
+	recent := func() time.Time { return time.Now().Add(-time.Minute) }
+	stale := func() time.Time { return time.Now().Add(-time.Hour) }
+	ctx := context.Background()
+
+	assert.NoError(t, server.RecencyCheck(never, time.Now(), 5*time.Minute)(ctx), "warming up")
+	assert.Error(t, server.RecencyCheck(never, time.Now().Add(-time.Hour), 5*time.Minute)(ctx))
+	assert.NoError(t, server.RecencyCheck(recent, time.Now().Add(-time.Hour), 5*time.Minute)(ctx))
+	assert.Error(t, server.RecencyCheck(stale, time.Now().Add(-time.Hour), 5*time.Minute)(ctx))
+}
@@ -0,0 +1,59 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeReader struct {
+	err error
+}
+
+// ReadPage takes tokens "1" and "2" for the articles; any other token is
+// invalid.
+func (f *fakeReader) ReadPage(ctx context.Context, token string) (*entities.Article, []string, error) {
+	switch {
+	case f.err != nil:
+		return nil, nil, f.err
+	case token == "1":
+		return &entities.Article{ID: 1, Title: "Tram <line> approved", URL: "https://example.com/a", SourceName: "City News"},
+			[]string{"First paragraph.", "Second & last."}, nil
+	case token == "2":
+		return &entities.Article{ID: 2, Title: "Video only"}, nil, nil
+	}
+	return nil, nil, nil
+}
+
+func readerMux(reader server.ReaderService) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET "+server.ReaderPath+"{token}", server.NewReaderHandler(reader))
+	return mux
+}
+
+func TestReaderHandler(t *testing.T) {
+	mux := readerMux(&fakeReader{})
+
+	rec := do(mux, "GET", "/read/1", "", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/html; charset=utf-8", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "<h1>Tram &lt;line&gt; approved</h1>")
+	assert.Contains(t, body, "<address>City News</address>")
+	assert.Contains(t, body, "<p>First paragraph.</p>\n<p>Second &amp; last.</p>")
+	assert.Contains(t, body, `<a href="https://example.com/a">`)
+
+	assert.Equal(t, http.StatusNotFound, do(mux, "GET", "/read/2", "", "").Code, "no text")
+	assert.Equal(t, http.StatusNotFound, do(mux, "GET", "/read/abc", "", "").Code, "invalid token")
+}
+
+func TestReaderHandler_StoreError(t *testing.T) {
+	mux := readerMux(&fakeReader{err: errors.New("db down")})
+	assert.Equal(t, http.StatusServiceUnavailable, do(mux, "GET", "/read/1", "", "").Code)
+}
@@ -0,0 +1,56 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tgbot/internal/server"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClicks struct {
+	opened []string
+	err    error
+}
+
+func (f *fakeClicks) Open(ctx context.Context, token string) (string, bool, error) {
+	if f.err != nil {
+		return "", false, f.err
+	}
+	if token != "good" {
+		return "", false, nil
+	}
+	f.opened = append(f.opened, token)
+	return "https://example.com/article", true, nil
+}
+
+func redirectMux(clicks server.ClickService) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("GET "+server.RedirectPath+"{token}", server.NewRedirectHandler(clicks))
+	return mux
+}
+
+func TestRedirectHandler(t *testing.T) {
+	clicks := &fakeClicks{}
+	mux := redirectMux(clicks)
+
+	rec := do(mux, "GET", "/r/good", "", "")
+	assert.Equal(t, http.StatusFound, rec.Code)
+	assert.Equal(t, "https://example.com/article", rec.Header().Get("Location"))
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, []string{"good"}, clicks.opened)
+
+	assert.Equal(t, http.StatusNotFound, do(mux, "GET", "/r/forged", "", "").Code)
+}
+
+func TestRedirectHandler_StoreError(t *testing.T) {
+	mux := redirectMux(&fakeClicks{err: errors.New("db down")})
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest("GET", "/r/good", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -0,0 +1,70 @@
+package service_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingFetcher struct {
+	calls int32
+	delay time.Duration
+}
+
+func (f *countingFetcher) GetNews(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+	atomic.AddInt32(&f.calls, 1)
+	time.Sleep(f.delay)
+	return []entities.Article{{Title: query.Category, URL: "http://example.com/" + query.Category}}, nil
+}
+
+func TestCachedNewsService_ServesFromCache(t *testing.T) {
+	fetcher := &countingFetcher{}
+	cached := service.NewCachedNewsService(fetcher, "newsapi", service.NewMemoryCache(), time.Minute)
+
+	for i := 0; i < 3; i++ {
+		articles, err := cached.GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
+		assert.NoError(t, err)
+		assert.Equal(t, "technology", articles[0].Title)
+	}
+	_, err := cached.GetNews(context.Background(), entities.NewsQuery{Category: "science"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetcher.calls))
+}
+
+func TestCachedNewsService_ExpiresEntries(t *testing.T) {
+	fetcher := &countingFetcher{}
+	cached := service.NewCachedNewsService(fetcher, "newsapi", service.NewMemoryCache(), 10*time.Millisecond)
+
+	_, _ = cached.GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
+	time.Sleep(20 * time.Millisecond)
+	_, _ = cached.GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&fetcher.calls))
+}
+
+func TestCachedNewsService_CollapsesConcurrentRequests(t *testing.T) {
+	fetcher := &countingFetcher{delay: 50 * time.Millisecond}
+	cached := service.NewCachedNewsService(fetcher, "newsapi", service.NewMemoryCache(), time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			articles, err := cached.GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
+			assert.NoError(t, err)
+			assert.Len(t, articles, 1)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetcher.calls))
+}
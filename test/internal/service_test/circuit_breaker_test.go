@@ -0,0 +1,62 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"tgbot/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker_OpensAfterThreshold(t *testing.T) {
+	breaker := service.NewCircuitBreaker(2, time.Hour)
+
+	assert.True(t, breaker.Allow())
+	breaker.Record(errors.New("timeout"))
+	assert.Equal(t, service.CircuitClosed, breaker.State())
+
+	assert.True(t, breaker.Allow())
+	breaker.Record(errors.New("timeout"))
+	assert.Equal(t, service.CircuitOpen, breaker.State())
+	assert.False(t, breaker.Allow())
+}
+
+func TestCircuitBreaker_OpensOnRateLimit(t *testing.T) {
+	breaker := service.NewCircuitBreaker(5, time.Hour)
+
+	breaker.Record(&service.APIError{StatusCode: 429, Code: "rateLimited"})
+	assert.Equal(t, service.CircuitOpen, breaker.State())
+	assert.False(t, breaker.Allow())
+}
+
+func TestCircuitBreaker_HalfOpenProbe(t *testing.T) {
+	breaker := service.NewCircuitBreaker(1, 10*time.Millisecond)
+
+	breaker.Record(errors.New("timeout"))
+	assert.False(t, breaker.Allow())
+
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+
+	breaker.Record(nil)
+	assert.Equal(t, service.CircuitClosed, breaker.State())
+	assert.True(t, breaker.Allow())
+}
+
+func TestCircuitBreaker_ReleasedProbe(t *testing.T) {
+	breaker := service.NewCircuitBreaker(1, 10*time.Millisecond)
+
+	breaker.Record(errors.New("timeout"))
+	time.Sleep(20 * time.Millisecond)
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+
+	// The probe was cancelled: the next request probes again.
+	breaker.Release()
+	assert.True(t, breaker.Allow())
+	breaker.Record(nil)
+	assert.Equal(t, service.CircuitClosed, breaker.State())
+}
@@ -0,0 +1,61 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"tgbot/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyPool_RoundRobin(t *testing.T) {
+	pool := service.NewKeyPool([]string{"a", "b", "c"}, service.RoundRobin)
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		key, err := pool.Acquire()
+		assert.NoError(t, err)
+		got = append(got, key)
+	}
+	assert.Equal(t, []string{"a", "b", "c", "a"}, got)
+}
+
+func TestKeyPool_LeastUsed(t *testing.T) {
+	pool := service.NewKeyPool([]string{"a", "b"}, service.LeastUsed)
+
+	first, _ := pool.Acquire()
+	second, _ := pool.Acquire()
+	assert.NotEqual(t, first, second)
+}
+
+func TestKeyPool_SkipsDisabledKeys(t *testing.T) {
+	pool := service.NewKeyPool([]string{"a", "b"}, service.RoundRobin)
+
+	pool.Report("a", &service.APIError{StatusCode: 401, Code: "apiKeyDisabled"})
+	for i := 0; i < 3; i++ {
+		key, err := pool.Acquire()
+		assert.NoError(t, err)
+		assert.Equal(t, "b", key)
+	}
+
+	pool.Report("b", &service.APIError{StatusCode: 429, Code: "apiKeyExhausted"})
+	_, err := pool.Acquire()
+	assert.ErrorIs(t, err, service.ErrNoAvailableKeys)
+	assert.ErrorIs(t, err, service.ErrQuotaExceeded)
+}
+
+func TestKeyPool_RateLimitedKeysCoolDown(t *testing.T) {
+	pool := service.NewKeyPool([]string{"a", "b"}, service.RoundRobin)
+
+	pool.Report("a", &service.APIError{StatusCode: 429, Code: "rateLimited", RetryAfter: time.Minute})
+	pool.Report("b", &service.APIError{StatusCode: 429, Code: "rateLimited"})
+
+	statuses := pool.Statuses()
+	assert.WithinDuration(t, time.Now().Add(time.Minute), statuses[0].DisabledUntil, 5*time.Second)
+	assert.WithinDuration(t, time.Now().Add(15*time.Minute), statuses[1].DisabledUntil, 5*time.Second)
+
+	pool.Report("a", &service.APIError{StatusCode: 429, Code: "apiKeyExhausted"})
+	midnight := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	assert.True(t, midnight.Equal(pool.Statuses()[0].DisabledUntil), "exhausted keys rest until the daily reset")
+}
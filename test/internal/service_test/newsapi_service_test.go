@@ -0,0 +1,199 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestService(url string) *service.NewsAPIService {
+	return service.NewNewsAPIService([]string{"test-key"},
+		service.WithBaseURL(url),
+		service.WithRetries(2, time.Millisecond),
+	)
+}
+
+func TestNewsAPIService_GetNews(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/top-headlines", r.URL.Path)
+		assert.Equal(t, "technology", r.URL.Query().Get("category"))
+		assert.Equal(t, "test-key", r.Header.Get("X-Api-Key"))
+		w.Write([]byte(`{"status":"ok","articles":[{"source":{"id":"wired","name":"Wired"},"author":"Jane Doe","title":"Title","description":"Desc","url":"http://example.com","urlToImage":"http://example.com/a.jpg","publishedAt":"2025-06-16T12:00:00Z"}]}`))
+	}))
+	defer server.Close()
+
+	articles, err := newTestService(server.URL).GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
+	assert.NoError(t, err)
+	assert.Len(t, articles, 1)
+	assert.Equal(t, "Title", articles[0].Title)
+	assert.Equal(t, "http://example.com", articles[0].URL)
+	assert.Equal(t, "Wired", articles[0].SourceName)
+	assert.Equal(t, "Jane Doe", articles[0].Author)
+	assert.Equal(t, "http://example.com/a.jpg", articles[0].ImageURL)
+}
+
+func TestNewsAPIService_RetriesServerErrors(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status":"error","code":"unexpectedError","message":"boom"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"ok","articles":[]}`))
+	}))
+	defer server.Close()
+
+	articles, err := newTestService(server.URL).GetNews(context.Background(), entities.NewsQuery{Category: "science"})
+	assert.NoError(t, err)
+	assert.Empty(t, articles)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestNewsAPIService_ErrorClassification(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		expected error
+	}{
+		{
+			name:     "Invalid key",
+			status:   http.StatusUnauthorized,
+			body:     `{"status":"error","code":"apiKeyInvalid","message":"Your API key is invalid"}`,
+			expected: service.ErrAPIKeyInvalid,
+		},
+		{
+			name:     "Rate limited",
+			status:   http.StatusTooManyRequests,
+			body:     `{"status":"error","code":"rateLimited","message":"Too many requests"}`,
+			expected: service.ErrRateLimited,
+		},
+		{
+			name:     "Invalid parameter",
+			status:   http.StatusBadRequest,
+			body:     `{"status":"error","code":"parameterInvalid","message":"Bad category"}`,
+			expected: service.ErrParameterInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			_, err := newTestService(server.URL).GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
+			assert.True(t, errors.Is(err, tt.expected))
+
+			var apiErr *service.APIError
+			assert.True(t, errors.As(err, &apiErr))
+			assert.NotEmpty(t, apiErr.Message)
+			assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		})
+	}
+}
+
+func TestNewsAPIService_RotatesExhaustedKeys(t *testing.T) {
+	var exhaustedCalls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") == "exhausted-key" {
+			atomic.AddInt32(&exhaustedCalls, 1)
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"error","code":"apiKeyExhausted","message":"No more requests"}`))
+			return
+		}
+		w.Write([]byte(`{"status":"ok","articles":[]}`))
+	}))
+	defer server.Close()
+
+	newsService := service.NewNewsAPIService([]string{"exhausted-key", "good-key"},
+		service.WithBaseURL(server.URL),
+		service.WithRetries(0, time.Millisecond),
+	)
+
+	for i := 0; i < 3; i++ {
+		_, err := newsService.GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&exhaustedCalls))
+
+	statuses := newsService.KeyStatuses(context.Background())
+	assert.Len(t, statuses, 2)
+	assert.False(t, statuses[0].DisabledUntil.IsZero())
+	assert.True(t, statuses[1].DisabledUntil.IsZero())
+	assert.Equal(t, 3, statuses[1].Requests)
+}
+
+func TestNewsAPIService_AllKeysRefused(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Api-Key") == "exhausted-key" {
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":"error","code":"apiKeyExhausted","message":"No more requests"}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"status":"error","code":"apiKeyDisabled","message":"Key disabled"}`))
+	}))
+	defer server.Close()
+
+	newsService := service.NewNewsAPIService([]string{"exhausted-key", "disabled-key"},
+		service.WithBaseURL(server.URL),
+		service.WithRetries(0, time.Millisecond),
+	)
+
+	_, err := newsService.GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
+	assert.ErrorIs(t, err, service.ErrNoAvailableKeys)
+	assert.ErrorIs(t, err, service.ErrQuotaExceeded)
+}
+
+func TestNewsAPIService_SendsCountryAndLanguage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "technology", r.URL.Query().Get("category"))
+		assert.Equal(t, "ru", r.URL.Query().Get("country"))
+		assert.Equal(t, "ru", r.URL.Query().Get("language"))
+		w.Write([]byte(`{"status":"ok","articles":[]}`))
+	}))
+	defer server.Close()
+
+	_, err := newTestService(server.URL).GetNews(context.Background(), entities.NewsQuery{Category: "technology", Country: "ru", Language: "ru"})
+	assert.NoError(t, err)
+}
+
+func TestNewsAPIService_CancelledProbeReleasesBreaker(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"ok","articles":[]}`))
+	}))
+	defer server.Close()
+
+	breaker := service.NewCircuitBreaker(1, time.Millisecond)
+	breaker.Record(errors.New("timeout"))
+	time.Sleep(5 * time.Millisecond)
+	newsService := service.NewNewsAPIService([]string{"test-key"},
+		service.WithBaseURL(server.URL),
+		service.WithCircuitBreaker(breaker),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := newsService.GetNews(ctx, entities.NewsQuery{Category: "science"})
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, service.ErrCircuitOpen)
+
+	_, err = newsService.GetNews(context.Background(), entities.NewsQuery{Category: "science"})
+	assert.NoError(t, err)
+	assert.Equal(t, service.CircuitClosed, breaker.State())
+}
@@ -0,0 +1,79 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tgbot/internal/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rssFeed = `<?xml version="1.0"?>
+<rss version="2.0"><channel>
+  <title>Example News</title>
+  <item>
+    <title>First</title>
+    <link>https://example.com/1</link>
+    <description>&lt;p&gt;Hello &amp;amp; welcome&lt;/p&gt;</description>
+    <pubDate>Mon, 16 Jun 2025 12:00:00 +0300</pubDate>
+  </item>
+  <item><title>No link</title></item>
+</channel></rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom">
+  <title>The Go Blog</title>
+  <entry>
+    <title>Go 1.24 is released</title>
+    <link rel="alternate" href="https://go.dev/blog/go1.24"/>
+    <summary>Release notes</summary>
+    <updated>2025-02-11T00:00:00Z</updated>
+  </entry>
+</feed>`
+
+func TestParseFeed_RSS(t *testing.T) {
+	articles, err := service.ParseFeed([]byte(rssFeed))
+	require.NoError(t, err)
+	require.Len(t, articles, 1)
+	assert.Equal(t, "First", articles[0].Title)
+	assert.Equal(t, "https://example.com/1", articles[0].URL)
+	assert.Equal(t, "Hello & welcome", articles[0].Description)
+	assert.Equal(t, "2025-06-16T09:00:00Z", articles[0].PublishedAt)
+	assert.Equal(t, "Example News", articles[0].SourceName)
+}
+
+func TestParseFeed_Atom(t *testing.T) {
+	articles, err := service.ParseFeed([]byte(atomFeed))
+	require.NoError(t, err)
+	require.Len(t, articles, 1)
+	assert.Equal(t, "https://go.dev/blog/go1.24", articles[0].URL)
+	assert.Equal(t, "Release notes", articles[0].Description)
+	assert.Equal(t, "2025-02-11T00:00:00Z", articles[0].PublishedAt)
+}
+
+func TestRSSService_GetFeeds(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rss":
+			w.Write([]byte(rssFeed))
+		case "/atom":
+			w.Write([]byte(atomFeed))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	rss := service.NewRSSService(server.Client())
+
+	articles, err := rss.GetFeeds(context.Background(), []string{server.URL + "/rss", server.URL + "/missing", server.URL + "/atom"})
+	require.NoError(t, err, "a failing feed doesn't fail the others")
+	assert.Len(t, articles, 2)
+
+	_, err = rss.GetFeeds(context.Background(), []string{server.URL + "/missing"})
+	assert.Error(t, err)
+}
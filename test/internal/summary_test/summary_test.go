@@ -0,0 +1,50 @@
+package summary_test
+
+import (
+	"strings"
+	"testing"
+
+	"tgbot/internal/summary"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSentences(t *testing.T) {
+	text := `The U.S. Senate passed the bill on Monday. "It is a historic day," said J. Smith! Was it? 2024 was a record year… Версия 2.0 вышла. Он сказал: «Это успех».`
+
+	assert.Equal(t, []string{
+		"The U.S. Senate passed the bill on Monday.",
+		`"It is a historic day," said J. Smith!`,
+		"Was it?",
+		"2024 was a record year…",
+		"Версия 2.0 вышла.",
+		"Он сказал: «Это успех».",
+	}, summary.Sentences(text))
+}
+
+func TestSummarize_PicksCentralSentencesInOrder(t *testing.T) {
+	paragraphs := []string{
+		"Subscribe to our newsletter for more stories like this one every single morning.",
+		"The volcano near Reykjavik erupted overnight, sending ash across the airport and grounding flights. " +
+			"Residents of the nearby town were evacuated as lava approached the volcano's southern slope.",
+		"Local bakeries reported a busy weekend with record sales of traditional bread and pastries.",
+		"Airlines expect flights to resume once the ash cloud over the airport disperses, officials said on Tuesday. " +
+			"Scientists warn the volcano could erupt again within weeks, and more ash may reach the airport.",
+	}
+
+	result := summary.Summarize("Volcano erupts near Reykjavik, flights grounded", paragraphs, 2)
+
+	sentences := summary.Sentences(result)
+	assert.Len(t, sentences, 2)
+	assert.True(t, strings.HasPrefix(result, "The volcano near Reykjavik erupted overnight"), "lead sentence matches the title")
+	assert.NotContains(t, result, "newsletter")
+	assert.NotContains(t, result, "bakeries")
+	for _, sentence := range sentences {
+		assert.Contains(t, strings.Join(paragraphs, " "), sentence, "sentences are kept verbatim")
+	}
+}
+
+func TestSummarize_NothingUsable(t *testing.T) {
+	assert.Empty(t, summary.Summarize("Title", []string{"Too short.", "Menu"}, 3))
+	assert.Empty(t, summary.Summarize("Title", nil, 3))
+}
@@ -0,0 +1,48 @@
+package tracking_test
+
+import (
+	"testing"
+
+	"tgbot/internal/tracking"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSigner_RoundTrip(t *testing.T) {
+	signer := tracking.NewSigner("0123456789abcdef")
+
+	for _, ids := range [][2]int64{{1, 1}, {123456789, 987654321}, {-1001234567890, 42}} {
+		token := signer.Sign(ids[0], ids[1])
+		assert.Less(t, len(token), 40, "tokens must stay short")
+
+		userID, articleID, err := signer.Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, ids[0], userID)
+		assert.Equal(t, ids[1], articleID)
+	}
+}
+
+func TestSigner_RejectsForgedTokens(t *testing.T) {
+	signer := tracking.NewSigner("0123456789abcdef")
+	token := signer.Sign(1, 42)
+
+	tampered := []byte(token)
+	tampered[0] ^= 1
+	for _, bad := range []string{"", "!!!", "AAAA", string(tampered), tracking.NewSigner("another secret!!").Sign(1, 42)} {
+		_, _, err := signer.Verify(bad)
+		assert.ErrorIs(t, err, tracking.ErrInvalidToken, bad)
+	}
+}
+
+func TestSigner_ArticleTokens(t *testing.T) {
+	signer := tracking.NewSigner("0123456789abcdef")
+
+	articleID, err := signer.VerifyArticle(signer.SignArticle(42))
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), articleID)
+
+	// A user's click token doesn't open reader pages.
+	_, err = signer.VerifyArticle(signer.Sign(1, 42))
+	assert.ErrorIs(t, err, tracking.ErrInvalidToken)
+}
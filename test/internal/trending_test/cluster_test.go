@@ -0,0 +1,41 @@
+package trending_test
+
+import (
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/trending"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetect_GroupsStoryAcrossSources(t *testing.T) {
+	articles := []entities.Article{
+		{ID: 1, Title: "Volcano erupts near Reykjavik, flights grounded", SourceID: "bbc-news"},
+		{ID: 2, Title: "New smartphone launch delayed", SourceID: "the-verge"},
+		{ID: 3, Title: "Iceland volcano erupts: airport flights grounded", SourceName: "Reuters"},
+		{ID: 4, Title: "Flights grounded as volcano erupts", SourceID: "cnn"},
+		{ID: 5, Title: "Volcano erupts near Reykjavik, flights grounded (update)", SourceID: "bbc-news"},
+		{ID: 6, Title: "Ash cloud spreads: flights grounded in Norway", URL: "https://www.nrk.no/ash"},
+	}
+
+	clusters := trending.Detect(articles)
+	require.Len(t, clusters, 2)
+
+	story := clusters[0]
+	assert.Equal(t, int64(1), story.Lead().ID, "the earliest article leads")
+	assert.Len(t, story.Articles, 5)
+	assert.Equal(t, []string{"bbc-news", "Reuters", "cnn", "nrk.no"}, story.Sources, "a source counts once")
+	assert.Equal(t, 4, story.Score())
+
+	assert.Equal(t, 1, clusters[1].Score())
+}
+
+func TestDetect_SingleSharedWordIsNotAStory(t *testing.T) {
+	clusters := trending.Detect([]entities.Article{
+		{ID: 1, Title: "Markets rally after election", SourceID: "a"},
+		{ID: 2, Title: "Election turnout hits record", SourceID: "b"},
+	})
+	assert.Len(t, clusters, 2)
+}
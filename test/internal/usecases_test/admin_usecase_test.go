@@ -0,0 +1,110 @@
+package usecases_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockStatsRepository struct {
+	mock.Mock
+}
+
+func (m *mockStatsRepository) GetStats(ctx context.Context) (*entities.Stats, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*entities.Stats), args.Error(1)
+}
+
+func commandUpdate(userID int64, text string) tgbotapi.Update {
+	command := strings.SplitN(text, " ", 2)[0]
+	return tgbotapi.Update{
+		Message: &tgbotapi.Message{
+			Chat:     &tgbotapi.Chat{ID: userID},
+			From:     &tgbotapi.User{ID: userID},
+			Text:     text,
+			Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: len(command)}},
+		},
+	}
+}
+
+func expectMessage(t *testing.T, mockBot *MockBotAPI, expected string) {
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		if !ok {
+			return false
+		}
+		t.Logf("Send called with message: %s", msg.Text)
+		return strings.Contains(msg.Text, expected)
+	})).Return(tgbotapi.Message{MessageID: 1}, nil).Once()
+}
+
+func TestBotUsecase_AdminCommands(t *testing.T) {
+	ctx := context.Background()
+	userRepo := &mockUserRepository{}
+	statsRepo := &mockStatsRepository{}
+	adminUsecase := usecases.NewAdminUsecase(userRepo, statsRepo, nil)
+
+	userRepo.On("TouchUser", mock.Anything, int64(1)).Return(&entities.User{ID: 1, Role: entities.RoleAdmin}, nil)
+	userRepo.On("TouchUser", mock.Anything, int64(2)).Return(&entities.User{ID: 2, Role: entities.RoleUser}, nil)
+	userRepo.On("TouchUser", mock.Anything, int64(3)).Return(&entities.User{ID: 3, Role: entities.RoleUser, Banned: true}, nil)
+	statsRepo.On("GetStats", mock.Anything).Return(&entities.Stats{
+		Users:                   10,
+		ActiveUsers:             4,
+		SubscriptionsByCategory: map[string]int{"technology": 7, "business": 2},
+		DeliveriesToday:         42,
+	}, nil)
+	userRepo.On("SetBanned", mock.Anything, int64(2), true).Return(nil)
+
+	tests := []struct {
+		name        string
+		update      tgbotapi.Update
+		expectedMsg string
+	}{
+		{name: "Stats for admin", update: commandUpdate(1, "/stats"), expectedMsg: "Пользователи: 10\nАктивные за сутки: 4"},
+		{name: "Stats per category", update: commandUpdate(1, "/stats"), expectedMsg: "business: 2\ntechnology: 7"},
+		{name: "Stats for non-admin", update: commandUpdate(2, "/stats"), expectedMsg: "Неизвестная команда"},
+		{name: "Ban", update: commandUpdate(1, "/ban 2"), expectedMsg: "Пользователь 2 заблокирован."},
+		{name: "Ban without ID", update: commandUpdate(1, "/ban"), expectedMsg: "укажите Telegram ID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBot := &MockBotAPI{}
+			botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+				usecases.WithAdminUsecase(adminUsecase))
+
+			expectMessage(t, mockBot, tt.expectedMsg)
+			botUsecase.HandleCommand(ctx, tt.update)
+			mockBot.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Banned user is ignored", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+			usecases.WithAdminUsecase(adminUsecase))
+
+		botUsecase.HandleCommand(ctx, commandUpdate(3, "/start"))
+		mockBot.AssertNotCalled(t, "Send", mock.Anything)
+	})
+}
+
+func TestAdminUsecase_SyncAdminsDemotesRemovedAdmins(t *testing.T) {
+	ctx := context.Background()
+	userRepo := &mockUserRepository{}
+	adminUsecase := usecases.NewAdminUsecase(userRepo, &mockStatsRepository{}, nil)
+
+	demoted := userRepo.On("DemoteAdmins", ctx, []int64{1, 2}).Return(nil)
+	userRepo.On("SetRole", ctx, int64(1), entities.RoleAdmin).Return(nil).NotBefore(demoted)
+	userRepo.On("SetRole", ctx, int64(2), entities.RoleAdmin).Return(nil).NotBefore(demoted)
+
+	assert.NoError(t, adminUsecase.SyncAdmins(ctx, []int64{1, 2}))
+	userRepo.AssertExpectations(t)
+}
@@ -0,0 +1,172 @@
+package usecases_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBookmarkRepository struct {
+	mock.Mock
+}
+
+func (m *mockBookmarkRepository) AddBookmark(ctx context.Context, userID, articleID int64) (bool, error) {
+	args := m.Called(ctx, userID, articleID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockBookmarkRepository) RemoveBookmark(ctx context.Context, userID, articleID int64) (bool, error) {
+	args := m.Called(ctx, userID, articleID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockBookmarkRepository) GetBookmarks(ctx context.Context, userID int64, limit, offset int) ([]entities.Bookmark, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	return args.Get(0).([]entities.Bookmark), args.Error(1)
+}
+
+func (m *mockBookmarkRepository) CountBookmarks(ctx context.Context, userID int64) (int, error) {
+	args := m.Called(ctx, userID)
+	return args.Int(0), args.Error(1)
+}
+
+func bookmarks(ids ...int64) []entities.Bookmark {
+	var result []entities.Bookmark
+	for _, id := range ids {
+		result = append(result, entities.Bookmark{Article: entities.Article{ID: id, Title: "Article", URL: "http://example.com"}})
+	}
+	return result
+}
+
+func TestBookmarkUsecase_PageClampsToRange(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockBookmarkRepository{}
+	repo.On("CountBookmarks", ctx, int64(1)).Return(7, nil)
+	repo.On("GetBookmarks", ctx, int64(1), 5, 5).Return(bookmarks(6, 7), nil)
+	usecase := usecases.NewBookmarkUsecase(repo)
+
+	page, err := usecase.Page(ctx, 1, 9)
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Page)
+	assert.Equal(t, 2, page.Pages)
+	assert.Len(t, page.Bookmarks, 2)
+}
+
+func TestBotUsecase_ArticlesHaveSaveButton(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"},
+		usecases.WithBookmarkUsecase(usecases.NewBookmarkUsecase(&mockBookmarkRepository{})))
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{{UserID: 1, Category: "technology"}}, nil)
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return(claimEvery, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{ID: 42, Title: "Stored", URL: "http://example.com/stored"},
+	}, nil)
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		if !ok {
+			return false
+		}
+		keyboard, ok := msg.ReplyMarkup.(*tgbotapi.InlineKeyboardMarkup)
+		return ok && *keyboard.InlineKeyboard[0][0].CallbackData == "bm:save:42"
+	})).Return(tgbotapi.Message{}, nil).Once()
+
+	botUsecase.CheckAndSendNews(ctx)
+
+	mockBot.AssertExpectations(t)
+}
+
+func callbackUpdate(data string) tgbotapi.Update {
+	return tgbotapi.Update{CallbackQuery: &tgbotapi.CallbackQuery{
+		ID:      "cb",
+		From:    &tgbotapi.User{ID: 1},
+		Data:    data,
+		Message: &tgbotapi.Message{MessageID: 10, Chat: &tgbotapi.Chat{ID: 1}},
+	}}
+}
+
+func TestBotUsecase_BookmarkCallbacks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Save", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		repo := &mockBookmarkRepository{}
+		botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+			usecases.WithBookmarkUsecase(usecases.NewBookmarkUsecase(repo)))
+
+		repo.On("AddBookmark", mock.Anything, int64(1), int64(42)).Return(true, nil)
+		mockBot.On("Request", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+			answer, ok := c.(tgbotapi.CallbackConfig)
+			return ok && answer.CallbackQueryID == "cb" && strings.Contains(answer.Text, "Сохранено")
+		})).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+
+		botUsecase.HandleCallback(ctx, callbackUpdate("bm:save:42"))
+
+		repo.AssertExpectations(t)
+		mockBot.AssertExpectations(t)
+	})
+
+	t.Run("Remove re-renders the page", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		repo := &mockBookmarkRepository{}
+		botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+			usecases.WithBookmarkUsecase(usecases.NewBookmarkUsecase(repo)))
+
+		repo.On("RemoveBookmark", mock.Anything, int64(1), int64(6)).Return(true, nil)
+		// The removed item was the last one on page 2, so page 1 is shown.
+		repo.On("CountBookmarks", mock.Anything, int64(1)).Return(5, nil)
+		repo.On("GetBookmarks", mock.Anything, int64(1), 5, 0).Return(bookmarks(1, 2, 3, 4, 5), nil)
+		mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+			edit, ok := c.(tgbotapi.EditMessageTextConfig)
+			return ok && edit.MessageID == 10 && strings.Contains(edit.Text, "страница 1 из 1") &&
+				len(edit.ReplyMarkup.InlineKeyboard) == 1 && len(edit.ReplyMarkup.InlineKeyboard[0]) == 5
+		})).Return(tgbotapi.Message{}, nil).Once()
+		mockBot.On("Request", mock.Anything).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+
+		botUsecase.HandleCallback(ctx, callbackUpdate("bm:del:6:2"))
+
+		repo.AssertExpectations(t)
+		mockBot.AssertExpectations(t)
+	})
+}
+
+func TestBotUsecase_SavedCommand(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	repo := &mockBookmarkRepository{}
+	botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+		usecases.WithBookmarkUsecase(usecases.NewBookmarkUsecase(repo)))
+
+	repo.On("CountBookmarks", mock.Anything, int64(1)).Return(12, nil)
+	repo.On("GetBookmarks", mock.Anything, int64(1), 5, 5).Return(bookmarks(6, 7, 8, 9, 10), nil)
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		if !ok {
+			return false
+		}
+		keyboard := msg.ReplyMarkup.(*tgbotapi.InlineKeyboardMarkup)
+		nav := keyboard.InlineKeyboard[1]
+		return strings.Contains(msg.Text, "6. [Article](http://example.com)") &&
+			*keyboard.InlineKeyboard[0][0].CallbackData == "bm:del:6:2" &&
+			len(nav) == 2 && *nav[0].CallbackData == "bm:page:1" && *nav[1].CallbackData == "bm:page:3"
+	})).Return(tgbotapi.Message{}, nil).Once()
+
+	botUsecase.HandleCommand(context.Background(), tgbotapi.Update{Message: &tgbotapi.Message{
+		Text:     "/saved 2",
+		Chat:     &tgbotapi.Chat{ID: 1},
+		From:     &tgbotapi.User{ID: 1},
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 6}},
+	}})
+
+	mockBot.AssertExpectations(t)
+}
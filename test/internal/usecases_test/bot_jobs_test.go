@@ -0,0 +1,224 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/queue"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeJobQueue is an in-memory job queue that serves both the bot, which
+// enqueues, and a worker, which claims.
+type fakeJobQueue struct {
+	mu         sync.Mutex
+	nextID     int64
+	jobs       []entities.Job
+	claimed    map[string]bool
+	enqueueErr error
+}
+
+func (q *fakeJobQueue) Enqueue(ctx context.Context, job *entities.Job) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.enqueue(job), nil
+}
+
+func (q *fakeJobQueue) enqueue(job *entities.Job) bool {
+	for _, queued := range q.jobs {
+		if job.DedupeKey != "" && queued.DedupeKey == job.DedupeKey {
+			return false
+		}
+	}
+	q.nextID++
+	queued := *job
+	queued.ID = q.nextID
+	q.jobs = append(q.jobs, queued)
+	return true
+}
+
+// EnqueueClaimed claims the articles not claimed before, and like a rolled
+// back transaction claims nothing if queuing fails.
+func (q *fakeJobQueue) EnqueueClaimed(ctx context.Context, articles []entities.Article, category string, build func(claimed []string) ([]*entities.Job, error)) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.enqueueErr != nil {
+		return q.enqueueErr
+	}
+	if q.claimed == nil {
+		q.claimed = make(map[string]bool)
+	}
+	var claimed []string
+	for _, article := range articles {
+		if !q.claimed[article.URL] {
+			q.claimed[article.URL] = true
+			claimed = append(claimed, article.URL)
+		}
+	}
+	jobs, err := build(claimed)
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		q.enqueue(job)
+	}
+	return nil
+}
+
+func (q *fakeJobQueue) Claim(ctx context.Context, workerID string, lease time.Duration) (*entities.Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.jobs) == 0 {
+		return nil, nil
+	}
+	job := q.jobs[0]
+	job.Attempts++
+	return &job, nil
+}
+
+func (q *fakeJobQueue) Extend(ctx context.Context, id int64, workerID string, lease time.Duration) error {
+	return nil
+}
+
+func (q *fakeJobQueue) Complete(ctx context.Context, id int64, workerID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, job := range q.jobs {
+		if job.ID == id {
+			q.jobs = append(q.jobs[:i], q.jobs[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (q *fakeJobQueue) Retry(ctx context.Context, id int64, workerID string, runAt time.Time, lastError string) error {
+	return nil
+}
+
+func (q *fakeJobQueue) Bury(ctx context.Context, id int64, workerID string, lastError string) error {
+	return q.Complete(ctx, id, workerID)
+}
+
+func (q *fakeJobQueue) kinds() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var kinds []string
+	for _, job := range q.jobs {
+		kinds = append(kinds, job.Kind)
+	}
+	return kinds
+}
+
+func TestBotUsecase_NewsCycleThroughJobQueue(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	jobs := &fakeJobQueue{}
+
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology", "science"},
+		usecases.WithJobQueue(jobs))
+	worker := queue.NewWorker(jobs)
+	botUsecase.RegisterJobs(worker)
+
+	var subscriptions []entities.Subscription
+	for userID := int64(1); userID <= 25; userID++ {
+		subscriptions = append(subscriptions, entities.Subscription{UserID: userID, Category: "technology"})
+	}
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return(subscriptions, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{Title: "Queued", URL: "http://example.com/queued"},
+	}, nil).Once()
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && strings.Contains(msg.Text, "*Queued*")
+	})).Return(tgbotapi.Message{}, nil).Times(25)
+
+	// The cycle only queues fetches; a second trigger doesn't duplicate them.
+	botUsecase.CheckAndSendNews(ctx)
+	botUsecase.CheckAndSendNews(ctx)
+	assert.Equal(t, []string{usecases.JobFetchCategory, usecases.JobFetchCategory}, jobs.kinds())
+	mockBot.AssertNotCalled(t, "Send", mock.Anything)
+
+	// Fetching technology queues its 25 recipients in two delivery batches.
+	require.True(t, worker.RunOnce(ctx))
+	assert.Equal(t, []string{usecases.JobFetchCategory, usecases.JobDeliverBatch, usecases.JobDeliverBatch}, jobs.kinds())
+
+	// Science has no subscribers; the batches send the article.
+	for worker.RunOnce(ctx) {
+	}
+	assert.Empty(t, jobs.kinds())
+	mockBot.AssertExpectations(t)
+	mockNewsUsecase.AssertExpectations(t)
+}
+
+func TestBotUsecase_FailedEnqueueLeavesArticlesUnsent(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	jobs := &fakeJobQueue{enqueueErr: errors.New("db down")}
+
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"},
+		usecases.WithJobQueue(jobs))
+	worker := queue.NewWorker(jobs)
+	botUsecase.RegisterJobs(worker)
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{{UserID: 1, Category: "technology"}}, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{Title: "Queued", URL: "http://example.com/queued"},
+	}, nil)
+
+	botUsecase.CheckAndSendNews(ctx)
+	require.True(t, worker.RunOnce(ctx))
+
+	// Nothing is sent directly and nothing is claimed, so the next cycle
+	// picks the article again.
+	mockBot.AssertNotCalled(t, "Send", mock.Anything)
+	assert.Empty(t, jobs.claimed)
+	assert.Empty(t, jobs.kinds())
+}
+
+func TestBotUsecase_DeliverBatchLoadsRecipientPrefsOnly(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	repo := &mockMediaRepository{}
+	jobs := &fakeJobQueue{}
+
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"},
+		usecases.WithJobQueue(jobs), usecases.WithMediaUsecase(usecases.NewMediaUsecase(repo)))
+	worker := queue.NewWorker(jobs)
+	botUsecase.RegisterJobs(worker)
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
+		{UserID: 1, Category: "technology"},
+		{UserID: 2, Category: "technology"},
+	}, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{Title: "Queued", URL: "http://example.com/queued"},
+	}, nil)
+	// The fetch picks for everyone; the delivery asks only about its batch.
+	repo.On("GetMediaModes", mock.Anything, []int64(nil)).Return(map[int64]string{}, nil).Once()
+	repo.On("GetMediaModes", mock.Anything, []int64{1, 2}).Return(map[int64]string{}, nil).Once()
+	mockBot.On("Send", mock.Anything).Return(tgbotapi.Message{}, nil).Times(2)
+
+	botUsecase.CheckAndSendNews(ctx)
+	for worker.RunOnce(ctx) {
+	}
+
+	repo.AssertExpectations(t)
+	mockBot.AssertExpectations(t)
+}
@@ -1,11 +1,17 @@
 package usecases_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"log/slog"
 	"strings"
 	"testing"
+	"time"
 
 	"tgbot/internal/entities"
+	"tgbot/internal/metrics"
+	"tgbot/internal/service"
 	"tgbot/internal/usecases"
 
 	tgbotapi "github.com/skinass/telegram-bot-api/v5"
@@ -23,6 +29,12 @@ func (m *MockBotAPI) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
 	return args.Get(0).(tgbotapi.Message), args.Error(1)
 }
 
+func (m *MockBotAPI) Request(c tgbotapi.Chattable) (*tgbotapi.APIResponse, error) {
+	args := m.Called(c)
+	resp, _ := args.Get(0).(*tgbotapi.APIResponse)
+	return resp, args.Error(1)
+}
+
 func (m *MockBotAPI) GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
 	args := m.Called(config)
 	return args.Get(0).(tgbotapi.UpdatesChannel)
@@ -33,6 +45,17 @@ func (m *MockBotAPI) Self() tgbotapi.User {
 	return args.Get(0).(tgbotapi.User)
 }
 
+func (m *MockBotAPI) SendToThread(c tgbotapi.Chattable, threadID int) (*tgbotapi.APIResponse, error) {
+	args := m.Called(c, threadID)
+	resp, _ := args.Get(0).(*tgbotapi.APIResponse)
+	return resp, args.Error(1)
+}
+
+func (m *MockBotAPI) MessageThreadID(chatID int64, messageID int) int {
+	args := m.Called(chatID, messageID)
+	return args.Int(0)
+}
+
 type MockSubscriptionUsecase struct {
 	mock.Mock
 }
@@ -52,20 +75,45 @@ func (m *MockSubscriptionUsecase) GetAllSubscriptions(ctx context.Context) ([]en
 	return args.Get(0).([]entities.Subscription), args.Error(1)
 }
 
+func (m *MockSubscriptionUsecase) SetUnreachable(ctx context.Context, chatID int64) error {
+	args := m.Called(ctx, chatID)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionUsecase) MigrateChat(ctx context.Context, fromID, toID int64) error {
+	args := m.Called(ctx, fromID, toID)
+	return args.Error(0)
+}
+
 type MockNewsUsecase struct {
 	mock.Mock
 }
 
-func (m *MockNewsUsecase) GetNewsByCategory(ctx context.Context, category string) ([]entities.Article, error) {
-	args := m.Called(ctx, category)
+func (m *MockNewsUsecase) GetNews(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+	args := m.Called(ctx, query)
+	return args.Get(0).([]entities.Article), args.Error(1)
+}
+
+func (m *MockNewsUsecase) GetNewArticles(ctx context.Context, query entities.NewsQuery, maxArticles int) ([]entities.Article, error) {
+	args := m.Called(ctx, query, maxArticles)
 	return args.Get(0).([]entities.Article), args.Error(1)
 }
 
-func (m *MockNewsUsecase) GetNewArticles(ctx context.Context, category string, maxArticles int) ([]entities.Article, error) {
-	args := m.Called(ctx, category, maxArticles)
+// ClaimArticles returns what the expectation returns, or if that is a
+// func, what it returns for the articles.
+func (m *MockNewsUsecase) ClaimArticles(ctx context.Context, articles []entities.Article, category string) ([]entities.Article, error) {
+	args := m.Called(ctx, articles, category)
+	if claim, ok := args.Get(0).(func([]entities.Article) []entities.Article); ok {
+		return claim(articles), args.Error(1)
+	}
 	return args.Get(0).([]entities.Article), args.Error(1)
 }
 
+// claimEvery claims every article, as if no cycle overlapped.
+func claimEvery(articles []entities.Article) []entities.Article {
+	return articles
+}
+
 func TestBotUsecase_HandleCommand(t *testing.T) {
 	ctx := context.Background()
 	mockBot := &MockBotAPI{}
@@ -107,7 +155,7 @@ func TestBotUsecase_HandleCommand(t *testing.T) {
 			},
 			expectedMsg: "Вы успешно подписались на категорию 'technology'!",
 			setupMocks: func() {
-				mockSubUsecase.On("SaveSubscription", ctx, &entities.User{ID: 123}, &entities.Subscription{UserID: 123, Category: "technology"}).Return(nil)
+				mockSubUsecase.On("SaveSubscription", mock.Anything, &entities.User{ID: 123}, &entities.Subscription{UserID: 123, Category: "technology"}).Return(nil)
 			},
 		},
 		{
@@ -134,7 +182,7 @@ func TestBotUsecase_HandleCommand(t *testing.T) {
 			},
 			expectedMsg: "*Test Title*\nTest Description\n[Read more](http://example.com)",
 			setupMocks: func() {
-				mockNewsUsecase.On("GetNewsByCategory", ctx, "technology").Return([]entities.Article{
+				mockNewsUsecase.On("GetNews", mock.Anything, entities.NewsQuery{Category: "technology"}).Return([]entities.Article{
 					{
 						Title:       "Test Title",
 						Description: "Test Description",
@@ -144,6 +192,23 @@ func TestBotUsecase_HandleCommand(t *testing.T) {
 				}, nil)
 			},
 		},
+		{
+			name: "News command with stale articles for a country",
+			update: tgbotapi.Update{
+				Message: &tgbotapi.Message{
+					Chat:     &tgbotapi.Chat{ID: 123},
+					From:     &tgbotapi.User{ID: 123},
+					Text:     "/news technology us",
+					Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+				},
+			},
+			expectedMsg: "не отфильтрованы по стране",
+			setupMocks: func() {
+				mockNewsUsecase.On("GetNews", mock.Anything, entities.NewsQuery{Category: "technology", Country: "us"}).Return([]entities.Article{
+					{Title: "Stored", URL: "http://stored.com"},
+				}, usecases.ErrStaleNews)
+			},
+		},
 		{
 			name: "MySubs with subscriptions",
 			update: tgbotapi.Update{
@@ -156,7 +221,7 @@ func TestBotUsecase_HandleCommand(t *testing.T) {
 			},
 			expectedMsg: "Ваши подписки:\ntechnology\nbusiness",
 			setupMocks: func() {
-				mockSubUsecase.On("GetSubscriptionsByUser", ctx, int64(123)).Return([]string{"technology", "business"}, nil)
+				mockSubUsecase.On("GetSubscriptionsByUser", mock.Anything, int64(123)).Return([]string{"technology", "business"}, nil)
 			},
 		},
 		{
@@ -208,10 +273,11 @@ func TestBotUsecase_SendToSubs(t *testing.T) {
 	mockBot.On("GetUpdatesChan", mock.Anything).Return(make(chan tgbotapi.Update, 1)).Maybe()
 
 	t.Run("Send new articles to subscribed users", func(t *testing.T) {
-		mockSubUsecase.On("GetAllSubscriptions", ctx).Return([]entities.Subscription{
+		mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
 			{UserID: 123, Category: "technology"},
 		}, nil)
-		mockNewsUsecase.On("GetNewArticles", ctx, "technology", 5).Return([]entities.Article{
+		mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return(claimEvery, nil)
+		mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
 			{
 				Title:       "Test Title",
 				Description: "Test Description",
@@ -236,6 +302,53 @@ func TestBotUsecase_SendToSubs(t *testing.T) {
 	})
 }
 
+func TestBotUsecase_SendToSubs_SkipsArticlesClaimedElsewhere(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"})
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
+		{UserID: 123, Category: "technology"},
+	}, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{Title: "A", URL: "http://a.com"},
+		{Title: "B", URL: "http://b.com"},
+	}, nil)
+	// An overlapping cycle claimed a.com first.
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return([]entities.Article{
+		{Title: "B", URL: "http://b.com"},
+	}, nil)
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && strings.HasPrefix(msg.Text, "*B*")
+	})).Return(tgbotapi.Message{}, nil).Once()
+
+	botUsecase.CheckAndSendNews(context.Background())
+
+	mockBot.AssertExpectations(t)
+}
+
+func TestBotUsecase_SendToSubs_ClaimError(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"})
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
+		{UserID: 123, Category: "technology"},
+	}, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{Title: "A", URL: "http://a.com"},
+	}, nil)
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return([]entities.Article(nil), errors.New("db down"))
+
+	// Unclaimed articles aren't sent, or they could be sent twice.
+	botUsecase.CheckAndSendNews(context.Background())
+
+	mockBot.AssertNotCalled(t, "Send", mock.Anything)
+}
+
 func TestBotUsecase_NoNewArticles(t *testing.T) {
 	ctx := context.Background()
 	mockBot := &MockBotAPI{}
@@ -248,10 +361,10 @@ func TestBotUsecase_NoNewArticles(t *testing.T) {
 	mockBot.On("GetUpdatesChan", mock.Anything).Return(make(chan tgbotapi.Update, 1)).Maybe()
 
 	t.Run("No new articles", func(t *testing.T) {
-		mockSubUsecase.On("GetAllSubscriptions", ctx).Return([]entities.Subscription{
+		mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
 			{UserID: 123, Category: "technology"},
 		}, nil)
-		mockNewsUsecase.On("GetNewArticles", ctx, "technology", 5).Return([]entities.Article{}, nil)
+		mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{}, nil)
 
 		mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
 			msg, ok := c.(tgbotapi.MessageConfig)
@@ -288,3 +401,256 @@ func TestBotUsecase_FormatArticle(t *testing.T) {
 	expected := "*Test Title*\nTest Description\n[Read more](http://example.com)"
 	assert.Equal(t, expected, result)
 }
+
+type MockKeyStatusProvider struct {
+	mock.Mock
+}
+
+func (m *MockKeyStatusProvider) KeyStatuses(ctx context.Context) []service.KeyStatus {
+	args := m.Called(ctx)
+	return args.Get(0).([]service.KeyStatus)
+}
+
+func TestBotUsecase_KeysCommand(t *testing.T) {
+	ctx := context.Background()
+	keyStatus := &MockKeyStatusProvider{}
+	keyStatus.On("KeyStatuses", mock.Anything).Return([]service.KeyStatus{
+		{ID: "abc123", Requests: 7, Remaining: 93},
+	}).Maybe()
+
+	tests := []struct {
+		name        string
+		userID      int64
+		expectedMsg string
+	}{
+		{name: "Admin", userID: 1, expectedMsg: "abc123 — активен, запросов сегодня: 7, осталось: 93"},
+		{name: "Not admin", userID: 2, expectedMsg: "Неизвестная команда"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBot := &MockBotAPI{}
+			botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+				usecases.WithAdmins([]int64{1}),
+				usecases.WithKeyStatusProvider(keyStatus),
+			)
+
+			mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+				msg, ok := c.(tgbotapi.MessageConfig)
+				return ok && strings.Contains(msg.Text, tt.expectedMsg)
+			})).Return(tgbotapi.Message{MessageID: 1}, nil).Once()
+
+			botUsecase.HandleCommand(ctx, tgbotapi.Update{
+				Message: &tgbotapi.Message{
+					Chat:     &tgbotapi.Chat{ID: tt.userID},
+					From:     &tgbotapi.User{ID: tt.userID},
+					Text:     "/keys",
+					Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 5}},
+				},
+			})
+
+			mockBot.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBotUsecase_AddWithCountryAndLanguage(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, &MockNewsUsecase{}, []string{"technology"})
+
+	tests := []struct {
+		name         string
+		text         string
+		subscription *entities.Subscription
+		expectedMsg  string
+	}{
+		{
+			name:         "Single code is country and language",
+			text:         "/add technology ru",
+			subscription: &entities.Subscription{UserID: 123, Category: "technology", Country: "ru", Language: "ru"},
+			expectedMsg:  "Вы успешно подписались на категорию 'technology (страна: ru, язык: ru)'!",
+		},
+		{
+			name:         "Country only",
+			text:         "/add technology us",
+			subscription: &entities.Subscription{UserID: 123, Category: "technology", Country: "us"},
+			expectedMsg:  "Вы успешно подписались на категорию 'technology (страна: us)'!",
+		},
+		{
+			name:         "Explicit country and language",
+			text:         "/add technology ua ru",
+			subscription: &entities.Subscription{UserID: 123, Category: "technology", Country: "ua", Language: "ru"},
+			expectedMsg:  "Вы успешно подписались на категорию 'technology (страна: ua, язык: ru)'!",
+		},
+		{
+			name:        "Unknown code",
+			text:        "/add technology xx",
+			expectedMsg: "неизвестный код страны или языка 'xx'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.subscription != nil {
+				mockSubUsecase.On("SaveSubscription", mock.Anything, &entities.User{ID: 123}, tt.subscription).Return(nil).Once()
+			}
+			mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+				msg, ok := c.(tgbotapi.MessageConfig)
+				return ok && strings.Contains(msg.Text, tt.expectedMsg)
+			})).Return(tgbotapi.Message{MessageID: 1}, nil).Once()
+
+			botUsecase.HandleCommand(ctx, tgbotapi.Update{
+				Message: &tgbotapi.Message{
+					Chat:     &tgbotapi.Chat{ID: 123},
+					From:     &tgbotapi.User{ID: 123},
+					Text:     tt.text,
+					Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 4}},
+				},
+			})
+
+			mockBot.AssertExpectations(t)
+			mockSubUsecase.AssertExpectations(t)
+		})
+	}
+}
+
+type recordedCommand struct {
+	command, outcome string
+}
+
+type fakeRecorder struct {
+	metrics.Nop
+	commands []recordedCommand
+	messages []string
+	fetched  [][2]int
+	cycles   int
+}
+
+func (r *fakeRecorder) CommandHandled(command, outcome string) {
+	r.commands = append(r.commands, recordedCommand{command, outcome})
+}
+
+func (r *fakeRecorder) MessageSent(result string) {
+	r.messages = append(r.messages, result)
+}
+
+func (r *fakeRecorder) ArticlesFetched(category string, fetched, fresh int) {
+	r.fetched = append(r.fetched, [2]int{fetched, fresh})
+}
+
+func (r *fakeRecorder) NewsCycle(duration time.Duration) {
+	r.cycles++
+}
+
+func TestBotUsecase_Metrics(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Commands and send failures", func(t *testing.T) {
+		recorder := &fakeRecorder{}
+		mockBot := &MockBotAPI{}
+		botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+			usecases.WithBotMetrics(recorder),
+		)
+
+		mockBot.On("Send", mock.Anything).Return(tgbotapi.Message{MessageID: 1}, nil).Once()
+		mockBot.On("Send", mock.Anything).Return(tgbotapi.Message{}, &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was blocked by the user"}).Once()
+
+		botUsecase.HandleCommand(ctx, commandUpdate(1, "/help"))
+		botUsecase.HandleCommand(ctx, commandUpdate(1, "/whatever"))
+
+		assert.Equal(t, []recordedCommand{{"help", "ok"}, {"unknown", "send_error"}}, recorder.commands)
+		assert.Equal(t, []string{"ok", "forbidden"}, recorder.messages)
+	})
+
+	t.Run("Banned users", func(t *testing.T) {
+		recorder := &fakeRecorder{}
+		userRepo := &mockUserRepository{}
+		userRepo.On("TouchUser", mock.Anything, int64(3)).Return(&entities.User{ID: 3, Banned: true}, nil)
+		botUsecase := usecases.NewBotUsecase(&MockBotAPI{}, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+			usecases.WithBotMetrics(recorder),
+			usecases.WithAdminUsecase(usecases.NewAdminUsecase(userRepo, &mockStatsRepository{}, nil)),
+		)
+
+		botUsecase.HandleCommand(ctx, commandUpdate(3, "/help"))
+		botUsecase.HandleCommand(ctx, commandUpdate(3, "/x1y2z3"))
+
+		assert.Equal(t, []recordedCommand{{"help", "banned"}, {"unknown", "banned"}}, recorder.commands)
+	})
+
+	t.Run("News cycle", func(t *testing.T) {
+		recorder := &fakeRecorder{}
+		mockSubUsecase := &MockSubscriptionUsecase{}
+		mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{}, nil)
+		botUsecase := usecases.NewBotUsecase(&MockBotAPI{}, mockSubUsecase, &MockNewsUsecase{}, nil,
+			usecases.WithBotMetrics(recorder),
+		)
+
+		botUsecase.CheckAndSendNews(ctx)
+
+		assert.Equal(t, 1, recorder.cycles)
+	})
+}
+
+func TestBotUsecase_LogsCorrelationIDs(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name      string
+		logBodies bool
+	}{
+		{name: "Bodies redacted by default", logBodies: false},
+		{name: "Bodies logged when enabled", logBodies: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+			mockBot := &MockBotAPI{}
+			mockBot.On("Send", mock.Anything).Return(tgbotapi.Message{MessageID: 1}, nil)
+			botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+				usecases.WithLogger(logger),
+				usecases.WithMessageBodyLogging(tt.logBodies),
+			)
+
+			update := commandUpdate(42, "/help")
+			update.UpdateID = 777
+			botUsecase.HandleCommand(ctx, update)
+
+			lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+			assert.NotEmpty(t, lines)
+			for _, line := range lines {
+				assert.Contains(t, line, `"update_id":777`)
+				assert.Contains(t, line, `"chat_id":42`)
+			}
+			assert.Equal(t, tt.logBodies, strings.Contains(buf.String(), "Доступные команды"))
+		})
+	}
+}
+
+func TestBotUsecase_UpdateSettings(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, &MockNewsUsecase{}, []string{"technology"})
+
+	expectMessage(t, mockBot, "Категория 'sports' не поддерживается")
+	botUsecase.HandleCommand(ctx, commandUpdate(1, "/add sports"))
+	mockBot.AssertExpectations(t)
+
+	botUsecase.UpdateSettings(usecases.BotSettings{
+		Categories:          []string{"technology", "sports"},
+		AdminIDs:            []int64{1},
+		NewsInterval:        time.Minute,
+		ArticlesPerCategory: 3,
+	})
+	assert.Equal(t, []string{"technology", "sports"}, botUsecase.Categories(ctx))
+
+	mockSubUsecase.On("SaveSubscription", mock.Anything, &entities.User{ID: 1}, &entities.Subscription{UserID: 1, Category: "sports"}).Return(nil).Once()
+	expectMessage(t, mockBot, "Вы успешно подписались на категорию 'sports'!")
+	botUsecase.HandleCommand(ctx, commandUpdate(1, "/add sports"))
+	mockBot.AssertExpectations(t)
+	mockSubUsecase.AssertExpectations(t)
+}
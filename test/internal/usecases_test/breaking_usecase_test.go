@@ -0,0 +1,243 @@
+package usecases_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockBreakingRepository struct {
+	mock.Mock
+}
+
+func (m *mockBreakingRepository) GetRecentArticles(ctx context.Context, category string, since time.Time) ([]entities.Article, error) {
+	args := m.Called(ctx, category, since)
+	return args.Get(0).([]entities.Article), args.Error(1)
+}
+
+func (m *mockBreakingRepository) RecordScores(ctx context.Context, scores []entities.TrendScore) error {
+	args := m.Called(ctx, scores)
+	return args.Error(0)
+}
+
+func (m *mockBreakingRepository) GetScoreCounts(ctx context.Context, category string, since time.Time) (map[int]int, error) {
+	args := m.Called(ctx, category, since)
+	return args.Get(0).(map[int]int), args.Error(1)
+}
+
+func (m *mockBreakingRepository) MarkAlerted(ctx context.Context, articleIDs []int64) (bool, error) {
+	args := m.Called(ctx, articleIDs)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockBreakingRepository) GetThreshold(ctx context.Context, category string) (int, error) {
+	args := m.Called(ctx, category)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockBreakingRepository) SetThreshold(ctx context.Context, category string, threshold int) error {
+	args := m.Called(ctx, category, threshold)
+	return args.Error(0)
+}
+
+func (m *mockBreakingRepository) SetBreakingAlerts(ctx context.Context, userID int64, enabled bool) error {
+	args := m.Called(ctx, userID, enabled)
+	return args.Error(0)
+}
+
+func (m *mockBreakingRepository) HasBreakingAlerts(ctx context.Context, userID int64) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockBreakingRepository) GetBreakingRecipients(ctx context.Context, category string) ([]entities.Subscription, error) {
+	args := m.Called(ctx, category)
+	return args.Get(0).([]entities.Subscription), args.Error(1)
+}
+
+// volcanoStory is covered by three sources; the other article is unrelated.
+var volcanoStory = []entities.Article{
+	{ID: 1, Title: "Volcano erupts near Reykjavik", URL: "http://example.com/bbc", SourceID: "bbc-news"},
+	{ID: 2, Title: "Smartphone launch delayed", URL: "http://example.com/verge", SourceID: "the-verge"},
+	{ID: 3, Title: "Iceland volcano erupts near Reykjavik", URL: "http://example.com/reuters", SourceName: "Reuters"},
+	{ID: 4, Title: "Reykjavik volcano erupts again", URL: "http://example.com/cnn", SourceID: "cnn"},
+}
+
+func TestBreakingUsecase_Detect(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Story over the threshold is alerted once", func(t *testing.T) {
+		repo := &mockBreakingRepository{}
+		repo.On("GetRecentArticles", ctx, "science", mock.Anything).Return(volcanoStory, nil)
+		repo.On("GetThreshold", ctx, "science").Return(0, nil)
+		repo.On("RecordScores", ctx, []entities.TrendScore{{Category: "science", ArticleID: 1, Score: 3}}).Return(nil)
+		repo.On("MarkAlerted", ctx, []int64{1, 3, 4}).Return(true, nil).Once()
+		repo.On("MarkAlerted", ctx, []int64{1, 3, 4}).Return(false, nil).Once()
+		usecase := usecases.NewBreakingUsecase(repo)
+
+		stories, err := usecase.Detect(ctx, "science")
+		require.NoError(t, err)
+		require.Len(t, stories, 1)
+		assert.Equal(t, "Volcano erupts near Reykjavik", stories[0].Lead().Title)
+
+		stories, err = usecase.Detect(ctx, "science")
+		require.NoError(t, err)
+		assert.Empty(t, stories)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Story under the category threshold is only recorded", func(t *testing.T) {
+		repo := &mockBreakingRepository{}
+		repo.On("GetRecentArticles", ctx, "science", mock.Anything).Return(volcanoStory, nil)
+		repo.On("GetThreshold", ctx, "science").Return(4, nil)
+		repo.On("RecordScores", ctx, mock.Anything).Return(nil)
+
+		stories, err := usecases.NewBreakingUsecase(repo).Detect(ctx, "science")
+		require.NoError(t, err)
+		assert.Empty(t, stories)
+		repo.AssertNotCalled(t, "MarkAlerted", mock.Anything, mock.Anything)
+	})
+}
+
+func TestBotUsecase_BreakingAlert(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	repo := &mockBreakingRepository{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"science"},
+		usecases.WithBreakingUsecase(usecases.NewBreakingUsecase(repo)))
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{{UserID: 1, Category: "science"}}, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "science"}, 5).Return([]entities.Article{}, nil)
+	repo.On("GetRecentArticles", mock.Anything, "science", mock.Anything).Return(volcanoStory, nil)
+	repo.On("GetThreshold", mock.Anything, "science").Return(0, nil)
+	repo.On("RecordScores", mock.Anything, mock.Anything).Return(nil)
+	repo.On("MarkAlerted", mock.Anything, []int64{1, 3, 4}).Return(true, nil)
+	repo.On("GetBreakingRecipients", mock.Anything, "science").Return([]entities.Subscription{{UserID: 7, Category: "science"}}, nil)
+
+	expectMessage(t, mockBot, "Пока новых новостей нет")
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == 7 && strings.HasPrefix(msg.Text, "🔴 *Breaking*\n*Volcano erupts near Reykjavik*") &&
+			strings.Contains(msg.Text, "Источников: 3 (bbc-news, Reuters, cnn)")
+	})).Return(tgbotapi.Message{}, nil).Once()
+
+	botUsecase.CheckAndSendNews(ctx)
+
+	mockBot.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
+
+func TestBotUsecase_BreakingCommand(t *testing.T) {
+	userRepo := &mockUserRepository{}
+	userRepo.On("TouchUser", mock.Anything, int64(1)).Return(&entities.User{ID: 1, Role: entities.RoleAdmin}, nil)
+	userRepo.On("TouchUser", mock.Anything, int64(2)).Return(&entities.User{ID: 2, Role: entities.RoleUser}, nil)
+	adminUsecase := usecases.NewAdminUsecase(userRepo, &mockStatsRepository{}, nil)
+
+	tests := []struct {
+		name        string
+		text        string
+		userID      int64
+		setup       func(repo *mockBreakingRepository)
+		expectedMsg string
+	}{
+		{
+			name:   "Turn on",
+			text:   "/breaking on",
+			userID: 2,
+			setup: func(repo *mockBreakingRepository) {
+				repo.On("SetBreakingAlerts", mock.Anything, int64(2), true).Return(nil)
+			},
+			expectedMsg: "Срочные новости включены",
+		},
+		{
+			name:        "Users can't set thresholds",
+			text:        "/breaking threshold science 5",
+			userID:      2,
+			setup:       func(*mockBreakingRepository) {},
+			expectedMsg: "Использование: /breaking on|off",
+		},
+		{
+			name:        "Set threshold",
+			text:        "/breaking threshold science 5",
+			userID:      1,
+			setup:       func(repo *mockBreakingRepository) { repo.On("SetThreshold", mock.Anything, "science", 5).Return(nil) },
+			expectedMsg: "установлен: 5",
+		},
+		{
+			name:        "Threshold too low",
+			text:        "/breaking threshold science 1",
+			userID:      1,
+			setup:       func(*mockBreakingRepository) {},
+			expectedMsg: "не меньше 2 источников",
+		},
+		{
+			name:   "Score history",
+			text:   "/breaking scores science",
+			userID: 1,
+			setup: func(repo *mockBreakingRepository) {
+				repo.On("GetScoreCounts", mock.Anything, "science", mock.Anything).Return(map[int]int{2: 30, 3: 6, 5: 1}, nil)
+				repo.On("GetThreshold", mock.Anything, "science").Return(0, nil)
+			},
+			expectedMsg: "(порог 3):\n5: 1 (не меньше 5: 1) 🔴\n3: 6 (не меньше 3: 7) 🔴\n2: 30 (не меньше 2: 37)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBot := &MockBotAPI{}
+			repo := &mockBreakingRepository{}
+			tt.setup(repo)
+			botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, []string{"science"},
+				usecases.WithAdminUsecase(adminUsecase),
+				usecases.WithBreakingUsecase(usecases.NewBreakingUsecase(repo)))
+
+			expectMessage(t, mockBot, tt.expectedMsg)
+			botUsecase.HandleCommand(context.Background(), commandUpdate(tt.userID, tt.text))
+
+			mockBot.AssertExpectations(t)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBotUsecase_BreakingAlertEscapesSources(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	repo := &mockBreakingRepository{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"science"},
+		usecases.WithBreakingUsecase(usecases.NewBreakingUsecase(repo)))
+
+	story := []entities.Article{
+		{ID: 1, Title: "Volcano erupts near Reykjavik", URL: "http://example.com/bbc", SourceID: "bbc_news"},
+		{ID: 3, Title: "Iceland volcano erupts near Reykjavik", URL: "http://example.com/reuters", SourceName: "*Reuters*"},
+	}
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{{UserID: 1, Category: "science"}}, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "science"}, 5).Return([]entities.Article{}, nil)
+	repo.On("GetRecentArticles", mock.Anything, "science", mock.Anything).Return(story, nil)
+	repo.On("GetThreshold", mock.Anything, "science").Return(2, nil)
+	repo.On("RecordScores", mock.Anything, mock.Anything).Return(nil)
+	repo.On("MarkAlerted", mock.Anything, []int64{1, 3}).Return(true, nil)
+	repo.On("GetBreakingRecipients", mock.Anything, "science").Return([]entities.Subscription{{UserID: 7, Category: "science"}}, nil)
+
+	expectMessage(t, mockBot, "Пока новых новостей нет")
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == 7 && strings.Contains(msg.Text, `Источников: 2 (bbc\_news, \*Reuters\*)`)
+	})).Return(tgbotapi.Message{}, nil).Once()
+
+	botUsecase.CheckAndSendNews(ctx)
+
+	mockBot.AssertExpectations(t)
+}
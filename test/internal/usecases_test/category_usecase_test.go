@@ -0,0 +1,206 @@
+package usecases_test
+
+import (
+	"context"
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockCategoryRepository struct {
+	mock.Mock
+}
+
+func (m *mockCategoryRepository) GetCategories(ctx context.Context) ([]entities.Category, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entities.Category), args.Error(1)
+}
+
+func (m *mockCategoryRepository) SaveCategory(ctx context.Context, category *entities.Category) error {
+	return m.Called(ctx, category).Error(0)
+}
+
+func (m *mockCategoryRepository) SeedCategories(ctx context.Context, slugs []string) error {
+	return m.Called(ctx, slugs).Error(0)
+}
+
+func (m *mockCategoryRepository) SetEnabled(ctx context.Context, slug string, enabled bool) (bool, error) {
+	args := m.Called(ctx, slug, enabled)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockCategoryRepository) SetName(ctx context.Context, slug, lang, name string) (bool, error) {
+	args := m.Called(ctx, slug, lang, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockCategoryRepository) SetSortOrder(ctx context.Context, slug string, order int) (bool, error) {
+	args := m.Called(ctx, slug, order)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockCategoryRepository) SetSchedule(ctx context.Context, slug, schedule string) (bool, error) {
+	args := m.Called(ctx, slug, schedule)
+	return args.Bool(0), args.Error(1)
+}
+
+var testCategories = []entities.Category{
+	{Slug: "technology", Names: map[string]string{"ru": "Технологии"}, Provider: entities.ProviderNewsAPI, NewsAPICategory: "technology", Enabled: true},
+	{Slug: "ai", Provider: entities.ProviderKeyword, Keywords: "artificial intelligence", Enabled: true},
+	{Slug: "sports", Provider: entities.ProviderNewsAPI, NewsAPICategory: "sports", Enabled: false},
+}
+
+func TestCategoryUsecase_ListAndGet(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockCategoryRepository{}
+	repo.On("GetCategories", ctx).Return(testCategories, nil).Once()
+	usecase := usecases.NewCategoryUsecase(repo)
+
+	enabled, err := usecase.ListCategories(ctx, false)
+	require.NoError(t, err)
+	assert.Len(t, enabled, 2)
+
+	all, err := usecase.ListCategories(ctx, true)
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	category, err := usecase.GetCategory(ctx, "ai")
+	require.NoError(t, err)
+	assert.Equal(t, "artificial intelligence", category.Keywords)
+
+	_, err = usecase.GetCategory(ctx, "sports")
+	assert.ErrorIs(t, err, usecases.ErrUnknownCategory)
+
+	repo.AssertExpectations(t)
+}
+
+func TestCategoryUsecase_ChangesInvalidateCache(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockCategoryRepository{}
+	repo.On("GetCategories", ctx).Return(testCategories, nil).Twice()
+	repo.On("SetEnabled", ctx, "sports", true).Return(true, nil)
+	usecase := usecases.NewCategoryUsecase(repo)
+
+	_, err := usecase.ListCategories(ctx, false)
+	require.NoError(t, err)
+	_, err = usecase.SetEnabled(ctx, "sports", true)
+	require.NoError(t, err)
+	_, err = usecase.ListCategories(ctx, false)
+	require.NoError(t, err)
+
+	repo.AssertExpectations(t)
+}
+
+func TestCategoryUsecase_SaveCategoryValidation(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockCategoryRepository{}
+	repo.On("SaveCategory", ctx, mock.Anything).Return(nil)
+	usecase := usecases.NewCategoryUsecase(repo)
+
+	tests := []struct {
+		name     string
+		category entities.Category
+		wantErr  bool
+	}{
+		{name: "NewsAPI", category: entities.Category{Slug: "sports", Provider: entities.ProviderNewsAPI, NewsAPICategory: "sports"}},
+		{name: "RSS", category: entities.Category{Slug: "go", Provider: entities.ProviderRSS, Feeds: []string{"https://go.dev/blog/feed.atom"}}},
+		{name: "Bad slug", category: entities.Category{Slug: "Спорт", Provider: entities.ProviderNewsAPI, NewsAPICategory: "sports"}, wantErr: true},
+		{name: "Missing keywords", category: entities.Category{Slug: "ai", Provider: entities.ProviderKeyword}, wantErr: true},
+		{name: "Bad feed", category: entities.Category{Slug: "go", Provider: entities.ProviderRSS, Feeds: []string{"ftp://x"}}, wantErr: true},
+		{name: "Unknown provider", category: entities.Category{Slug: "x", Provider: "twitter"}, wantErr: true},
+		{name: "Cron schedule", category: entities.Category{Slug: "sports", Provider: entities.ProviderNewsAPI, NewsAPICategory: "sports", Schedule: "*/15 * * * *"}},
+		{name: "Bad schedule", category: entities.Category{Slug: "sports", Provider: entities.ProviderNewsAPI, NewsAPICategory: "sports", Schedule: "61 * * * *"}, wantErr: true},
+		{name: "Schedule too frequent", category: entities.Category{Slug: "sports", Provider: entities.ProviderNewsAPI, NewsAPICategory: "sports", Schedule: "@every 10s"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := usecase.SaveCategory(ctx, &tt.category)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+type mockFeedService struct {
+	urls []string
+}
+
+func (m *mockFeedService) GetFeeds(ctx context.Context, urls []string) ([]entities.Article, error) {
+	m.urls = urls
+	return []entities.Article{{Title: "From feed", URL: "http://feed.com/1"}}, nil
+}
+
+func TestNewsUsecase_ResolvesCategoryProviders(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockCategoryRepository{}
+	repo.On("GetCategories", mock.Anything).Return(append(testCategories,
+		entities.Category{Slug: "golang", Provider: entities.ProviderRSS, Feeds: []string{"https://go.dev/blog/feed.atom"}, Enabled: true},
+	), nil)
+
+	var gotQuery entities.NewsQuery
+	mockNews := &MockNewsAPIService{
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+			gotQuery = query
+			return []entities.Article{{Title: "From NewsAPI", URL: "http://news.com/1"}}, nil
+		},
+	}
+	feeds := &mockFeedService{}
+	usecase := usecases.NewNewsUsecase(mockNews, nil, usecases.WithCategoryCatalogue(usecases.NewCategoryUsecase(repo), feeds))
+
+	_, err := usecase.GetNews(ctx, entities.NewsQuery{Category: "ai", Country: "us"})
+	require.NoError(t, err)
+	assert.Equal(t, entities.NewsQuery{Keywords: "artificial intelligence", Country: "us"}, gotQuery)
+
+	articles, err := usecase.GetNews(ctx, entities.NewsQuery{Category: "golang"})
+	require.NoError(t, err)
+	assert.Equal(t, "From feed", articles[0].Title)
+	assert.Equal(t, []string{"https://go.dev/blog/feed.atom"}, feeds.urls)
+
+	_, err = usecase.GetNews(ctx, entities.NewsQuery{Category: "sports"})
+	assert.ErrorIs(t, err, usecases.ErrUnknownCategory)
+}
+
+func TestBotUsecase_CategoryCommands(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockCategoryRepository{}
+	repo.On("GetCategories", mock.Anything).Return(testCategories, nil)
+	repo.On("SaveCategory", mock.Anything, &entities.Category{Slug: "ai", Provider: entities.ProviderKeyword, Keywords: "machine learning", Enabled: true}).Return(nil)
+	repo.On("SetEnabled", mock.Anything, "sports", false).Return(true, nil)
+	categoryUsecase := usecases.NewCategoryUsecase(repo)
+
+	tests := []struct {
+		name        string
+		update      tgbotapi.Update
+		expectedMsg string
+	}{
+		{name: "Public list", update: commandUpdate(2, "/categories"), expectedMsg: "Доступные категории:\ntechnology — Технологии\nai"},
+		{name: "Admin list", update: commandUpdate(1, "/category list"), expectedMsg: "sports (sports) — NewsAPI: sports, отключена"},
+		{name: "Add keyword category", update: commandUpdate(1, "/category add ai keyword machine learning"), expectedMsg: "Категория 'ai' сохранена."},
+		{name: "Disable", update: commandUpdate(1, "/category disable sports"), expectedMsg: "Категория 'sports' отключена."},
+		{name: "Non-admin", update: commandUpdate(2, "/category list"), expectedMsg: "Неизвестная команда"},
+		{name: "Disabled category rejected", update: commandUpdate(2, "/add sports"), expectedMsg: "Категория 'sports' не поддерживается. Доступные категории: technology, ai"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBot := &MockBotAPI{}
+			botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+				usecases.WithAdmins([]int64{1}),
+				usecases.WithCategoryUsecase(categoryUsecase))
+
+			expectMessage(t, mockBot, tt.expectedMsg)
+			botUsecase.HandleCommand(ctx, tt.update)
+			mockBot.AssertExpectations(t)
+		})
+	}
+}
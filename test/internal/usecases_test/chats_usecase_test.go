@@ -0,0 +1,289 @@
+package usecases_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/mock"
+)
+
+const groupID = int64(-1001)
+
+// groupCommand returns a command sent by the user in the supergroup.
+func groupCommand(userID int64, text string) tgbotapi.Update {
+	update := commandUpdate(userID, text)
+	update.Message.MessageID = 42
+	update.Message.Chat = &tgbotapi.Chat{ID: groupID, Type: entities.ChatSuperGroup}
+	return update
+}
+
+// expectChatMember makes getChatMember report the user's status in the group.
+func expectChatMember(mockBot *MockBotAPI, userID int64, status string) {
+	result, _ := json.Marshal(map[string]any{"status": status, "user": map[string]any{"id": userID}})
+	mockBot.On("Request", tgbotapi.GetChatMemberConfig{
+		ChatConfigWithUser: tgbotapi.ChatConfigWithUser{ChatID: groupID, UserID: userID},
+	}).Return(&tgbotapi.APIResponse{Ok: true, Result: result}, nil)
+}
+
+// expectReply expects a message to the group answering the command.
+func expectReply(mockBot *MockBotAPI, expected string) {
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == groupID && msg.ReplyToMessageID == 42 && strings.Contains(msg.Text, expected)
+	})).Return(tgbotapi.Message{}, nil).Once()
+}
+
+func TestBotUsecase_GroupSubscriptionByAdmin(t *testing.T) {
+	for _, status := range []string{"creator", "administrator"} {
+		t.Run(status, func(t *testing.T) {
+			mockBot := &MockBotAPI{}
+			subs := &MockSubscriptionUsecase{}
+			botUsecase := usecases.NewBotUsecase(mockBot, subs, &MockNewsUsecase{}, []string{"technology"})
+
+			mockBot.On("Self").Return(tgbotapi.User{UserName: "NewsBot"})
+			expectChatMember(mockBot, 5, status)
+			// Sent in a forum topic, whose deliveries go there too.
+			mockBot.On("MessageThreadID", groupID, 42).Return(7)
+			subs.On("SaveSubscription", mock.Anything,
+				&entities.User{ID: groupID, ChatType: entities.ChatSuperGroup},
+				&entities.Subscription{UserID: groupID, Category: "technology", ThreadID: 7}).Return(nil)
+			expectReply(mockBot, "Вы успешно подписались на категорию 'technology'!")
+
+			botUsecase.HandleCommand(context.Background(), groupCommand(5, "/add@newsbot technology"))
+
+			mockBot.AssertExpectations(t)
+			subs.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBotUsecase_GroupSubscriptionByMember(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	subs := &MockSubscriptionUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, subs, &MockNewsUsecase{}, []string{"technology"})
+
+	expectChatMember(mockBot, 5, "member")
+	expectReply(mockBot, "только его администраторы")
+
+	botUsecase.HandleCommand(context.Background(), groupCommand(5, "/add technology"))
+
+	mockBot.AssertExpectations(t)
+	subs.AssertNotCalled(t, "SaveSubscription", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBotUsecase_GroupReadCommandsNeedNoAdmin(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	subs := &MockSubscriptionUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, subs, &MockNewsUsecase{}, []string{"technology"})
+
+	subs.On("GetSubscriptionsByUser", mock.Anything, groupID).Return([]string{"technology"}, nil)
+	expectReply(mockBot, "Ваши подписки:\ntechnology")
+
+	botUsecase.HandleCommand(context.Background(), groupCommand(5, "/mysubs"))
+
+	mockBot.AssertExpectations(t)
+	mockBot.AssertNotCalled(t, "Request", mock.Anything)
+}
+
+func TestBotUsecase_AnonymousGroupAdmin(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	subs := &MockSubscriptionUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, subs, &MockNewsUsecase{}, []string{"technology"})
+
+	update := groupCommand(1087968824, "/add technology")
+	update.Message.SenderChat = update.Message.Chat
+	mockBot.On("MessageThreadID", groupID, 42).Return(0)
+	subs.On("SaveSubscription", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	expectReply(mockBot, "Вы успешно подписались")
+
+	botUsecase.HandleCommand(context.Background(), update)
+
+	mockBot.AssertExpectations(t)
+	mockBot.AssertNotCalled(t, "Request", mock.Anything)
+}
+
+func TestBotUsecase_ChannelPostCommand(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	subs := &MockSubscriptionUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, subs, &MockNewsUsecase{}, []string{"technology"})
+
+	channel := &tgbotapi.Chat{ID: -1002, Type: entities.ChatChannel}
+	update := tgbotapi.Update{ChannelPost: &tgbotapi.Message{
+		Chat:       channel,
+		SenderChat: channel,
+		Text:       "/add technology",
+		Entities:   []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: 4}},
+	}}
+	subs.On("SaveSubscription", mock.Anything,
+		&entities.User{ID: -1002, ChatType: entities.ChatChannel},
+		&entities.Subscription{UserID: -1002, Category: "technology"}).Return(nil)
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == -1002 && msg.ReplyToMessageID == 0
+	})).Return(tgbotapi.Message{}, nil).Once()
+
+	botUsecase.HandleCommand(context.Background(), update)
+
+	mockBot.AssertExpectations(t)
+	subs.AssertExpectations(t)
+}
+
+func TestBotUsecase_CommandForAnotherBot(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	subs := &MockSubscriptionUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, subs, &MockNewsUsecase{}, []string{"technology"})
+
+	mockBot.On("Self").Return(tgbotapi.User{UserName: "newsbot"})
+
+	botUsecase.HandleCommand(context.Background(), groupCommand(5, "/add@otherbot technology"))
+
+	mockBot.AssertNotCalled(t, "Send", mock.Anything)
+	mockBot.AssertNotCalled(t, "Request", mock.Anything)
+	subs.AssertNotCalled(t, "SaveSubscription", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBotUsecase_GroupFeedbackRanksForGroup(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	repo := &mockFeedbackRepository{}
+	botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+		usecases.WithFeedbackUsecase(usecases.NewFeedbackUsecase(repo)))
+
+	update := callbackUpdate("fb:up:42")
+	update.CallbackQuery.Message.Chat = &tgbotapi.Chat{ID: groupID, Type: entities.ChatGroup}
+	repo.On("SaveFeedback", mock.Anything, groupID, int64(42), entities.VoteUp).Return(true, nil)
+	mockBot.On("Request", mock.AnythingOfType("tgbotapi.CallbackConfig")).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+
+	botUsecase.HandleCallback(context.Background(), update)
+
+	repo.AssertExpectations(t)
+	mockBot.AssertExpectations(t)
+}
+
+// groupDelivery sets up a cycle that sends two technology articles to the
+// group.
+func groupDelivery(mockSubUsecase *MockSubscriptionUsecase, mockNewsUsecase *MockNewsUsecase) {
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
+		{UserID: groupID, Category: "technology"},
+	}, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{Title: "A", URL: "http://a.com"},
+		{Title: "B", URL: "http://b.com"},
+	}, nil)
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return(claimEvery, nil)
+}
+
+func TestBotUsecase_BlockedChatStopsDeliveries(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"})
+
+	groupDelivery(mockSubUsecase, mockNewsUsecase)
+	mockBot.On("Send", mock.Anything).Return(tgbotapi.Message{}, &tgbotapi.Error{Code: 403, Message: "Forbidden: bot was kicked from the supergroup chat"}).Once()
+	mockSubUsecase.On("SetUnreachable", mock.Anything, groupID).Return(nil).Once()
+
+	botUsecase.CheckAndSendNews(context.Background())
+
+	mockSubUsecase.AssertExpectations(t)
+	mockBot.AssertNumberOfCalls(t, "Send", 1)
+}
+
+func TestBotUsecase_MigratedGroupMovesSubscriptions(t *testing.T) {
+	const supergroupID = int64(-1002)
+
+	t.Run("Send error", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		mockSubUsecase := &MockSubscriptionUsecase{}
+		mockNewsUsecase := &MockNewsUsecase{}
+		botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"})
+
+		groupDelivery(mockSubUsecase, mockNewsUsecase)
+		mockBot.On("Send", mock.Anything).Return(tgbotapi.Message{}, &tgbotapi.Error{
+			Code:               400,
+			Message:            "Bad Request: group chat was upgraded to a supergroup chat",
+			ResponseParameters: tgbotapi.ResponseParameters{MigrateToChatID: supergroupID},
+		}).Once()
+		mockSubUsecase.On("MigrateChat", mock.Anything, groupID, supergroupID).Return(nil).Once()
+
+		botUsecase.CheckAndSendNews(context.Background())
+
+		mockSubUsecase.AssertExpectations(t)
+		mockBot.AssertNumberOfCalls(t, "Send", 1)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		mockSubUsecase := &MockSubscriptionUsecase{}
+		botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, &MockNewsUsecase{}, nil)
+
+		updates := make(chan tgbotapi.Update, 1)
+		updates <- tgbotapi.Update{Message: &tgbotapi.Message{
+			Chat:            &tgbotapi.Chat{ID: groupID, Type: entities.ChatGroup},
+			MigrateToChatID: supergroupID,
+		}}
+		close(updates)
+		mockBot.On("Self").Return(tgbotapi.User{UserName: "newsbot"})
+		mockBot.On("GetUpdatesChan", mock.Anything).Return(tgbotapi.UpdatesChannel(updates))
+		mockSubUsecase.On("MigrateChat", mock.Anything, groupID, supergroupID).Return(nil).Once()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		botUsecase.StartBot(ctx)
+
+		mockSubUsecase.AssertExpectations(t)
+	})
+}
+
+func TestBotUsecase_DeliversToForumTopic(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology", "sports"})
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
+		{UserID: groupID, Category: "technology", ThreadID: 7},
+		{UserID: groupID, Category: "sports"},
+	}, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{Title: "A", URL: "http://a.com"},
+	}, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "sports"}, 5).Return([]entities.Article{}, nil)
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return(claimEvery, nil)
+	mockBot.On("SendToThread", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == groupID && strings.HasPrefix(msg.Text, "*A*")
+	}), 7).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+	// The General topic's subscription is sent as usual.
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == groupID && strings.Contains(msg.Text, "Пока новых новостей нет")
+	})).Return(tgbotapi.Message{}, nil).Once()
+
+	botUsecase.CheckAndSendNews(context.Background())
+
+	mockBot.AssertExpectations(t)
+}
+
+func TestBotUsecase_DeletedForumTopicFallsBackToGeneral(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"})
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
+		{UserID: groupID, Category: "technology", ThreadID: 7},
+	}, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{}, nil)
+	mockBot.On("SendToThread", mock.Anything, 7).Return((*tgbotapi.APIResponse)(nil), &tgbotapi.Error{Code: 400, Message: "Bad Request: message thread not found"}).Once()
+	expectMessage(t, mockBot, "Пока новых новостей нет")
+
+	botUsecase.CheckAndSendNews(context.Background())
+
+	mockBot.AssertExpectations(t)
+}
@@ -0,0 +1,179 @@
+package usecases_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/tracking"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockClickRepository struct {
+	mock.Mock
+}
+
+func (m *mockClickRepository) RecordClick(ctx context.Context, userID, articleID int64) (string, bool, error) {
+	args := m.Called(ctx, userID, articleID)
+	return args.String(0), args.Bool(1), args.Error(2)
+}
+
+func (m *mockClickRepository) SetTrackingOptOut(ctx context.Context, userID int64, optOut bool) error {
+	args := m.Called(ctx, userID, optOut)
+	return args.Error(0)
+}
+
+func (m *mockClickRepository) IsTrackingOptOut(ctx context.Context, userID int64) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockClickRepository) GetTrackingOptOuts(ctx context.Context, userIDs []int64) (map[int64]bool, error) {
+	args := m.Called(ctx, userIDs)
+	return args.Get(0).(map[int64]bool), args.Error(1)
+}
+
+const linkPrefix = "https://bot.example.com/r/"
+
+func newClickUsecase(repo *mockClickRepository) *usecases.ClickUsecase {
+	return usecases.NewClickUsecase(repo, tracking.NewSigner("0123456789abcdef"), linkPrefix)
+}
+
+func TestClickUsecase_LinkOpensArticle(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockClickRepository{}
+	clicks := newClickUsecase(repo)
+	repo.On("RecordClick", ctx, int64(7), int64(42)).Return("https://example.com/a", true, nil)
+
+	link := clicks.Link(7, &entities.Article{ID: 42, URL: "https://example.com/a"})
+	require.True(t, strings.HasPrefix(link, linkPrefix))
+
+	url, found, err := clicks.Open(ctx, strings.TrimPrefix(link, linkPrefix))
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "https://example.com/a", url)
+
+	_, found, err = clicks.Open(ctx, "forged")
+	require.NoError(t, err)
+	assert.False(t, found)
+	repo.AssertNumberOfCalls(t, "RecordClick", 1)
+
+	assert.Equal(t, "https://example.com/b", clicks.Link(7, &entities.Article{URL: "https://example.com/b"}), "unstored articles keep their URL")
+}
+
+func TestBotUsecase_TrackedLinksRespectOptOut(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	repo := &mockClickRepository{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"},
+		usecases.WithClickTracking(newClickUsecase(repo)))
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
+		{UserID: 1, Category: "technology"},
+		{UserID: 2, Category: "technology"},
+	}, nil)
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return(claimEvery, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{ID: 42, Title: "Stored", URL: "https://example.com/a"},
+	}, nil)
+	repo.On("GetTrackingOptOuts", mock.Anything, mock.Anything).Return(map[int64]bool{2: true}, nil)
+
+	linkTo := func(chatID int64, prefix string) any {
+		return mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+			msg, ok := c.(tgbotapi.MessageConfig)
+			return ok && msg.ChatID == chatID && strings.Contains(msg.Text, "[Read more]("+prefix)
+		})
+	}
+	mockBot.On("Send", linkTo(1, linkPrefix)).Return(tgbotapi.Message{}, nil).Once()
+	mockBot.On("Send", linkTo(2, "https://example.com/a)")).Return(tgbotapi.Message{}, nil).Once()
+
+	botUsecase.CheckAndSendNews(ctx)
+
+	mockBot.AssertExpectations(t)
+}
+
+func TestBotUsecase_PrivacyCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		setup       func(repo *mockClickRepository)
+		expectedMsg string
+	}{
+		{
+			name: "Status",
+			text: "/privacy",
+			setup: func(repo *mockClickRepository) {
+				repo.On("IsTrackingOptOut", mock.Anything, int64(1)).Return(true, nil)
+			},
+			expectedMsg: "не учитываются",
+		},
+		{
+			name: "Opt out",
+			text: "/privacy on",
+			setup: func(repo *mockClickRepository) {
+				repo.On("SetTrackingOptOut", mock.Anything, int64(1), true).Return(nil)
+			},
+			expectedMsg: "прямыми ссылками",
+		},
+		{
+			name: "Opt back in",
+			text: "/privacy off",
+			setup: func(repo *mockClickRepository) {
+				repo.On("SetTrackingOptOut", mock.Anything, int64(1), false).Return(nil)
+			},
+			expectedMsg: "снова учитываются",
+		},
+		{name: "Bad argument", text: "/privacy maybe", setup: func(*mockClickRepository) {}, expectedMsg: "Использование"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBot := &MockBotAPI{}
+			repo := &mockClickRepository{}
+			tt.setup(repo)
+			botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+				usecases.WithClickTracking(newClickUsecase(repo)))
+
+			expectMessage(t, mockBot, tt.expectedMsg)
+			botUsecase.HandleCommand(context.Background(), commandUpdate(1, tt.text))
+
+			mockBot.AssertExpectations(t)
+			repo.AssertExpectations(t)
+		})
+	}
+
+	t.Run("Without tracking", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil)
+
+		expectMessage(t, mockBot, "не отслеживает")
+		botUsecase.HandleCommand(context.Background(), commandUpdate(1, "/privacy on"))
+		mockBot.AssertExpectations(t)
+	})
+}
+
+func TestBotUsecase_StatsShowClickThrough(t *testing.T) {
+	userRepo := &mockUserRepository{}
+	statsRepo := &mockStatsRepository{}
+	userRepo.On("TouchUser", mock.Anything, int64(1)).Return(&entities.User{ID: 1, Role: entities.RoleAdmin}, nil)
+	statsRepo.On("GetStats", mock.Anything).Return(&entities.Stats{
+		ClicksByCategory: []entities.ClickThrough{{Name: "technology", Deliveries: 200, Clicks: 9}},
+		ClicksBySource:   []entities.ClickThrough{{Name: "Wired", Deliveries: 40, Clicks: 4}},
+	}, nil)
+
+	mockBot := &MockBotAPI{}
+	botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+		usecases.WithAdminUsecase(usecases.NewAdminUsecase(userRepo, statsRepo, nil)),
+		usecases.WithClickTracking(newClickUsecase(&mockClickRepository{})))
+
+	expectMessage(t, mockBot, "по категориям:\ntechnology: 9 из 200 (4.5%)\nПереходы за неделю по источникам:\nWired: 4 из 40 (10.0%)")
+	botUsecase.HandleCommand(context.Background(), commandUpdate(1, "/stats"))
+	mockBot.AssertExpectations(t)
+}
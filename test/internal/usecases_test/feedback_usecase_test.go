@@ -0,0 +1,123 @@
+package usecases_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockFeedbackRepository struct {
+	mock.Mock
+}
+
+func (m *mockFeedbackRepository) SaveFeedback(ctx context.Context, userID, articleID int64, vote int) (bool, error) {
+	args := m.Called(ctx, userID, articleID, vote)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockFeedbackRepository) GetRecentFeedback(ctx context.Context, perUser int) ([]entities.Feedback, error) {
+	args := m.Called(ctx, perUser)
+	return args.Get(0).([]entities.Feedback), args.Error(1)
+}
+
+func TestFeedbackUsecase_RejectsInvalidVote(t *testing.T) {
+	repo := &mockFeedbackRepository{}
+	usecase := usecases.NewFeedbackUsecase(repo)
+
+	_, err := usecase.Vote(context.Background(), 1, 42, 5)
+
+	assert.Error(t, err)
+	repo.AssertNotCalled(t, "SaveFeedback", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestBotUsecase_FeedbackCallbacks(t *testing.T) {
+	tests := []struct {
+		data   string
+		vote   int
+		answer string
+	}{
+		{data: "fb:up:42", vote: entities.VoteUp, answer: "больше"},
+		{data: "fb:down:42", vote: entities.VoteDown, answer: "меньше"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			mockBot := &MockBotAPI{}
+			repo := &mockFeedbackRepository{}
+			botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+				usecases.WithFeedbackUsecase(usecases.NewFeedbackUsecase(repo)))
+
+			repo.On("SaveFeedback", mock.Anything, int64(1), int64(42), tt.vote).Return(true, nil)
+			mockBot.On("Request", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+				answer, ok := c.(tgbotapi.CallbackConfig)
+				return ok && strings.Contains(answer.Text, tt.answer)
+			})).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+
+			botUsecase.HandleCallback(context.Background(), callbackUpdate(tt.data))
+
+			repo.AssertExpectations(t)
+			mockBot.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBotUsecase_DeliveryRankedByFeedback(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	repo := &mockFeedbackRepository{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"},
+		usecases.WithFeedbackUsecase(usecases.NewFeedbackUsecase(repo)))
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
+		{UserID: 1, Category: "technology"},
+		{UserID: 2, Category: "technology"},
+	}, nil)
+	repo.On("GetRecentFeedback", mock.Anything, 200).Return([]entities.Feedback{
+		{UserID: 1, Vote: entities.VoteUp, Category: "technology", Article: entities.Article{SourceID: "liked"}},
+		{UserID: 1, Vote: entities.VoteUp, Category: "technology", Article: entities.Article{SourceID: "liked"}},
+	}, nil)
+
+	// With ranking enabled more candidates are fetched than are sent.
+	var articles []entities.Article
+	for i := range 7 {
+		articles = append(articles, entities.Article{ID: int64(i + 1), Title: "Other", URL: fmt.Sprintf("http://example.com/other%d", i), SourceID: "other"})
+	}
+	articles[6] = entities.Article{ID: 7, Title: "Liked", URL: "http://example.com/liked", SourceID: "liked"}
+	// Only picked articles are marked as sent: the sixth "Other" is picked
+	// by nobody and stays a candidate for the next cycle.
+	picked := mock.MatchedBy(func(claimed []entities.Article) bool {
+		for _, article := range claimed {
+			if article.ID == 6 {
+				return false
+			}
+		}
+		return len(claimed) == 6
+	})
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, picked, "technology").Return(claimEvery, nil).Once()
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 15).Return(articles, nil)
+
+	firstTitle := func(chatID int64, title string) any {
+		return mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+			msg, ok := c.(tgbotapi.MessageConfig)
+			return ok && msg.ChatID == chatID && strings.HasPrefix(msg.Text, "*"+title+"*")
+		})
+	}
+	mockBot.On("Send", firstTitle(1, "Liked")).Return(tgbotapi.Message{}, nil).Once()
+	mockBot.On("Send", firstTitle(1, "Other")).Return(tgbotapi.Message{}, nil).Times(4)
+	// Users without feedback keep the fetched order.
+	mockBot.On("Send", firstTitle(2, "Other")).Return(tgbotapi.Message{}, nil).Times(5)
+
+	botUsecase.CheckAndSendNews(ctx)
+
+	mockBot.AssertExpectations(t)
+	mockNewsUsecase.AssertExpectations(t)
+}
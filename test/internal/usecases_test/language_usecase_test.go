@@ -0,0 +1,134 @@
+package usecases_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockLanguageRepository struct {
+	mock.Mock
+}
+
+func (m *mockLanguageRepository) SetLanguages(ctx context.Context, userID int64, languages []string) error {
+	args := m.Called(ctx, userID, languages)
+	return args.Error(0)
+}
+
+func (m *mockLanguageRepository) GetLanguages(ctx context.Context, userID int64) ([]string, error) {
+	args := m.Called(ctx, userID)
+	languages, _ := args.Get(0).([]string)
+	return languages, args.Error(1)
+}
+
+func (m *mockLanguageRepository) GetAllLanguages(ctx context.Context) (map[int64][]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[int64][]string), args.Error(1)
+}
+
+func TestLanguageUsecase_SetLanguages(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockLanguageRepository{}
+	languages := usecases.NewLanguageUsecase(repo)
+	repo.On("SetLanguages", ctx, int64(1), []string{"ru", "en"}).Return(nil)
+
+	assert.NoError(t, languages.SetLanguages(ctx, 1, []string{"RU", " en", "ru"}))
+	assert.ErrorContains(t, languages.SetLanguages(ctx, 1, []string{"ru", "klingon"}), `неизвестный язык "klingon"`)
+	repo.AssertNumberOfCalls(t, "SetLanguages", 1)
+}
+
+func TestBotUsecase_LanguagesCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		setup       func(repo *mockLanguageRepository)
+		expectedMsg string
+	}{
+		{
+			name: "Status",
+			text: "/languages",
+			setup: func(repo *mockLanguageRepository) {
+				repo.On("GetLanguages", mock.Anything, int64(1)).Return([]string{"ru", "en"}, nil)
+			},
+			expectedMsg: "на языках: ru, en.",
+		},
+		{
+			name: "Set",
+			text: "/languages ru, en",
+			setup: func(repo *mockLanguageRepository) {
+				repo.On("SetLanguages", mock.Anything, int64(1), []string{"ru", "en"}).Return(nil)
+			},
+			expectedMsg: "только на языках ru, en",
+		},
+		{
+			name: "All",
+			text: "/languages all",
+			setup: func(repo *mockLanguageRepository) {
+				repo.On("SetLanguages", mock.Anything, int64(1), []string(nil)).Return(nil)
+			},
+			expectedMsg: "на всех языках",
+		},
+		{name: "Unknown language", text: "/languages xx", setup: func(*mockLanguageRepository) {}, expectedMsg: "неизвестный язык"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBot := &MockBotAPI{}
+			repo := &mockLanguageRepository{}
+			tt.setup(repo)
+			botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+				usecases.WithLanguageUsecase(usecases.NewLanguageUsecase(repo)))
+
+			expectMessage(t, mockBot, tt.expectedMsg)
+			botUsecase.HandleCommand(context.Background(), commandUpdate(1, tt.text))
+
+			mockBot.AssertExpectations(t)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBotUsecase_DeliveryFiltersLanguages(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	repo := &mockLanguageRepository{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"},
+		usecases.WithLanguageUsecase(usecases.NewLanguageUsecase(repo)))
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
+		{UserID: 1, Category: "technology"},
+		{UserID: 2, Category: "technology"},
+	}, nil)
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return(claimEvery, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{Title: "Russian", URL: "https://example.com/ru", Language: "ru"},
+		{Title: "German", URL: "https://example.com/de", Language: "de"},
+		{Title: "Unknown", URL: "https://example.com/x"},
+	}, nil)
+	repo.On("GetAllLanguages", mock.Anything).Return(map[int64][]string{1: {"ru", "en"}}, nil)
+
+	withTitle := func(chatID int64, title string) any {
+		return mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+			msg, ok := c.(tgbotapi.MessageConfig)
+			return ok && msg.ChatID == chatID && strings.Contains(msg.Text, title)
+		})
+	}
+	// User 1 skips the German article; unknown languages reach everyone.
+	for _, title := range []string{"Russian", "Unknown"} {
+		mockBot.On("Send", withTitle(1, title)).Return(tgbotapi.Message{}, nil).Once()
+	}
+	for _, title := range []string{"Russian", "German", "Unknown"} {
+		mockBot.On("Send", withTitle(2, title)).Return(tgbotapi.Message{}, nil).Once()
+	}
+
+	botUsecase.CheckAndSendNews(context.Background())
+
+	mockBot.AssertExpectations(t)
+}
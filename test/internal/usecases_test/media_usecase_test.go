@@ -0,0 +1,232 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockMediaRepository struct {
+	mock.Mock
+}
+
+func (m *mockMediaRepository) SetMediaMode(ctx context.Context, userID int64, mode string) error {
+	args := m.Called(ctx, userID, mode)
+	return args.Error(0)
+}
+
+func (m *mockMediaRepository) GetMediaMode(ctx context.Context, userID int64) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *mockMediaRepository) GetMediaModes(ctx context.Context, userIDs []int64) (map[int64]string, error) {
+	args := m.Called(ctx, userIDs)
+	return args.Get(0).(map[int64]string), args.Error(1)
+}
+
+func TestBotUsecase_MediaCommand(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		setup       func(repo *mockMediaRepository)
+		expectedMsg string
+	}{
+		{
+			name: "Status",
+			text: "/media",
+			setup: func(repo *mockMediaRepository) {
+				repo.On("GetMediaMode", mock.Anything, int64(1)).Return("photo", nil)
+			},
+			expectedMsg: "в режиме photo",
+		},
+		{
+			name: "Photo",
+			text: "/media Photo",
+			setup: func(repo *mockMediaRepository) {
+				repo.On("SetMediaMode", mock.Anything, int64(1), "photo").Return(nil)
+			},
+			expectedMsg: "фотографиями с подписью",
+		},
+		{name: "Unknown mode", text: "/media video", setup: func(*mockMediaRepository) {}, expectedMsg: `неизвестный режим "video"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockBot := &MockBotAPI{}
+			repo := &mockMediaRepository{}
+			tt.setup(repo)
+			botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+				usecases.WithMediaUsecase(usecases.NewMediaUsecase(repo)))
+
+			expectMessage(t, mockBot, tt.expectedMsg)
+			botUsecase.HandleCommand(context.Background(), commandUpdate(1, tt.text))
+
+			mockBot.AssertExpectations(t)
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+// newMediaBot returns a bot delivering the articles to user 1, whose media
+// mode is mode.
+func newMediaBot(mockBot *MockBotAPI, mode string, articles []entities.Article, opts ...usecases.BotOption) *usecases.BotUsecase {
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	repo := &mockMediaRepository{}
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{{UserID: 1, Category: "technology"}}, nil)
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return(claimEvery, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return(articles, nil)
+	repo.On("GetMediaModes", mock.Anything, mock.Anything).Return(map[int64]string{1: mode}, nil)
+	opts = append(opts, usecases.WithMediaUsecase(usecases.NewMediaUsecase(repo)))
+	return usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"}, opts...)
+}
+
+func isMessage(match func(msg tgbotapi.MessageConfig) bool) any {
+	return mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && match(msg)
+	})
+}
+
+func isPhoto(match func(photo tgbotapi.PhotoConfig) bool) any {
+	return mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		photo, ok := c.(tgbotapi.PhotoConfig)
+		return ok && match(photo)
+	})
+}
+
+func TestBotUsecase_MediaModes(t *testing.T) {
+	ctx := context.Background()
+	withImage := entities.Article{Title: "Pictured", Description: "Desc", URL: "https://example.com/a", ImageURL: "https://example.com/a.jpg"}
+	withoutImage := entities.Article{Title: "Plain", Description: "Desc", URL: "https://example.com/b"}
+
+	t.Run("Preview shows the image large", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		mockBot.On("Send", isMessage(func(msg tgbotapi.MessageConfig) bool {
+			return strings.HasPrefix(msg.Text, "[\u200b](https://example.com/a.jpg)*Pictured*") && !msg.DisableWebPagePreview
+		})).Return(tgbotapi.Message{}, nil).Once()
+		mockBot.On("Send", isMessage(func(msg tgbotapi.MessageConfig) bool {
+			return strings.HasPrefix(msg.Text, "*Plain*") && !msg.DisableWebPagePreview
+		})).Return(tgbotapi.Message{}, nil).Once()
+
+		newMediaBot(mockBot, entities.MediaPreview, []entities.Article{withImage, withoutImage}).CheckAndSendNews(ctx)
+		mockBot.AssertExpectations(t)
+	})
+
+	t.Run("Preview encodes Markdown characters of the image URL", func(t *testing.T) {
+		marked := withImage
+		marked.ImageURL = "https://example.com/a_(1)*.jpg"
+		mockBot := &MockBotAPI{}
+		mockBot.On("Send", isMessage(func(msg tgbotapi.MessageConfig) bool {
+			return strings.HasPrefix(msg.Text, "[\u200b](https://example.com/a%5F%281%29%2A.jpg)*Pictured*")
+		})).Return(tgbotapi.Message{}, nil).Once()
+
+		newMediaBot(mockBot, entities.MediaPreview, []entities.Article{marked}).CheckAndSendNews(ctx)
+		mockBot.AssertExpectations(t)
+	})
+
+	t.Run("Text has no preview", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		mockBot.On("Send", isMessage(func(msg tgbotapi.MessageConfig) bool {
+			return strings.HasPrefix(msg.Text, "*Pictured*") && msg.DisableWebPagePreview
+		})).Return(tgbotapi.Message{}, nil).Once()
+
+		newMediaBot(mockBot, entities.MediaText, []entities.Article{withImage}).CheckAndSendNews(ctx)
+		mockBot.AssertExpectations(t)
+	})
+
+	t.Run("Photo falls back to text", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		mockBot.On("Send", isPhoto(func(photo tgbotapi.PhotoConfig) bool {
+			return strings.HasPrefix(photo.Caption, "*Pictured*") && photo.ParseMode == "Markdown"
+		})).Return(tgbotapi.Message{}, &tgbotapi.Error{Code: 400, Message: "Bad Request: wrong file identifier/HTTP URL specified"}).Once()
+		mockBot.On("Send", isMessage(func(msg tgbotapi.MessageConfig) bool {
+			return strings.HasPrefix(msg.Text, "*Pictured*")
+		})).Return(tgbotapi.Message{}, nil).Once()
+		mockBot.On("Send", isMessage(func(msg tgbotapi.MessageConfig) bool {
+			return strings.HasPrefix(msg.Text, "*Plain*")
+		})).Return(tgbotapi.Message{}, nil).Once()
+
+		newMediaBot(mockBot, entities.MediaPhoto, []entities.Article{withImage, withoutImage}).CheckAndSendNews(ctx)
+		mockBot.AssertExpectations(t)
+	})
+
+	t.Run("Long captions are shortened", func(t *testing.T) {
+		long := withImage
+		long.Description = strings.Repeat("слово ", 300)
+		mockBot := &MockBotAPI{}
+		mockBot.On("Send", isPhoto(func(photo tgbotapi.PhotoConfig) bool {
+			return utf8.RuneCountInString(photo.Caption) <= 1024 && strings.Contains(photo.Caption, "…\n[Read more](https://example.com/a)")
+		})).Return(tgbotapi.Message{}, nil).Once()
+
+		newMediaBot(mockBot, entities.MediaPhoto, []entities.Article{long}).CheckAndSendNews(ctx)
+		mockBot.AssertExpectations(t)
+	})
+}
+
+func TestBotUsecase_MediaAlbum(t *testing.T) {
+	ctx := context.Background()
+	articles := []entities.Article{
+		{ID: 1, Title: "First", URL: "https://example.com/1", ImageURL: "https://example.com/1.jpg"},
+		{ID: 2, Title: "Second", URL: "https://example.com/2"},
+		{ID: 3, Title: "Third", URL: "https://example.com/3", ImageURL: "https://example.com/3.jpg"},
+	}
+	isAlbum := mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		group, ok := c.(tgbotapi.MediaGroupConfig)
+		if !ok || group.ChatID != 1 || len(group.Media) != 2 {
+			return false
+		}
+		first := group.Media[0].(tgbotapi.InputMediaPhoto)
+		second := group.Media[1].(tgbotapi.InputMediaPhoto)
+		return first.Media == tgbotapi.FileURL("https://example.com/1.jpg") && strings.HasPrefix(first.Caption, "1. *First*") &&
+			strings.HasPrefix(second.Caption, "2. *Third*")
+	})
+
+	t.Run("Articles with images go in an album", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		mockBot.On("Request", isAlbum).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+		mockBot.On("Send", isMessage(func(msg tgbotapi.MessageConfig) bool {
+			keyboard, ok := msg.ReplyMarkup.(*tgbotapi.InlineKeyboardMarkup)
+			return ok && strings.Contains(msg.Text, "из альбома") && len(keyboard.InlineKeyboard) == 2 &&
+				*keyboard.InlineKeyboard[1][0].CallbackData == "bm:save:3" && keyboard.InlineKeyboard[1][0].Text == "2 🔖"
+		})).Return(tgbotapi.Message{}, nil).Once()
+		mockBot.On("Send", isMessage(func(msg tgbotapi.MessageConfig) bool {
+			return strings.HasPrefix(msg.Text, "*Second*")
+		})).Return(tgbotapi.Message{}, nil).Once()
+
+		newMediaBot(mockBot, entities.MediaPhoto, articles,
+			usecases.WithBookmarkUsecase(usecases.NewBookmarkUsecase(&mockBookmarkRepository{}))).CheckAndSendNews(ctx)
+		mockBot.AssertExpectations(t)
+	})
+
+	t.Run("Rejected albums are sent one by one", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		mockBot.On("Request", isAlbum).Return((*tgbotapi.APIResponse)(nil), errors.New("timeout")).Once()
+		mockBot.On("Send", isPhoto(func(photo tgbotapi.PhotoConfig) bool { return true })).Return(tgbotapi.Message{}, nil).Twice()
+		mockBot.On("Send", isMessage(func(msg tgbotapi.MessageConfig) bool {
+			return strings.HasPrefix(msg.Text, "*Second*")
+		})).Return(tgbotapi.Message{}, nil).Once()
+
+		newMediaBot(mockBot, entities.MediaPhoto, articles).CheckAndSendNews(ctx)
+		mockBot.AssertExpectations(t)
+	})
+}
+
+func TestBotUsecase_FormatArticleAuthor(t *testing.T) {
+	botUsecase := usecases.NewBotUsecase(&MockBotAPI{}, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil)
+
+	article := &entities.Article{Title: "Title", Description: "Desc", URL: "http://example.com", Author: "Jane_Doe"}
+	assert.Equal(t, "*Title*\nDesc\n✍️ Jane\\_Doe\n[Read more](http://example.com)", botUsecase.FormatArticle(article))
+
+	article.Author = "https://example.com/staff/jane"
+	assert.Equal(t, "*Title*\nDesc\n[Read more](http://example.com)", botUsecase.FormatArticle(article), "profile links are left out")
+}
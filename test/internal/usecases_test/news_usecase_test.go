@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 	"tgbot/internal/entities"
+	"tgbot/internal/service"
 	"tgbot/internal/usecases"
 	"time"
 
@@ -12,29 +13,29 @@ import (
 )
 
 type MockNewsAPIService struct {
-	GetNewsByCategoryFunc func(ctx context.Context, category string) ([]entities.Article, error)
+	GetNewsFunc func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error)
 }
 
-func (m *MockNewsAPIService) GetNewsByCategory(ctx context.Context, category string) ([]entities.Article, error) {
-	return m.GetNewsByCategoryFunc(ctx, category)
+func (m *MockNewsAPIService) GetNews(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+	return m.GetNewsFunc(ctx, query)
 }
 
 type MockSentArticlesRepository struct {
-	IsArticleSentFunc   func(ctx context.Context, url string) (bool, error)
-	SaveSentArticleFunc func(ctx context.Context, article *entities.Article, category string) error
+	IsArticleSentFunc func(ctx context.Context, url string) (bool, error)
+	ClaimArticlesFunc func(ctx context.Context, articles []entities.Article, category string) ([]string, error)
 }
 
 func (m *MockSentArticlesRepository) IsArticleSent(ctx context.Context, url string) (bool, error) {
 	return m.IsArticleSentFunc(ctx, url)
 }
 
-func (m *MockSentArticlesRepository) SaveSentArticle(ctx context.Context, article *entities.Article, category string) error {
-	return m.SaveSentArticleFunc(ctx, article, category)
+func (m *MockSentArticlesRepository) ClaimArticles(ctx context.Context, articles []entities.Article, category string) ([]string, error) {
+	return m.ClaimArticlesFunc(ctx, articles, category)
 }
 
-func TestNewsUsecase_GetNewsByCategory(t *testing.T) {
+func TestNewsUsecase_GetNews(t *testing.T) {
 	mockNews := &MockNewsAPIService{
-		GetNewsByCategoryFunc: func(ctx context.Context, category string) ([]entities.Article, error) {
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
 			return []entities.Article{
 				{Title: "Title1", URL: "http://1.com"},
 			}, nil
@@ -43,29 +44,29 @@ func TestNewsUsecase_GetNewsByCategory(t *testing.T) {
 
 	usecase := usecases.NewNewsUsecase(mockNews, nil)
 
-	articles, err := usecase.GetNewsByCategory(context.Background(), "technology")
+	articles, err := usecase.GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
 	assert.NoError(t, err)
 	assert.Len(t, articles, 1)
 	assert.Equal(t, "Title1", articles[0].Title)
 }
 
-func TestNewsUsecase_GetNewsByCategory_Error(t *testing.T) {
+func TestNewsUsecase_GetNews_Error(t *testing.T) {
 	mockNews := &MockNewsAPIService{
-		GetNewsByCategoryFunc: func(ctx context.Context, category string) ([]entities.Article, error) {
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
 			return nil, errors.New("service error")
 		},
 	}
 
 	usecase := usecases.NewNewsUsecase(mockNews, nil)
 
-	articles, err := usecase.GetNewsByCategory(context.Background(), "science")
+	articles, err := usecase.GetNews(context.Background(), entities.NewsQuery{Category: "science"})
 	assert.Error(t, err)
 	assert.Nil(t, articles)
 }
 
 func TestNewsUsecase_GetNewArticles(t *testing.T) {
 	mockNews := &MockNewsAPIService{
-		GetNewsByCategoryFunc: func(ctx context.Context, category string) ([]entities.Article, error) {
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
 			return []entities.Article{
 				{Title: "New Article", URL: "http://new.com", PublishedAt: time.Now().Format(time.RFC3339)},
 				{Title: "Old Article", URL: "http://old.com", PublishedAt: time.Now().Add(-time.Hour).Format(time.RFC3339)},
@@ -80,22 +81,46 @@ func TestNewsUsecase_GetNewArticles(t *testing.T) {
 			}
 			return true, nil
 		},
-		SaveSentArticleFunc: func(ctx context.Context, article *entities.Article, category string) error {
-			return nil
-		},
 	}
 
 	usecase := usecases.NewNewsUsecase(mockNews, mockRepo)
 
-	articles, err := usecase.GetNewArticles(context.Background(), "technology", 5)
+	articles, err := usecase.GetNewArticles(context.Background(), entities.NewsQuery{Category: "technology"}, 5)
 	assert.NoError(t, err)
 	assert.Len(t, articles, 1)
 	assert.Equal(t, "New Article", articles[0].Title)
 }
 
+func TestNewsUsecase_GetNewArticles_CheckErrorSkipsArticle(t *testing.T) {
+	mockNews := &MockNewsAPIService{
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+			return []entities.Article{
+				{Title: "Bad", URL: "http://bad.com", PublishedAt: time.Now().Format(time.RFC3339)},
+				{Title: "Good", URL: "http://good.com", PublishedAt: time.Now().Add(-time.Hour).Format(time.RFC3339)},
+			}, nil
+		},
+	}
+
+	mockRepo := &MockSentArticlesRepository{
+		IsArticleSentFunc: func(ctx context.Context, url string) (bool, error) {
+			if url == "http://bad.com" {
+				return false, errors.New("check error")
+			}
+			return false, nil
+		},
+	}
+
+	usecase := usecases.NewNewsUsecase(mockNews, mockRepo)
+
+	articles, err := usecase.GetNewArticles(context.Background(), entities.NewsQuery{Category: "tech"}, 5)
+	assert.NoError(t, err)
+	assert.Len(t, articles, 1)
+	assert.Equal(t, "Good", articles[0].Title)
+}
+
 func TestNewsUsecase_GetNewArticles_EmptyResult(t *testing.T) {
 	mockNews := &MockNewsAPIService{
-		GetNewsByCategoryFunc: func(ctx context.Context, category string) ([]entities.Article, error) {
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
 			return []entities.Article{
 				{Title: "Sent Article", URL: "http://sent.com"},
 			}, nil
@@ -106,40 +131,206 @@ func TestNewsUsecase_GetNewArticles_EmptyResult(t *testing.T) {
 		IsArticleSentFunc: func(ctx context.Context, url string) (bool, error) {
 			return true, nil
 		},
-		SaveSentArticleFunc: func(ctx context.Context, article *entities.Article, category string) error {
-			return nil
-		},
 	}
 
 	usecase := usecases.NewNewsUsecase(mockNews, mockRepo)
 
-	articles, err := usecase.GetNewArticles(context.Background(), "science", 5)
+	articles, err := usecase.GetNewArticles(context.Background(), entities.NewsQuery{Category: "science"}, 5)
 	assert.NoError(t, err)
 	assert.Empty(t, articles)
 }
 
-func TestNewsUsecase_GetNewArticles_SaveErrorIgnored(t *testing.T) {
+func TestNewsUsecase_GetNewArticles_DoesNotClaim(t *testing.T) {
 	mockNews := &MockNewsAPIService{
-		GetNewsByCategoryFunc: func(ctx context.Context, category string) ([]entities.Article, error) {
-			return []entities.Article{
-				{Title: "New", URL: "http://new.com", PublishedAt: time.Now().Format(time.RFC3339)},
-			}, nil
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+			return []entities.Article{{URL: "http://a.com"}}, nil
 		},
 	}
-
+	// ClaimArticlesFunc is nil: candidates stay unsent until picked.
 	mockRepo := &MockSentArticlesRepository{
 		IsArticleSentFunc: func(ctx context.Context, url string) (bool, error) {
 			return false, nil
 		},
-		SaveSentArticleFunc: func(ctx context.Context, article *entities.Article, category string) error {
-			return errors.New("save error")
-		},
 	}
 
 	usecase := usecases.NewNewsUsecase(mockNews, mockRepo)
 
-	articles, err := usecase.GetNewArticles(context.Background(), "tech", 5)
+	articles, err := usecase.GetNewArticles(context.Background(), entities.NewsQuery{Category: "tech"}, 5)
+	assert.NoError(t, err)
+	assert.Len(t, articles, 1)
+}
+
+func TestNewsUsecase_ClaimArticles_Error(t *testing.T) {
+	mockRepo := &MockSentArticlesRepository{
+		ClaimArticlesFunc: func(ctx context.Context, articles []entities.Article, category string) ([]string, error) {
+			return nil, errors.New("claim error")
+		},
+	}
+
+	usecase := usecases.NewNewsUsecase(&MockNewsAPIService{}, mockRepo)
+
+	// Articles that can't be claimed aren't sent, or they could be sent twice.
+	articles, err := usecase.ClaimArticles(context.Background(), []entities.Article{{URL: "http://new.com"}}, "tech")
+	assert.Error(t, err)
+	assert.Empty(t, articles)
+}
+
+func TestNewsUsecase_ClaimArticles_OnlyClaimed(t *testing.T) {
+	mockRepo := &MockSentArticlesRepository{
+		// Another cycle claimed a.com in the meantime.
+		ClaimArticlesFunc: func(ctx context.Context, articles []entities.Article, category string) ([]string, error) {
+			return []string{"http://b.com"}, nil
+		},
+	}
+
+	usecase := usecases.NewNewsUsecase(&MockNewsAPIService{}, mockRepo)
+
+	articles, err := usecase.ClaimArticles(context.Background(), []entities.Article{{URL: "http://a.com"}, {URL: "http://b.com"}}, "tech")
+	assert.NoError(t, err)
+	assert.Equal(t, []entities.Article{{URL: "http://b.com"}}, articles)
+}
+
+type MockArticleRepository struct {
+	SaveArticlesFunc      func(ctx context.Context, category string, articles []entities.Article) error
+	GetLatestArticlesFunc func(ctx context.Context, category, language string, limit int) ([]entities.Article, error)
+}
+
+func (m *MockArticleRepository) SaveArticles(ctx context.Context, category string, articles []entities.Article) error {
+	return m.SaveArticlesFunc(ctx, category, articles)
+}
+
+func (m *MockArticleRepository) GetLatestArticles(ctx context.Context, category, language string, limit int) ([]entities.Article, error) {
+	return m.GetLatestArticlesFunc(ctx, category, language, limit)
+}
+
+func TestNewsUsecase_GetNews_StoresArticles(t *testing.T) {
+	mockNews := &MockNewsAPIService{
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+			return []entities.Article{{Title: "Fresh", URL: "http://fresh.com"}}, nil
+		},
+	}
+
+	var stored []entities.Article
+	mockArticles := &MockArticleRepository{
+		SaveArticlesFunc: func(ctx context.Context, category string, articles []entities.Article) error {
+			stored = articles
+			return nil
+		},
+	}
+
+	usecase := usecases.NewNewsUsecase(mockNews, nil, usecases.WithArticleStore(mockArticles))
+
+	articles, err := usecase.GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
+	assert.NoError(t, err)
+	assert.Len(t, articles, 1)
+	assert.Equal(t, articles, stored)
+}
+
+func TestNewsUsecase_GetNews_LeavesProviderArticlesAlone(t *testing.T) {
+	// The provider's cache hands out the same slice on every hit.
+	cached := []entities.Article{{Title: "Fresh", URL: "http://fresh.com"}}
+	mockNews := &MockNewsAPIService{
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+			return cached, nil
+		},
+	}
+	mockArticles := &MockArticleRepository{
+		SaveArticlesFunc: func(ctx context.Context, category string, articles []entities.Article) error {
+			articles[0].ID = 7
+			return nil
+		},
+	}
+
+	usecase := usecases.NewNewsUsecase(mockNews, nil, usecases.WithArticleStore(mockArticles))
+
+	articles, err := usecase.GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), articles[0].ID)
+	assert.Zero(t, cached[0].ID)
+}
+
+func TestNewsUsecase_GetNews_FallsBackWhenCircuitOpen(t *testing.T) {
+	mockNews := &MockNewsAPIService{
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+			return nil, service.ErrCircuitOpen
+		},
+	}
+
+	mockArticles := &MockArticleRepository{
+		GetLatestArticlesFunc: func(ctx context.Context, category, language string, limit int) ([]entities.Article, error) {
+			return []entities.Article{{Title: "Stored", URL: "http://stored.com"}}, nil
+		},
+	}
+
+	usecase := usecases.NewNewsUsecase(mockNews, nil, usecases.WithArticleStore(mockArticles))
+
+	articles, err := usecase.GetNews(context.Background(), entities.NewsQuery{Category: "technology"})
+	assert.ErrorIs(t, err, usecases.ErrStaleNews)
+	assert.Len(t, articles, 1)
+	assert.Equal(t, "Stored", articles[0].Title)
+}
+
+func TestNewsUsecase_GetNews_FallbackKeepsLanguage(t *testing.T) {
+	mockNews := &MockNewsAPIService{
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+			return nil, service.ErrNoAvailableKeys
+		},
+	}
+
+	var language string
+	mockArticles := &MockArticleRepository{
+		GetLatestArticlesFunc: func(ctx context.Context, category, lang string, limit int) ([]entities.Article, error) {
+			language = lang
+			return []entities.Article{{Title: "Stored", URL: "http://stored.com", Language: lang}}, nil
+		},
+	}
+
+	usecase := usecases.NewNewsUsecase(mockNews, nil, usecases.WithArticleStore(mockArticles))
+
+	_, err := usecase.GetNews(context.Background(), entities.NewsQuery{Category: "technology", Country: "de", Language: "de"})
+	assert.ErrorIs(t, err, usecases.ErrStaleNews)
+	assert.Equal(t, "de", language)
+}
+
+func TestNewsUsecase_GetNews_DetectsLanguage(t *testing.T) {
+	cached := []entities.Article{
+		{Title: "В Москве открылась новая станция метро", URL: "http://1.com"},
+		{Title: "Stocks rally", Description: "Markets rose as the central bank signalled a pause in rate hikes.", URL: "http://2.com"},
+		{Title: "OpenAI", URL: "http://3.com"},
+	}
+	mockNews := &MockNewsAPIService{
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+			return cached, nil
+		},
+	}
+
+	usecase := usecases.NewNewsUsecase(mockNews, nil)
+
+	articles, err := usecase.GetNews(context.Background(), entities.NewsQuery{Category: "technology", Language: "de"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ru", articles[0].Language)
+	assert.Equal(t, "en", articles[1].Language)
+	assert.Equal(t, "de", articles[2].Language, "undetected articles get the requested language")
+	// A cached result serves queries asking for other languages.
+	assert.Empty(t, cached[2].Language)
+}
+
+func TestNewsUsecase_GetNewArticles_CountsFreshBeforeLimit(t *testing.T) {
+	mockNews := &MockNewsAPIService{
+		GetNewsFunc: func(ctx context.Context, query entities.NewsQuery) ([]entities.Article, error) {
+			return []entities.Article{{URL: "http://a.com"}, {URL: "http://b.com"}, {URL: "http://c.com"}}, nil
+		},
+	}
+	mockRepo := &MockSentArticlesRepository{
+		IsArticleSentFunc: func(ctx context.Context, url string) (bool, error) {
+			return false, nil
+		},
+	}
+	recorder := &fakeRecorder{}
+	usecase := usecases.NewNewsUsecase(mockNews, mockRepo, usecases.WithNewsMetrics(recorder))
+
+	articles, err := usecase.GetNewArticles(context.Background(), entities.NewsQuery{Category: "technology"}, 1)
 	assert.NoError(t, err)
 	assert.Len(t, articles, 1)
-	assert.Equal(t, "New", articles[0].Title)
+	assert.Equal(t, [][2]int{{3, 3}}, recorder.fetched)
 }
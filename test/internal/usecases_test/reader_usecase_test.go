@@ -0,0 +1,210 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/tracking"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockReaderRepository struct {
+	mock.Mock
+}
+
+func (m *mockReaderRepository) GetArticle(ctx context.Context, articleID int64) (*entities.Article, string, bool, error) {
+	args := m.Called(ctx, articleID)
+	article, _ := args.Get(0).(*entities.Article)
+	return article, args.String(1), args.Bool(2), args.Error(3)
+}
+
+func (m *mockReaderRepository) SaveContent(ctx context.Context, articleID int64, content string) error {
+	args := m.Called(ctx, articleID, content)
+	return args.Error(0)
+}
+
+var readerArticle = &entities.Article{ID: 1, Title: "Tram line approved", URL: "https://example.com/a"}
+
+var readerSigner = tracking.NewSigner("0123456789abcdef")
+
+func TestReaderUsecase_Read(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Cached", func(t *testing.T) {
+		repo := &mockReaderRepository{}
+		fetcher := &mockPageFetcher{}
+		repo.On("GetArticle", ctx, int64(1)).Return(readerArticle, "First.\n\nSecond.", true, nil)
+
+		article, paragraphs, err := usecases.NewReaderUsecase(repo, fetcher, readerSigner, "").Read(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, readerArticle, article)
+		assert.Equal(t, []string{"First.", "Second."}, paragraphs)
+		fetcher.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Extracted and saved", func(t *testing.T) {
+		repo := &mockReaderRepository{}
+		fetcher := &mockPageFetcher{}
+		repo.On("GetArticle", ctx, int64(1)).Return(readerArticle, "", false, nil)
+		fetcher.On("Fetch", ctx, "https://example.com/a").Return(articlePage, nil)
+		repo.On("SaveContent", ctx, int64(1),
+			"The city council approved the new tram line after a long public debate on Monday evening.\n\n"+
+				"Construction of the tram line will start next spring and take about three years to finish.").Return(nil)
+
+		_, paragraphs, err := usecases.NewReaderUsecase(repo, fetcher, readerSigner, "").Read(ctx, 1)
+		require.NoError(t, err)
+		assert.Len(t, paragraphs, 2)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Fetch errors are remembered briefly", func(t *testing.T) {
+		repo := &mockReaderRepository{}
+		fetcher := &mockPageFetcher{}
+		repo.On("GetArticle", ctx, int64(1)).Return(readerArticle, "", false, nil)
+		fetcher.On("Fetch", ctx, "https://example.com/a").Return("", errors.New("timeout")).Once()
+
+		reader := usecases.NewReaderUsecase(repo, fetcher, readerSigner, "")
+		_, _, err := reader.Read(ctx, 1)
+		assert.Error(t, err)
+		// The second request doesn't fetch the page again.
+		_, _, err = reader.Read(ctx, 1)
+		assert.EqualError(t, err, "timeout")
+		fetcher.AssertExpectations(t)
+		repo.AssertNotCalled(t, "SaveContent", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown article", func(t *testing.T) {
+		repo := &mockReaderRepository{}
+		repo.On("GetArticle", ctx, int64(2)).Return(nil, "", false, nil)
+
+		article, _, err := usecases.NewReaderUsecase(repo, &mockPageFetcher{}, readerSigner, "").Read(ctx, 2)
+		require.NoError(t, err)
+		assert.Nil(t, article)
+	})
+}
+
+func TestReaderUsecase_PageURL(t *testing.T) {
+	assert.Empty(t, usecases.NewReaderUsecase(nil, nil, readerSigner, "").PageURL(1))
+	assert.Equal(t, "https://bot.example.com/read/"+readerSigner.SignArticle(42),
+		usecases.NewReaderUsecase(nil, nil, readerSigner, "https://bot.example.com/read/").PageURL(42))
+}
+
+func TestReaderUsecase_ReadPage(t *testing.T) {
+	ctx := context.Background()
+	repo := &mockReaderRepository{}
+	repo.On("GetArticle", ctx, int64(1)).Return(readerArticle, "First.", true, nil)
+	reader := usecases.NewReaderUsecase(repo, &mockPageFetcher{}, readerSigner, "")
+
+	article, paragraphs, err := reader.ReadPage(ctx, readerSigner.SignArticle(1))
+	require.NoError(t, err)
+	assert.Equal(t, readerArticle, article)
+	assert.Equal(t, []string{"First."}, paragraphs)
+
+	// Pages can't be opened by guessing article IDs.
+	for _, token := range []string{"1", readerSigner.Sign(5, 1), tracking.NewSigner("another secret!!").SignArticle(1)} {
+		article, _, err := reader.ReadPage(ctx, token)
+		assert.NoError(t, err)
+		assert.Nil(t, article, token)
+	}
+}
+
+func TestBotUsecase_ReadCallbacks(t *testing.T) {
+	ctx := context.Background()
+	// Three paragraphs of 1500 characters make two pages.
+	paragraph := strings.Repeat("word ", 299) + "end."
+	stored := strings.Join([]string{paragraph, paragraph, paragraph}, "\n\n")
+
+	newBot := func(mockBot *MockBotAPI) *usecases.BotUsecase {
+		repo := &mockReaderRepository{}
+		repo.On("GetArticle", mock.Anything, int64(1)).Return(readerArticle, stored, true, nil)
+		repo.On("GetArticle", mock.Anything, int64(2)).Return(readerArticle, "", true, nil)
+		reader := usecases.NewReaderUsecase(repo, &mockPageFetcher{}, readerSigner, "https://bot.example.com/read/")
+		return usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+			usecases.WithReaderUsecase(reader))
+	}
+
+	t.Run("First page", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+			msg, ok := c.(tgbotapi.MessageConfig)
+			if !ok {
+				return false
+			}
+			keyboard := msg.ReplyMarkup.(*tgbotapi.InlineKeyboardMarkup)
+			return msg.ChatID == 1 && msg.ParseMode == "" &&
+				strings.HasPrefix(msg.Text, "Tram line approved\n\n") && strings.HasSuffix(msg.Text, "страница 1 из 2") &&
+				len(keyboard.InlineKeyboard) == 2 && *keyboard.InlineKeyboard[0][0].CallbackData == "rd:1:2" &&
+				*keyboard.InlineKeyboard[1][0].URL == "https://bot.example.com/read/"+readerSigner.SignArticle(1)
+		})).Return(tgbotapi.Message{}, nil).Once()
+		mockBot.On("Request", mock.Anything).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+
+		newBot(mockBot).HandleCallback(ctx, callbackUpdate("rd:1"))
+
+		mockBot.AssertExpectations(t)
+	})
+
+	t.Run("Turning pages edits the message", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+			edit, ok := c.(tgbotapi.EditMessageTextConfig)
+			return ok && edit.MessageID == 10 && strings.HasSuffix(edit.Text, "страница 2 из 2") &&
+				*edit.ReplyMarkup.InlineKeyboard[0][0].CallbackData == "rd:1:1"
+		})).Return(tgbotapi.Message{}, nil).Once()
+		mockBot.On("Request", mock.Anything).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+
+		newBot(mockBot).HandleCallback(ctx, callbackUpdate("rd:1:2"))
+
+		mockBot.AssertExpectations(t)
+	})
+
+	t.Run("No text", func(t *testing.T) {
+		mockBot := &MockBotAPI{}
+		mockBot.On("Request", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+			answer, ok := c.(tgbotapi.CallbackConfig)
+			return ok && strings.Contains(answer.Text, "откройте её по ссылке")
+		})).Return(&tgbotapi.APIResponse{Ok: true}, nil).Once()
+
+		newBot(mockBot).HandleCallback(ctx, callbackUpdate("rd:2"))
+
+		mockBot.AssertExpectations(t)
+	})
+}
+
+func TestBotUsecase_ArticlesHaveReadButton(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"},
+		usecases.WithReaderUsecase(usecases.NewReaderUsecase(&mockReaderRepository{}, &mockPageFetcher{}, readerSigner, "")))
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{{UserID: 1, Category: "technology"}}, nil)
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return(claimEvery, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{ID: 42, Title: "Stored", URL: "https://example.com/a"},
+		{Title: "Not stored", URL: "https://example.com/b"},
+	}, nil)
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		if !ok || !strings.Contains(msg.Text, "Stored") || strings.Contains(msg.Text, "Not stored") {
+			return false
+		}
+		keyboard := msg.ReplyMarkup.(*tgbotapi.InlineKeyboardMarkup)
+		return *keyboard.InlineKeyboard[0][0].CallbackData == "rd:42"
+	})).Return(tgbotapi.Message{}, nil).Once()
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && strings.Contains(msg.Text, "Not stored") && msg.ReplyMarkup == nil
+	})).Return(tgbotapi.Message{}, nil).Once()
+
+	botUsecase.CheckAndSendNews(context.Background())
+
+	mockBot.AssertExpectations(t)
+}
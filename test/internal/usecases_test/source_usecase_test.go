@@ -0,0 +1,156 @@
+package usecases_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockSourceRepository struct {
+	mock.Mock
+}
+
+func (m *mockSourceRepository) ReplaceSources(ctx context.Context, sources []entities.Source) error {
+	args := m.Called(ctx, sources)
+	return args.Error(0)
+}
+
+func (m *mockSourceRepository) GetSources(ctx context.Context) ([]entities.Source, time.Time, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entities.Source), args.Get(1).(time.Time), args.Error(2)
+}
+
+func (m *mockSourceRepository) FollowSource(ctx context.Context, follow *entities.SourceFollow) error {
+	args := m.Called(ctx, follow)
+	return args.Error(0)
+}
+
+func (m *mockSourceRepository) GetFollowsByUser(ctx context.Context, userID int64) ([]string, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockSourceRepository) GetAllFollows(ctx context.Context) ([]entities.SourceFollow, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entities.SourceFollow), args.Error(1)
+}
+
+func (m *mockSourceRepository) MuteSource(ctx context.Context, userID int64, source string) error {
+	args := m.Called(ctx, userID, source)
+	return args.Error(0)
+}
+
+func (m *mockSourceRepository) GetAllMutes(ctx context.Context) (map[int64][]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[int64][]string), args.Error(1)
+}
+
+type mockSourceCatalogue struct {
+	mock.Mock
+}
+
+func (m *mockSourceCatalogue) GetSources(ctx context.Context) ([]entities.Source, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]entities.Source), args.Error(1)
+}
+
+func TestSourceUsecase_ListSources_RefreshesStaleCatalogue(t *testing.T) {
+	ctx := context.Background()
+	sourceRepo := &mockSourceRepository{}
+	catalogue := &mockSourceCatalogue{}
+	usecase := usecases.NewSourceUsecase(&mockUserRepository{}, sourceRepo, catalogue)
+
+	fresh := []entities.Source{{ID: "bbc-news", Name: "BBC News"}}
+	sourceRepo.On("GetSources", ctx).Return([]entities.Source{{ID: "old"}}, time.Now().Add(-48*time.Hour), nil)
+	catalogue.On("GetSources", ctx).Return(fresh, nil)
+	sourceRepo.On("ReplaceSources", ctx, fresh).Return(nil)
+
+	sources, err := usecase.ListSources(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, fresh, sources)
+	sourceRepo.AssertExpectations(t)
+	catalogue.AssertExpectations(t)
+}
+
+func TestSourceUsecase_FollowSource_UnknownSource(t *testing.T) {
+	ctx := context.Background()
+	sourceRepo := &mockSourceRepository{}
+	usecase := usecases.NewSourceUsecase(&mockUserRepository{}, sourceRepo, &mockSourceCatalogue{})
+
+	sourceRepo.On("GetSources", ctx).Return([]entities.Source{{ID: "bbc-news"}}, time.Now(), nil)
+
+	err := usecase.FollowSource(ctx, 123, "reuters", 0)
+	assert.ErrorIs(t, err, usecases.ErrUnknownSource)
+	sourceRepo.AssertNotCalled(t, "FollowSource", mock.Anything, mock.Anything)
+}
+
+func TestSourceUsecase_FollowSource_KeepsTopic(t *testing.T) {
+	ctx := context.Background()
+	userRepo := &mockUserRepository{}
+	sourceRepo := &mockSourceRepository{}
+	usecase := usecases.NewSourceUsecase(userRepo, sourceRepo, &mockSourceCatalogue{})
+
+	sourceRepo.On("GetSources", ctx).Return([]entities.Source{{ID: "bbc-news"}}, time.Now(), nil)
+	userRepo.On("SaveUser", ctx, &entities.User{ID: -100}).Return(nil)
+	sourceRepo.On("FollowSource", ctx, &entities.SourceFollow{UserID: -100, SourceID: "bbc-news", ThreadID: 7}).Return(nil)
+
+	assert.NoError(t, usecase.FollowSource(ctx, -100, " BBC-News ", 7))
+	sourceRepo.AssertExpectations(t)
+}
+
+func TestSourceUsecase_MuteSource_NormalizesName(t *testing.T) {
+	ctx := context.Background()
+	userRepo := &mockUserRepository{}
+	sourceRepo := &mockSourceRepository{}
+	usecase := usecases.NewSourceUsecase(userRepo, sourceRepo, &mockSourceCatalogue{})
+
+	userRepo.On("SaveUser", ctx, &entities.User{ID: 123}).Return(nil)
+	sourceRepo.On("MuteSource", ctx, int64(123), "daily mail").Return(nil)
+
+	assert.NoError(t, usecase.MuteSource(ctx, 123, "  Daily Mail "))
+	sourceRepo.AssertExpectations(t)
+}
+
+func TestBotUsecase_CheckAndSendNews_SkipsMutedSources(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	sourceRepo := &mockSourceRepository{}
+	sourceUsecase := usecases.NewSourceUsecase(&mockUserRepository{}, sourceRepo, &mockSourceCatalogue{})
+
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"},
+		usecases.WithSourceUsecase(sourceUsecase))
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
+		{UserID: 1, Category: "technology"},
+		{UserID: 2, Category: "technology"},
+	}, nil)
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return(claimEvery, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{Title: "Tabloid", URL: "http://tabloid.com", SourceName: "Daily Mail"},
+	}, nil)
+	sourceRepo.On("GetAllMutes", mock.Anything).Return(map[int64][]string{1: {"daily mail"}}, nil)
+	sourceRepo.On("GetAllFollows", mock.Anything).Return([]entities.SourceFollow{}, nil)
+
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == 1 && strings.Contains(msg.Text, "*Пока новых новостей нет*")
+	})).Return(tgbotapi.Message{MessageID: 1}, nil).Once()
+	mockBot.On("Send", mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+		msg, ok := c.(tgbotapi.MessageConfig)
+		return ok && msg.ChatID == 2 && strings.Contains(msg.Text, "*Tabloid*")
+	})).Return(tgbotapi.Message{MessageID: 2}, nil).Once()
+
+	botUsecase.CheckAndSendNews(ctx)
+
+	mockBot.AssertExpectations(t)
+}
@@ -20,6 +20,46 @@ func (m *mockUserRepository) SaveUser(ctx context.Context, user *entities.User)
 	return args.Error(0)
 }
 
+func (m *mockUserRepository) TouchUser(ctx context.Context, userID int64) (*entities.User, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(*entities.User), args.Error(1)
+}
+
+func (m *mockUserRepository) SetRole(ctx context.Context, userID int64, role string) error {
+	args := m.Called(ctx, userID, role)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) DemoteAdmins(ctx context.Context, keep []int64) error {
+	args := m.Called(ctx, keep)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) SetBanned(ctx context.Context, userID int64, banned bool) error {
+	args := m.Called(ctx, userID, banned)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) SetUnreachable(ctx context.Context, chatID int64) error {
+	args := m.Called(ctx, chatID)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) MigrateChat(ctx context.Context, fromID, toID int64) error {
+	args := m.Called(ctx, fromID, toID)
+	return args.Error(0)
+}
+
+func (m *mockUserRepository) ListUsers(ctx context.Context, search string, limit, offset int) ([]entities.User, error) {
+	args := m.Called(ctx, search, limit, offset)
+	return args.Get(0).([]entities.User), args.Error(1)
+}
+
+func (m *mockUserRepository) GetActiveUserIDs(ctx context.Context) ([]int64, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]int64), args.Error(1)
+}
+
 type mockSubscriptionRepository struct {
 	mock.Mock
 }
@@ -34,6 +74,11 @@ func (m *mockSubscriptionRepository) GetSubscriptionsByUser(ctx context.Context,
 	return args.Get(0).([]entities.Subscription), args.Error(1)
 }
 
+func (m *mockSubscriptionRepository) DeleteSubscription(ctx context.Context, userID int64, category string) (bool, error) {
+	args := m.Called(ctx, userID, category)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *mockSubscriptionRepository) GetAllSubscriptions(ctx context.Context) ([]entities.Subscription, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]entities.Subscription), args.Error(1)
@@ -0,0 +1,154 @@
+package usecases_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"tgbot/internal/entities"
+	"tgbot/internal/usecases"
+
+	tgbotapi "github.com/skinass/telegram-bot-api/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockSummaryRepository struct {
+	mock.Mock
+}
+
+func (m *mockSummaryRepository) GetSummary(ctx context.Context, articleID int64) (string, bool, error) {
+	args := m.Called(ctx, articleID)
+	return args.String(0), args.Bool(1), args.Error(2)
+}
+
+func (m *mockSummaryRepository) SaveSummary(ctx context.Context, articleID int64, summary string) error {
+	args := m.Called(ctx, articleID, summary)
+	return args.Error(0)
+}
+
+func (m *mockSummaryRepository) SetSummaries(ctx context.Context, userID int64, enabled bool) error {
+	args := m.Called(ctx, userID, enabled)
+	return args.Error(0)
+}
+
+func (m *mockSummaryRepository) HasSummaries(ctx context.Context, userID int64) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *mockSummaryRepository) GetSummaryUsers(ctx context.Context, userIDs []int64) (map[int64]bool, error) {
+	args := m.Called(ctx, userIDs)
+	return args.Get(0).(map[int64]bool), args.Error(1)
+}
+
+type mockPageFetcher struct {
+	mock.Mock
+}
+
+func (m *mockPageFetcher) Fetch(ctx context.Context, url string) (string, error) {
+	args := m.Called(ctx, url)
+	return args.String(0), args.Error(1)
+}
+
+const articlePage = `<html><body><nav><p>Home</p></nav><article>
+<p>The city council approved the new tram line after a long public debate on Monday evening.</p>
+<p>Construction of the tram line will start next spring and take about three years to finish.</p>
+</article></body></html>`
+
+func TestSummaryUsecase_Summarize(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Cached", func(t *testing.T) {
+		repo := &mockSummaryRepository{}
+		fetcher := &mockPageFetcher{}
+		repo.On("GetSummary", ctx, int64(1)).Return("Cached summary.", true, nil)
+
+		text, err := usecases.NewSummaryUsecase(repo, fetcher).Summarize(ctx, &entities.Article{ID: 1, URL: "https://example.com/a"})
+		require.NoError(t, err)
+		assert.Equal(t, "Cached summary.", text)
+		fetcher.AssertNotCalled(t, "Fetch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fetched and saved", func(t *testing.T) {
+		repo := &mockSummaryRepository{}
+		fetcher := &mockPageFetcher{}
+		repo.On("GetSummary", ctx, int64(1)).Return("", false, nil)
+		fetcher.On("Fetch", ctx, "https://example.com/a").Return(articlePage, nil)
+		repo.On("SaveSummary", ctx, int64(1), mock.MatchedBy(func(s string) bool {
+			return strings.HasPrefix(s, "The city council approved the new tram line")
+		})).Return(nil)
+
+		text, err := usecases.NewSummaryUsecase(repo, fetcher).Summarize(ctx, &entities.Article{ID: 1, Title: "Tram line approved", URL: "https://example.com/a"})
+		require.NoError(t, err)
+		assert.NotContains(t, text, "Home")
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Fetch errors aren't cached", func(t *testing.T) {
+		repo := &mockSummaryRepository{}
+		fetcher := &mockPageFetcher{}
+		repo.On("GetSummary", ctx, int64(1)).Return("", false, nil)
+		fetcher.On("Fetch", ctx, "https://example.com/a").Return("", errors.New("timeout"))
+
+		_, err := usecases.NewSummaryUsecase(repo, fetcher).Summarize(ctx, &entities.Article{ID: 1, URL: "https://example.com/a"})
+		assert.Error(t, err)
+		repo.AssertNotCalled(t, "SaveSummary", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestBotUsecase_SummaryCommand(t *testing.T) {
+	mockBot := &MockBotAPI{}
+	repo := &mockSummaryRepository{}
+	repo.On("SetSummaries", mock.Anything, int64(1), true).Return(nil)
+	botUsecase := usecases.NewBotUsecase(mockBot, &MockSubscriptionUsecase{}, &MockNewsUsecase{}, nil,
+		usecases.WithSummaryUsecase(usecases.NewSummaryUsecase(repo, &mockPageFetcher{})))
+
+	expectMessage(t, mockBot, "Краткий пересказ включён")
+	botUsecase.HandleCommand(context.Background(), commandUpdate(1, "/summary on"))
+
+	mockBot.AssertExpectations(t)
+	repo.AssertExpectations(t)
+}
+
+func TestBotUsecase_DeliverySummarizesForOptedInUsers(t *testing.T) {
+	ctx := context.Background()
+	mockBot := &MockBotAPI{}
+	mockSubUsecase := &MockSubscriptionUsecase{}
+	mockNewsUsecase := &MockNewsUsecase{}
+	repo := &mockSummaryRepository{}
+	fetcher := &mockPageFetcher{}
+	botUsecase := usecases.NewBotUsecase(mockBot, mockSubUsecase, mockNewsUsecase, []string{"technology"},
+		usecases.WithSummaryUsecase(usecases.NewSummaryUsecase(repo, fetcher)))
+
+	mockSubUsecase.On("GetAllSubscriptions", mock.Anything).Return([]entities.Subscription{
+		{UserID: 1, Category: "technology"},
+		{UserID: 2, Category: "technology"},
+		{UserID: 3, Category: "technology"},
+	}, nil)
+	mockNewsUsecase.On("ClaimArticles", mock.Anything, mock.Anything, "technology").Return(claimEvery, nil)
+	mockNewsUsecase.On("GetNewArticles", mock.Anything, entities.NewsQuery{Category: "technology"}, 5).Return([]entities.Article{
+		{ID: 42, Title: "Tram line approved", Description: "Feed description", URL: "https://example.com/a"},
+	}, nil)
+	repo.On("GetSummaryUsers", mock.Anything, mock.Anything).Return(map[int64]bool{1: true, 3: true}, nil)
+	repo.On("GetSummary", mock.Anything, int64(42)).Return("", false, nil).Once()
+	repo.On("SaveSummary", mock.Anything, int64(42), mock.Anything).Return(nil).Once()
+	fetcher.On("Fetch", mock.Anything, "https://example.com/a").Return(articlePage, nil).Once()
+
+	withText := func(chatID int64, text string) any {
+		return mock.MatchedBy(func(c tgbotapi.Chattable) bool {
+			msg, ok := c.(tgbotapi.MessageConfig)
+			return ok && msg.ChatID == chatID && strings.Contains(msg.Text, text)
+		})
+	}
+	mockBot.On("Send", withText(1, "city council approved")).Return(tgbotapi.Message{}, nil).Once()
+	mockBot.On("Send", withText(2, "Feed description")).Return(tgbotapi.Message{}, nil).Once()
+	mockBot.On("Send", withText(3, "city council approved")).Return(tgbotapi.Message{}, nil).Once()
+
+	botUsecase.CheckAndSendNews(ctx)
+
+	mockBot.AssertExpectations(t)
+	fetcher.AssertExpectations(t)
+}